@@ -0,0 +1,132 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Version is the version of the bundle format written by Encode
+const Version = 1
+
+// Format is the enum that enumerates the serialization formats of the bundles
+type Format string
+
+const (
+	// JSON serializes the bundle as JSON
+	JSON Format = "json"
+	// YAML serializes the bundle as YAML
+	YAML Format = "yaml"
+)
+
+// ErrUnsupportedVersion is an error raised when a bundle has no version or is written by a newer version of the format
+var ErrUnsupportedVersion = fmt.Errorf("Bundle version is not supported")
+
+// Bundle defines the structure for the products and environments moved between installations
+// swagger:model
+type Bundle struct {
+	// the version of the bundle format
+	//
+	// required: true
+	Version int `json:"version"`
+
+	// the date the bundle has been exported
+	//
+	// required: false
+	ExportedAt time.Time `json:"exportedAt"`
+
+	// the environments the features are configured for
+	//
+	// required: false
+	Environments []data.Environment `json:"environments"`
+
+	// the products with their features
+	//
+	// required: true
+	Products []data.Product `json:"products"`
+}
+
+// Encode writes the bundle to the writer in the given format
+func Encode(b Bundle, w io.Writer, format Format) error {
+	if format != YAML {
+		return data.ToJSON(b, w)
+	}
+	content, err := MarshalYAML(b)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// Decode reads a bundle in the given format from the reader
+func Decode(r io.Reader, format Format) (*Bundle, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if format == YAML {
+		err = UnmarshalYAML(content, &b)
+	} else {
+		err = json.Unmarshal(content, &b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.Version < 1 || b.Version > Version {
+		return nil, ErrUnsupportedVersion
+	}
+	return &b, nil
+}
+
+// MarshalYAML returns the YAML encoding of v.
+// The models only carry json tags, so v goes through its JSON form to keep the same field names
+func MarshalYAML(v interface{}) ([]byte, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	err = json.Unmarshal(content, &document)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(document)
+}
+
+// UnmarshalYAML parses the YAML content into v through its JSON form, like MarshalYAML writes it
+func UnmarshalYAML(content []byte, v interface{}) error {
+	var document interface{}
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return err
+	}
+	content, err = json.Marshal(jsonCompatible(document))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// jsonCompatible converts the maps decoded from YAML, which can have keys of any type, to maps with string keys
+func jsonCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, value := range t {
+			m[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = jsonCompatible(t[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package bundle_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_EncodeDecodeYAML(t *testing.T) {
+	productID := primitive.NewObjectID()
+	b := bundle.Bundle{
+		Version:      bundle.Version,
+		Environments: []data.Environment{{Key: "prod", Name: "Production", Order: 2}},
+		Products: []data.Product{{
+			ID:   productID,
+			Name: "shop",
+			Features: []data.Feature{{
+				Code:         "checkout",
+				Type:         data.Int,
+				Environments: map[string]data.FeatureEnvironment{"prod": {Enabled: true, Value: float64(3)}},
+			}},
+		}},
+	}
+	var buffer bytes.Buffer
+	err := bundle.Encode(b, &buffer, bundle.YAML)
+	if err != nil {
+		t.Fatalf("Error encoding bundle. Expected no error, got %v", err)
+	}
+	decoded, err := bundle.Decode(&buffer, bundle.YAML)
+	if err != nil {
+		t.Fatalf("Error decoding bundle. Expected no error, got %v", err)
+	}
+	if len(decoded.Products) != 1 || decoded.Products[0].ID != productID || decoded.Environments[0].Key != "prod" {
+		t.Errorf("Error decoding bundle. Expected the encoded product and environment, got %v", decoded)
+	}
+	if v := decoded.Products[0].Features[0].Environments["prod"].Value; v != float64(3) {
+		t.Errorf("Error decoding bundle. Expected value 3, got %v (%T)", v, v)
+	}
+}
+
+func Test_DecodeNewerVersion(t *testing.T) {
+	_, err := bundle.Decode(bytes.NewBufferString(`{"version": 99}`), bundle.JSON)
+	if err != bundle.ErrUnsupportedVersion {
+		t.Errorf("Error decoding a newer bundle. Expected %v, got %v", bundle.ErrUnsupportedVersion, err)
+	}
+}
+
+func Test_DecodeMissingVersion(t *testing.T) {
+	for _, content := range []string{`{"products": []}`, `{"version": 0}`} {
+		if _, err := bundle.Decode(bytes.NewBufferString(content), bundle.JSON); err != bundle.ErrUnsupportedVersion {
+			t.Errorf("Error decoding a bundle without a version. Expected %v, got %v", bundle.ErrUnsupportedVersion, err)
+		}
+	}
+}
+
+func Test_NewPlan(t *testing.T) {
+	shop, billing, legacy := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	existing := []data.Product{
+		{ID: shop, Name: "shop", Revision: 3, Features: []data.Feature{{ID: primitive.NewObjectID(), Code: "checkout"}}},
+		{ID: primitive.NewObjectID(), Name: "legacy"},
+	}
+	b := bundle.Bundle{Products: []data.Product{
+		{ID: shop, Name: "shop", Owners: []string{"alice"}, Features: []data.Feature{{Code: "checkout"}}},
+		{ID: billing, Name: "billing", Features: []data.Feature{{Code: "payments", Prerequisites: []data.Prerequisite{{ProductID: &shop, Code: "checkout"}}}}},
+		{ID: legacy, Name: "legacy"},
+	}}
+
+	plan := bundle.NewPlan(b, existing, nil, false)
+	if plan.Report.Created != 1 || plan.Report.Updated != 1 || plan.Report.Conflicts != 1 {
+		t.Errorf("Error planning an import by id. Expected 1 create, 1 update and 1 conflict, got %+v", plan.Report)
+	}
+	if plan.Creates[0].ID != billing {
+		t.Errorf("Error planning an import by id. Expected the created product to keep its id")
+	}
+
+	plan = bundle.NewPlan(b, existing, nil, true)
+	if plan.Report.Created != 1 || plan.Report.Updated != 1 || plan.Report.Unchanged != 1 || plan.Report.Conflicts != 0 {
+		t.Errorf("Error planning an import with remapping. Expected 1 create, 1 update and 1 unchanged, got %+v", plan.Report)
+	}
+	created := plan.Creates[0]
+	if created.ID == billing {
+		t.Errorf("Error planning an import with remapping. Expected the created product to get a new id")
+	}
+	if *created.Features[0].Prerequisites[0].ProductID != shop {
+		t.Errorf("Error planning an import with remapping. Expected the prerequisite to refer to %s, got %s", shop.Hex(), created.Features[0].Prerequisites[0].ProductID.Hex())
+	}
+	plan.Check(nil)
+	if plan.Report.Conflicts != 0 {
+		t.Errorf("Error checking the prerequisites of the import. Expected no conflicts, got %+v", plan.Report.Products)
+	}
+}
+
+func Test_NewPlanWithoutIDs(t *testing.T) {
+	b := bundle.Bundle{Products: []data.Product{{Name: "shop"}, {Name: "billing"}}}
+	for _, remap := range []bool{false, true} {
+		plan := bundle.NewPlan(b, nil, nil, remap)
+		if plan.Report.Created != 2 || plan.Report.Conflicts != 0 {
+			t.Errorf("Error planning an import of products without ids with remap %t. Expected 2 creates, got %+v", remap, plan.Report)
+			continue
+		}
+		if plan.Creates[0].ID.IsZero() || plan.Creates[1].ID.IsZero() || plan.Creates[0].ID == plan.Creates[1].ID {
+			t.Errorf("Error planning an import of products without ids with remap %t. Expected 2 different new ids, got %s and %s", remap, plan.Creates[0].ID.Hex(), plan.Creates[1].ID.Hex())
+		}
+		plan.Conflict(plan.Creates[0].ID, "Prerequisites form a cycle")
+		if plan.Report.Created != 1 || plan.Report.Conflicts != 1 || plan.Report.Products[1].Action != bundle.ActionCreate {
+			t.Errorf("Error marking a product without an id as a conflict with remap %t. Expected 1 create and 1 conflict, got %+v", remap, plan.Report)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions taken for the items of an imported bundle
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionConflict  = "conflict"
+)
+
+// Item defines the structure for the action taken for a product or an environment of an imported bundle
+// swagger:model ImportItem
+type Item struct {
+	// the action, one of create, update, unchanged and conflict
+	//
+	// required: true
+	Action string `json:"action"`
+
+	// the name of the product or the key of the environment
+	//
+	// required: true
+	Name string `json:"name"`
+
+	// the id of the product in the bundle
+	//
+	// required: false
+	SourceID string `json:"sourceId,omitempty"`
+
+	// the id of the product after the import
+	//
+	// required: false
+	ID string `json:"id,omitempty"`
+
+	// the reason of a conflict
+	//
+	// required: false
+	Message string `json:"message,omitempty"`
+}
+
+// Report defines the structure for the outcome of importing a bundle
+// swagger:model ImportReport
+type Report struct {
+	// shows whether the import has only been checked without changing anything
+	//
+	// required: true
+	DryRun bool `json:"dryRun"`
+
+	// the number of created products and environments
+	//
+	// required: true
+	Created int `json:"created"`
+
+	// the number of updated products
+	//
+	// required: true
+	Updated int `json:"updated"`
+
+	// the number of products and environments which are the same already
+	//
+	// required: true
+	Unchanged int `json:"unchanged"`
+
+	// the number of products and environments which can not be imported
+	//
+	// required: true
+	Conflicts int `json:"conflicts"`
+
+	// the actions taken for the environments
+	//
+	// required: true
+	Environments []Item `json:"environments"`
+
+	// the actions taken for the products
+	//
+	// required: true
+	Products []Item `json:"products"`
+}
+
+// Plan holds the changes importing a bundle makes, with the ids of the products already mapped to their ids after the import
+type Plan struct {
+	Report       Report
+	Environments []data.Environment
+	Creates      []data.Product
+	Updates      []data.Product
+	// befores holds the current state of the updated products, in the same order
+	Befores []data.Product
+}
+
+// NewPlan returns the changes importing the bundle makes to the existing products and environments.
+// If remap is true the products are matched by name and created with new ids, otherwise they're matched by id and keep their ids.
+// Prerequisites referring to the products of the bundle are changed to their new ids. Products without an id get a new one
+func NewPlan(b Bundle, existing []data.Product, environments []data.Environment, remap bool) *Plan {
+	plan := &Plan{Report: Report{Environments: []Item{}, Products: []Item{}}}
+	keys := map[string]bool{}
+	for _, e := range environments {
+		keys[e.Key] = true
+	}
+	for _, e := range b.Environments {
+		if keys[e.Key] {
+			plan.add(&plan.Report.Environments, Item{Action: ActionUnchanged, Name: e.Key})
+			continue
+		}
+		keys[e.Key] = true
+		plan.Environments = append(plan.Environments, data.Environment{Key: e.Key, Name: e.Name, Order: e.Order})
+		plan.add(&plan.Report.Environments, Item{Action: ActionCreate, Name: e.Key})
+	}
+
+	byID := map[primitive.ObjectID]data.Product{}
+	byName := map[string]data.Product{}
+	for _, p := range existing {
+		byID[p.ID] = p
+		if p.DeletedAt == nil {
+			byName[p.Name] = p
+		}
+	}
+	// the products are keyed by their ids from here on, so the ones without an id can't share the zero id
+	products := make([]data.Product, len(b.Products))
+	ids := map[primitive.ObjectID]primitive.ObjectID{}
+	targets := map[primitive.ObjectID]*data.Product{}
+	items := make([]Item, len(b.Products))
+	for i, p := range b.Products {
+		items[i] = Item{Name: p.Name}
+		if p.ID.IsZero() {
+			p.ID = primitive.NewObjectID()
+		} else {
+			items[i].SourceID = p.ID.Hex()
+		}
+		products[i] = p
+		if _, ok := ids[p.ID]; ok {
+			items[i].Action, items[i].Message = ActionConflict, "Product is in the bundle more than once"
+			continue
+		}
+		var target *data.Product
+		if remap {
+			if t, ok := byName[p.Name]; ok {
+				target = &t
+			}
+		} else if t, ok := byID[p.ID]; ok {
+			target = &t
+		}
+		switch {
+		case target != nil && target.DeletedAt != nil:
+			items[i].Action, items[i].Message = ActionConflict, "Product is deleted"
+			continue
+		case target == nil && !remap && byName[p.Name].Name != "":
+			items[i].Action, items[i].Message = ActionConflict, fmt.Sprintf("Name is used by product %s", byName[p.Name].ID.Hex())
+			continue
+		case target != nil:
+			ids[p.ID] = target.ID
+			targets[p.ID] = target
+		case remap:
+			ids[p.ID] = primitive.NewObjectID()
+		default:
+			ids[p.ID] = p.ID
+		}
+	}
+
+	for i, p := range products {
+		if items[i].Action == ActionConflict {
+			plan.add(&plan.Report.Products, items[i])
+			continue
+		}
+		product := remapProduct(p, ids, targets[p.ID])
+		items[i].ID = product.ID.Hex()
+		target := targets[p.ID]
+		switch {
+		case target == nil:
+			items[i].Action = ActionCreate
+			plan.Creates = append(plan.Creates, product)
+		case !changed(*target, product):
+			items[i].Action = ActionUnchanged
+		default:
+			items[i].Action = ActionUpdate
+			plan.Updates = append(plan.Updates, product)
+			plan.Befores = append(plan.Befores, *target)
+		}
+		plan.add(&plan.Report.Products, items[i])
+	}
+	return plan
+}
+
+// Check marks the products whose prerequisites refer to features which don't exist or form a cycle as conflicts,
+// finding the features in the planned products first and in the source after
+func (plan *Plan) Check(source evaluation.FeatureSource) {
+	for _, p := range append(append([]data.Product{}, plan.Creates...), plan.Updates...) {
+		source = evaluation.WithProduct(p, source)
+	}
+	for _, p := range append(append([]data.Product{}, plan.Creates...), plan.Updates...) {
+		messages := []string{}
+		for _, id := range evaluation.MissingPrerequisites(p, source) {
+			messages = append(messages, fmt.Sprintf("Feature '%s' does not exist", id))
+		}
+		if cycle := evaluation.FindCycle(p, source); cycle != nil {
+			messages = append(messages, fmt.Sprintf("Prerequisites form a cycle: %s", strings.Join(cycle, " -> ")))
+		}
+		if len(messages) > 0 {
+			plan.Conflict(p.ID, strings.Join(messages, ", "))
+		}
+	}
+}
+
+// RejectEnvironments marks the environments which would be created as conflicts with the given message
+func (plan *Plan) RejectEnvironments(message string) {
+	for i, item := range plan.Report.Environments {
+		if item.Action == ActionCreate {
+			plan.Report.Environments[i].Action, plan.Report.Environments[i].Message = ActionConflict, message
+			plan.Report.Created--
+			plan.Report.Conflicts++
+		}
+	}
+	plan.Environments = nil
+}
+
+// Conflict marks the planned product with the given id as a conflict with the given message
+func (plan *Plan) Conflict(id primitive.ObjectID, message string) {
+	for i, item := range plan.Report.Products {
+		if item.ID != id.Hex() || item.Action == ActionConflict {
+			continue
+		}
+		if item.Action == ActionCreate {
+			plan.Report.Created--
+		} else {
+			plan.Report.Updated--
+		}
+		plan.Report.Products[i].Action, plan.Report.Products[i].Message = ActionConflict, message
+		plan.Report.Conflicts++
+	}
+}
+
+func (plan *Plan) add(items *[]Item, item Item) {
+	switch item.Action {
+	case ActionCreate:
+		plan.Report.Created++
+	case ActionUpdate:
+		plan.Report.Updated++
+	case ActionUnchanged:
+		plan.Report.Unchanged++
+	case ActionConflict:
+		plan.Report.Conflicts++
+	}
+	*items = append(*items, item)
+}
+
+// remapProduct returns the product with its new id and the prerequisites referring to the products of the bundle changed to their new ids.
+// Features of an existing product keep their ids if their codes match
+func remapProduct(p data.Product, ids map[primitive.ObjectID]primitive.ObjectID, target *data.Product) data.Product {
+	product := p
+	product.ID = ids[p.ID]
+	product.DeletedAt = nil
+	if p.Features == nil {
+		return product
+	}
+	product.Features = make([]data.Feature, len(p.Features))
+	featureIDs := map[string]primitive.ObjectID{}
+	if target != nil {
+		product.Revision = target.Revision
+		for _, f := range target.Features {
+			featureIDs[f.Code] = f.ID
+		}
+	}
+	for i, f := range p.Features {
+		feature := f
+		if id, ok := featureIDs[f.Code]; ok {
+			feature.ID = id
+		}
+		if f.Prerequisites != nil {
+			feature.Prerequisites = make([]data.Prerequisite, len(f.Prerequisites))
+		}
+		for j, pr := range f.Prerequisites {
+			if pr.ProductID != nil {
+				if id, ok := ids[*pr.ProductID]; ok {
+					pr.ProductID = &id
+				}
+			}
+			feature.Prerequisites[j] = pr
+		}
+		product.Features[i] = feature
+	}
+	return product
+}
+
+// changed checks if the imported product differs from the existing one, ignoring the difference of an empty list and a missing one
+func changed(existing data.Product, imported data.Product) bool {
+	for _, c := range data.DiffProductRevisions(&data.ProductRevision{Product: existing}, &data.ProductRevision{Product: imported}) {
+		if !empty(c.Before) || !empty(c.After) {
+			return true
+		}
+	}
+	return false
+}
+
+func empty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	return false
+}
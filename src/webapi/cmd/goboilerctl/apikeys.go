@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newAPIKeysCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "apikeys",
+		Aliases: []string{"apikey"},
+		Short:   "List, issue, rotate and revoke API keys",
+	}
+	var name, scope string
+	var products []string
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the API keys, without the keys themselves",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiKeys, err := opts.client().GetAPIKeys()
+			if err != nil {
+				return err
+			}
+			return opts.print(apiKeys, func() table { return apiKeysTable(apiKeys...) })
+		},
+	}
+
+	issue := &cobra.Command{
+		Use:   "issue --name <name>",
+		Short: "Issue a new API key. The key is printed only once",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiKey := dto.APIKey{Name: name, Scope: scope, Products: []primitive.ObjectID{}}
+			for _, p := range products {
+				id, err := primitive.ObjectIDFromHex(p)
+				if err != nil {
+					return err
+				}
+				apiKey.Products = append(apiKey.Products, id)
+			}
+			issued, err := opts.client().IssueAPIKey(apiKey)
+			if err != nil {
+				return err
+			}
+			return opts.print(issued, func() table { return issuedTable(issued) })
+		},
+	}
+	issue.Flags().StringVar(&name, "name", "", "Name describing the owner of the API key")
+	issue.Flags().StringVar(&scope, "scope", string(data.ReadOnly), "Scope of the API key, read or readwrite")
+	issue.Flags().StringSliceVar(&products, "product", nil, "Id of a product the API key is restricted to, can be repeated")
+	issue.MarkFlagRequired("name")
+
+	rotate := &cobra.Command{
+		Use:   "rotate <apiKeyId>",
+		Short: "Replace the key of an API key. The new key is printed only once",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issued, err := opts.client().RotateAPIKey(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.print(issued, func() table { return issuedTable(issued) })
+		},
+	}
+
+	revoke := &cobra.Command{
+		Use:   "revoke <apiKeyId>",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiKey, err := opts.client().RevokeAPIKey(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.print(apiKey, func() table { return apiKeysTable(*apiKey) })
+		},
+	}
+
+	cmd.AddCommand(list, issue, rotate, revoke)
+	return cmd
+}
+
+func apiKeysTable(apiKeys ...data.APIKey) table {
+	t := table{headers: []string{"ID", "NAME", "HINT", "SCOPE", "PRODUCTS", "REVOKED"}}
+	for _, k := range apiKeys {
+		ids := []string{}
+		for _, p := range k.Products {
+			ids = append(ids, p.Hex())
+		}
+		revoked := ""
+		if k.RevokedAt != nil {
+			revoked = k.RevokedAt.Format("2006-01-02 15:04")
+		}
+		t.rows = append(t.rows, []string{k.ID.Hex(), k.Name, k.Hint, string(k.Scope), strings.Join(ids, ","), revoked})
+	}
+	return t
+}
+
+func issuedTable(issued *dto.IssuedAPIKey) table {
+	return table{headers: []string{"ID", "KEY"}, rows: [][]string{{issued.ID.Hex(), issued.Key}}}
+}
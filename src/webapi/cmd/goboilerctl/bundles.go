@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/spf13/cobra"
+)
+
+func newBundleCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Export and import the products and environments as a bundle",
+	}
+	var file, format string
+	var remap, dryRun bool
+
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "Export the products and environments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "-" {
+				return opts.client().Export(os.Stdout, bundleFormat(format, file))
+			}
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			err = opts.client().Export(f, bundleFormat(format, file))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			return err
+		},
+	}
+	export.Flags().StringVarP(&file, "file", "f", "-", "File to write the bundle to, - for the standard output")
+	export.Flags().StringVar(&format, "format", "", "Format of the bundle, json or yaml. Defaults to the extension of the file, or json")
+
+	imp := &cobra.Command{
+		Use:   "import -f <file>",
+		Short: "Import the products and environments of a bundle",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := os.Stdin
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			report, err := opts.client().Import(in, bundleFormat(format, file), remap, dryRun)
+			if report != nil {
+				if perr := opts.print(report, func() table { return reportTable(report) }); perr != nil {
+					return perr
+				}
+			}
+			return err
+		},
+	}
+	imp.Flags().StringVarP(&file, "file", "f", "-", "File to read the bundle from, - for the standard input")
+	imp.Flags().StringVar(&format, "format", "", "Format of the bundle, json or yaml. Defaults to the extension of the file, or json")
+	imp.Flags().BoolVar(&remap, "remap", false, "Match the products by name and create the new ones with new ids")
+	imp.Flags().BoolVar(&dryRun, "dry-run", false, "Only report what would change")
+
+	cmd.AddCommand(export, imp)
+	return cmd
+}
+
+// bundleFormat returns the format of the flag, or the one of the extension of the file if the flag is empty
+func bundleFormat(format string, file string) bundle.Format {
+	if format == "" {
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml":
+			format = string(bundle.YAML)
+		}
+	}
+	if format == string(bundle.YAML) {
+		return bundle.YAML
+	}
+	return bundle.JSON
+}
+
+func reportTable(report *bundle.Report) table {
+	t := table{headers: []string{"KIND", "ACTION", "NAME", "ID", "MESSAGE"}}
+	for _, item := range report.Environments {
+		t.rows = append(t.rows, []string{"environment", item.Action, item.Name, "", item.Message})
+	}
+	for _, item := range report.Products {
+		t.rows = append(t.rows, []string{"product", item.Action, item.Name, item.ID, item.Message})
+	}
+	return t
+}
@@ -0,0 +1,280 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+)
+
+// APIError is an error returned by the API
+type APIError struct {
+	StatusCode int
+	Message    string   `json:"message"`
+	Messages   []string `json:"messages"`
+	// body is the whole response, some errors carry more than a message
+	body []byte
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if len(e.Messages) > 0 {
+		message = strings.Join(e.Messages, ", ")
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, message)
+}
+
+// Client calls the HTTP API
+type Client struct {
+	// BaseURL is the address of the API, like http://localhost:5500
+	BaseURL string
+	// Token is sent as the bearer token if it's not empty
+	Token string
+	// APIKey is sent in the X-API-Key header if it's not empty
+	APIKey string
+	// Tenant is sent in the TenantHeader if it's not empty
+	Tenant       string
+	TenantHeader string
+	HTTPClient   *http.Client
+}
+
+// New returns a new Client for the API at the given address
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		TenantHeader: "X-Tenant-ID",
+		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetProducts returns every product
+func (c *Client) GetProducts() ([]data.Product, error) {
+	products := []data.Product{}
+	err := c.doJSON(http.MethodGet, "/products", nil, nil, &products)
+	return products, err
+}
+
+// GetProduct returns the product which matches the id
+func (c *Client) GetProduct(id string) (*data.Product, error) {
+	var product data.Product
+	err := c.doJSON(http.MethodGet, "/products/"+url.PathEscape(id), nil, nil, &product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// AddProduct creates the product and returns it with its id
+func (c *Client) AddProduct(product data.Product) (*data.Product, error) {
+	var created data.Product
+	err := c.doJSON(http.MethodPost, "/products", nil, product, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateProduct replaces the product which matches the id of the product
+func (c *Client) UpdateProduct(product data.Product) (*data.Product, error) {
+	var updated data.Product
+	err := c.doJSON(http.MethodPut, "/products/"+product.ID.Hex(), nil, product, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// GetAPIKeys returns every API key, without the keys themselves
+func (c *Client) GetAPIKeys() ([]data.APIKey, error) {
+	apiKeys := []data.APIKey{}
+	err := c.doJSON(http.MethodGet, "/apikeys", nil, nil, &apiKeys)
+	return apiKeys, err
+}
+
+// IssueAPIKey creates a new API key and returns it with the key
+func (c *Client) IssueAPIKey(apiKey dto.APIKey) (*dto.IssuedAPIKey, error) {
+	var issued dto.IssuedAPIKey
+	err := c.doJSON(http.MethodPost, "/apikeys", nil, apiKey, &issued)
+	if err != nil {
+		return nil, err
+	}
+	return &issued, nil
+}
+
+// RotateAPIKey replaces the key of the API key which matches the id and returns the new key
+func (c *Client) RotateAPIKey(id string) (*dto.IssuedAPIKey, error) {
+	var issued dto.IssuedAPIKey
+	err := c.doJSON(http.MethodPost, "/apikeys/"+url.PathEscape(id)+"/rotate", nil, nil, &issued)
+	if err != nil {
+		return nil, err
+	}
+	return &issued, nil
+}
+
+// RevokeAPIKey revokes the API key which matches the id
+func (c *Client) RevokeAPIKey(id string) (*data.APIKey, error) {
+	var apiKey data.APIKey
+	err := c.doJSON(http.MethodDelete, "/apikeys/"+url.PathEscape(id), nil, nil, &apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetCustomers returns every customer
+func (c *Client) GetCustomers() ([]data.Customer, error) {
+	customers := []data.Customer{}
+	err := c.doJSON(http.MethodGet, "/customers", nil, nil, &customers)
+	return customers, err
+}
+
+// GetCustomer returns the customer which matches the id
+func (c *Client) GetCustomer(id string) (*data.Customer, error) {
+	var customer data.Customer
+	err := c.doJSON(http.MethodGet, "/customers/"+url.PathEscape(id), nil, nil, &customer)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// AddCustomer creates the customer and returns it with its id
+func (c *Client) AddCustomer(customer dto.Customer) (*data.Customer, error) {
+	var created data.Customer
+	err := c.doJSON(http.MethodPost, "/customers", nil, customer, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateCustomer replaces the name and the balance of the customer which matches the id
+func (c *Client) UpdateCustomer(id string, customer dto.Customer) (*data.Customer, error) {
+	var updated data.Customer
+	err := c.doJSON(http.MethodPut, "/customers/"+url.PathEscape(id), nil, customer, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteCustomer removes the customer which matches the id
+func (c *Client) DeleteCustomer(id string) error {
+	return c.doJSON(http.MethodDelete, "/customers/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Export writes the bundle of the products and environments to the writer in the given format
+func (c *Client) Export(w io.Writer, format bundle.Format) error {
+	query := url.Values{"format": {string(format)}}
+	res, err := c.do(http.MethodGet, "/products/export", query, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// Import imports the bundle read from the reader in the given format and returns the report of the import.
+// The report is returned together with the error if the import has conflicts
+func (c *Client) Import(r io.Reader, format bundle.Format, remap bool, dryRun bool) (*bundle.Report, error) {
+	query := url.Values{"format": {string(format)}}
+	if remap {
+		query.Set("remap", "true")
+	}
+	if dryRun {
+		query.Set("dryRun", "true")
+	}
+	contentType := "application/json"
+	if format == bundle.YAML {
+		contentType = "application/yaml"
+	}
+	res, err := c.do(http.MethodPost, "/products/import", query, r, contentType)
+	if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == http.StatusConflict {
+		var report bundle.Report
+		if json.Unmarshal(apiErr.body, &report) == nil {
+			return &report, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var report bundle.Report
+	err = json.NewDecoder(res.Body).Decode(&report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// doJSON sends the request with the body serialized as JSON and deserializes the response into out
+func (c *Client) doJSON(method string, path string, query url.Values, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+	res, err := c.do(method, path, query, reader, "application/json")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// do sends the request with the credentials of the client and returns an APIError for the responses other than 2xx
+func (c *Client) do(method string, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	if c.Tenant != "" {
+		req.Header.Set(c.TenantHeader, c.Tenant)
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	apiErr := &APIError{StatusCode: res.StatusCode}
+	content, _ := ioutil.ReadAll(res.Body)
+	json.Unmarshal(content, apiErr)
+	apiErr.body = content
+	return nil, apiErr
+}
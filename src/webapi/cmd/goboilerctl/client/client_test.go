@@ -0,0 +1,85 @@
+package client_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/serdarkalayci/goboiler/webapi/cmd/goboilerctl/client"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+)
+
+func Test_Credentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-API-Key") != "key" || r.Header.Get("X-Org") != "acme" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Write([]byte(`[{"id":"5f7b1a2e9d1e8a1b2c3d4e5f","name":"Shop","features":[],"owners":[]}]`))
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL + "/")
+	c.Token, c.APIKey, c.Tenant, c.TenantHeader = "token", "key", "acme", "X-Org"
+	products, err := c.GetProducts()
+	if err != nil || len(products) != 1 || products[0].Name != "Shop" {
+		t.Errorf("Error getting products with credentials. Expected Shop, got %v (%v)", products, err)
+	}
+}
+
+func Test_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+		rw.Write([]byte(`{"messages":["Name is required","Code is required"]}`))
+	}))
+	defer server.Close()
+
+	_, err := client.New(server.URL).GetProduct("5f7b1a2e9d1e8a1b2c3d4e5f")
+	apiErr, ok := err.(*client.APIError)
+	if !ok || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Error getting the API error. Expected status 422, got %v", err)
+	}
+	if expected := "422: Name is required, Code is required"; apiErr.Error() != expected {
+		t.Errorf("Error formatting the API error. Expected %s, got %s", expected, apiErr.Error())
+	}
+}
+
+func Test_ImportConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") != "yaml" || r.URL.Query().Get("remap") != "true" || r.Header.Get("Content-Type") != "application/yaml" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rw.WriteHeader(http.StatusConflict)
+		rw.Write([]byte(`{"dryRun":false,"conflicts":1,"environments":[],"products":[{"action":"conflict","name":"Shop","message":"Prerequisite missing"}]}`))
+	}))
+	defer server.Close()
+
+	report, err := client.New(server.URL).Import(bytes.NewBufferString("version: 1\n"), bundle.YAML, true, false)
+	if err == nil {
+		t.Errorf("Error importing a bundle with conflicts. Expected an error, got nil")
+	}
+	if report == nil || report.Conflicts != 1 || report.Products[0].Action != bundle.ActionConflict {
+		t.Errorf("Error importing a bundle with conflicts. Expected the report with 1 conflict, got %v", report)
+	}
+}
+
+func Test_UpdateCustomer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/customers/5f7b1a2e9d1e8a1b2c3d4e5f" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		rw.Write([]byte(`{"id":"5f7b1a2e9d1e8a1b2c3d4e5f",` + string(body[1:])))
+	}))
+	defer server.Close()
+
+	customer, err := client.New(server.URL).UpdateCustomer("5f7b1a2e9d1e8a1b2c3d4e5f", dto.Customer{Name: "Jane", Balance: 12.5})
+	if err != nil || customer.ID.Hex() != "5f7b1a2e9d1e8a1b2c3d4e5f" || customer.Name != "Jane" || customer.Balance != 12.5 {
+		t.Errorf("Error updating the customer. Expected Jane with balance 12.5, got %v (%v)", customer, err)
+	}
+}
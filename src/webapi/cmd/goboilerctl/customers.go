@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/spf13/cobra"
+)
+
+func newCustomersCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "customers",
+		Aliases: []string{"customer"},
+		Short:   "List, get, create, update and delete customers",
+	}
+	var name string
+	var balance float64
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the customers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			customers, err := opts.client().GetCustomers()
+			if err != nil {
+				return err
+			}
+			return opts.print(customers, func() table { return customersTable(customers...) })
+		},
+	}
+
+	get := &cobra.Command{
+		Use:   "get <customerId>",
+		Short: "Get a customer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			customer, err := opts.client().GetCustomer(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.print(customer, func() table { return customersTable(*customer) })
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create --name <name>",
+		Short: "Create a customer",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			created, err := opts.client().AddCustomer(dto.Customer{Name: name, Balance: balance})
+			if err != nil {
+				return err
+			}
+			return opts.print(created, func() table { return customersTable(*created) })
+		},
+	}
+	create.Flags().StringVar(&name, "name", "", "Name of the customer")
+	create.Flags().Float64Var(&balance, "balance", 0, "Balance of the customer")
+	create.MarkFlagRequired("name")
+
+	update := &cobra.Command{
+		Use:   "update <customerId> [--name <name>] [--balance <balance>]",
+		Short: "Change the name or the balance of a customer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := opts.client()
+			customer, err := c.GetCustomer(args[0])
+			if err != nil {
+				return err
+			}
+			changed := dto.Customer{Name: customer.Name, Balance: customer.Balance}
+			if cmd.Flags().Changed("name") {
+				changed.Name = name
+			}
+			if cmd.Flags().Changed("balance") {
+				changed.Balance = balance
+			}
+			updated, err := c.UpdateCustomer(args[0], changed)
+			if err != nil {
+				return err
+			}
+			return opts.print(updated, func() table { return customersTable(*updated) })
+		},
+	}
+	update.Flags().StringVar(&name, "name", "", "New name of the customer")
+	update.Flags().Float64Var(&balance, "balance", 0, "New balance of the customer")
+
+	remove := &cobra.Command{
+		Use:   "delete <customerId>",
+		Short: "Delete a customer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.client().DeleteCustomer(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted customer %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.AddCommand(list, get, create, update, remove)
+	return cmd
+}
+
+func customersTable(customers ...data.Customer) table {
+	t := table{headers: []string{"ID", "NAME", "BALANCE"}}
+	for _, c := range customers {
+		t.rows = append(t.rows, []string{c.ID.Hex(), c.Name, strconv.FormatFloat(c.Balance, 'f', 2, 64)})
+	}
+	return t
+}
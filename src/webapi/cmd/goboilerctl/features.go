@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newFeaturesCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "features",
+		Aliases: []string{"feature", "flags", "flag"},
+		Short:   "List, get, create, update and toggle the feature flags of a product",
+	}
+	var file string
+	var on, off bool
+
+	list := &cobra.Command{
+		Use:   "list <productId>",
+		Short: "List the features of a product",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			product, err := opts.client().GetProduct(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.print(product.Features, func() table { return featuresTable(product.Features...) })
+		},
+	}
+
+	get := &cobra.Command{
+		Use:   "get <productId> <code>",
+		Short: "Get a feature of a product",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			product, err := opts.client().GetProduct(args[0])
+			if err != nil {
+				return err
+			}
+			i, err := findFeature(product, args[1])
+			if err != nil {
+				return err
+			}
+			feature := product.Features[i]
+			return opts.print(feature, func() table { return featuresTable(feature) })
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create <productId> -f <file>",
+		Short: "Add a feature from a JSON or YAML file to a product",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var feature data.Feature
+			if err := readFile(file, &feature); err != nil {
+				return err
+			}
+			return opts.changeFeature(args[0], feature.Code, func(product *data.Product) error {
+				if _, err := findFeature(product, feature.Code); err == nil {
+					return fmt.Errorf("Feature '%s' already exists", feature.Code)
+				}
+				feature.ID = primitive.NewObjectID()
+				product.Features = append(product.Features, feature)
+				return nil
+			})
+		},
+	}
+	create.Flags().StringVarP(&file, "file", "f", "-", "JSON or YAML file of the feature, - for the standard input")
+
+	update := &cobra.Command{
+		Use:   "update <productId> <code> -f <file>",
+		Short: "Replace a feature of a product with a JSON or YAML file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var feature data.Feature
+			if err := readFile(file, &feature); err != nil {
+				return err
+			}
+			if feature.Code == "" {
+				feature.Code = args[1]
+			}
+			return opts.changeFeature(args[0], feature.Code, func(product *data.Product) error {
+				i, err := findFeature(product, args[1])
+				if err != nil {
+					return err
+				}
+				feature.ID = product.Features[i].ID
+				product.Features[i] = feature
+				return nil
+			})
+		},
+	}
+	update.Flags().StringVarP(&file, "file", "f", "-", "JSON or YAML file of the feature, - for the standard input")
+
+	toggle := &cobra.Command{
+		Use:   "toggle <productId> <code> <environment>",
+		Short: "Turn a feature on or off in an environment, switching its current state unless --on or --off is given",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if on && off {
+				return fmt.Errorf("Only one of --on and --off can be given")
+			}
+			return opts.changeFeature(args[0], args[1], func(product *data.Product) error {
+				i, err := findFeature(product, args[1])
+				if err != nil {
+					return err
+				}
+				feature := &product.Features[i]
+				if feature.Environments == nil {
+					feature.Environments = map[string]data.FeatureEnvironment{}
+				}
+				env := feature.Environments[args[2]]
+				switch {
+				case on:
+					env.Enabled = true
+				case off:
+					env.Enabled = false
+				default:
+					env.Enabled = !env.Enabled
+				}
+				feature.Environments[args[2]] = env
+				return nil
+			})
+		},
+	}
+	toggle.Flags().BoolVar(&on, "on", false, "Turn the feature on")
+	toggle.Flags().BoolVar(&off, "off", false, "Turn the feature off")
+
+	cmd.AddCommand(list, get, create, update, toggle)
+	return cmd
+}
+
+// changeFeature gets the product, changes it and stores it again, printing the feature with the code
+func (opts *options) changeFeature(productID string, code string, change func(product *data.Product) error) error {
+	c := opts.client()
+	product, err := c.GetProduct(productID)
+	if err != nil {
+		return err
+	}
+	if err := change(product); err != nil {
+		return err
+	}
+	product, err = c.UpdateProduct(*product)
+	if err != nil {
+		return err
+	}
+	i, err := findFeature(product, code)
+	if err != nil {
+		return err
+	}
+	feature := product.Features[i]
+	return opts.print(feature, func() table { return featuresTable(feature) })
+}
+
+// findFeature returns the index of the feature with the code in the features of the product
+func findFeature(product *data.Product, code string) (int, error) {
+	for i, f := range product.Features {
+		if f.Code == code {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("Feature '%s' not found in product %s", code, product.ID.Hex())
+}
+
+func featuresTable(features ...data.Feature) table {
+	t := table{headers: []string{"CODE", "NAME", "TYPE", "ENVIRONMENTS"}}
+	for _, f := range features {
+		keys := []string{}
+		for key := range f.Environments {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		states := []string{}
+		for _, key := range keys {
+			states = append(states, key+"="+onOff(f.Environments[key].Enabled))
+		}
+		t.rows = append(t.rows, []string{f.Code, f.Name, f.Type.Name(), strings.Join(states, ",")})
+	}
+	return t
+}
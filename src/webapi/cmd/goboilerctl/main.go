@@ -0,0 +1,94 @@
+// goboilerctl manages the products, feature flags, bundles, customers and API keys of a running API over HTTP
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/serdarkalayci/goboiler/webapi/cmd/goboilerctl/client"
+	"github.com/spf13/cobra"
+)
+
+// options holds the global flags of the commands
+type options struct {
+	server       string
+	token        string
+	apiKey       string
+	tenant       string
+	tenantHeader string
+	output       string
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+	root := &cobra.Command{
+		Use:          "goboilerctl",
+		Short:        "Manage the products, feature flags, bundles, customers and API keys of the API",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputTable, outputJSON, outputYAML:
+				return nil
+			}
+			return fmt.Errorf("Unknown output format '%s', expected one of %s, %s and %s", opts.output, outputTable, outputJSON, outputYAML)
+		},
+	}
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.server, "server", envOr("GOBOILER_SERVER", "http://localhost:5500"), "Address of the API (GOBOILER_SERVER)")
+	flags.StringVar(&opts.token, "token", os.Getenv("GOBOILER_TOKEN"), "Bearer token to authenticate with (GOBOILER_TOKEN)")
+	flags.StringVar(&opts.apiKey, "api-key", os.Getenv("GOBOILER_API_KEY"), "API key to authenticate with (GOBOILER_API_KEY)")
+	flags.StringVar(&opts.tenant, "tenant", os.Getenv("GOBOILER_TENANT"), "Tenant to send the requests for (GOBOILER_TENANT)")
+	flags.StringVar(&opts.tenantHeader, "tenant-header", envOr("GOBOILER_TENANT_HEADER", "X-Tenant-ID"), "Header the tenant is sent in (GOBOILER_TENANT_HEADER)")
+	flags.StringVarP(&opts.output, "output", "o", outputTable, "Output format, one of table, json and yaml")
+
+	root.AddCommand(
+		newProductsCommand(opts),
+		newFeaturesCommand(opts),
+		newBundleCommand(opts),
+		newCustomersCommand(opts),
+		newAPIKeysCommand(opts),
+	)
+	return root
+}
+
+// client returns a client for the API with the credentials of the flags
+func (opts *options) client() *client.Client {
+	c := client.New(opts.server)
+	c.Token = opts.token
+	c.APIKey = opts.apiKey
+	c.Tenant = opts.tenant
+	c.TenantHeader = opts.tenantHeader
+	return c
+}
+
+// envOr returns the value of the environment variable, or the fallback if it's not set
+func envOr(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// readFile reads the JSON or YAML file into v, reading the standard input if the path is -
+func readFile(path string, v interface{}) error {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	// JSON is valid YAML, so both are read the same way
+	return bundle.UnmarshalYAML(content, v)
+}
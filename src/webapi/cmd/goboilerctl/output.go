@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table holds the rows of a value printed as a table
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// print writes the value to the standard output in the output format of the flags.
+// The table is only built for the table format
+func (opts *options) print(v interface{}, toTable func() table) error {
+	return write(os.Stdout, opts.output, v, toTable)
+}
+
+func write(w io.Writer, format string, v interface{}, toTable func() table) error {
+	switch format {
+	case outputJSON:
+		content, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		return err
+	case outputYAML:
+		content, err := bundle.MarshalYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	t := toTable()
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// onOff returns on or off for the enabled state of a flag
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
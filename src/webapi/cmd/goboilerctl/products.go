@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newProductsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "products",
+		Aliases: []string{"product"},
+		Short:   "List, get, create and update products",
+	}
+	var file string
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the products",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			products, err := opts.client().GetProducts()
+			if err != nil {
+				return err
+			}
+			return opts.print(products, func() table { return productsTable(products...) })
+		},
+	}
+
+	get := &cobra.Command{
+		Use:   "get <productId>",
+		Short: "Get a product",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			product, err := opts.client().GetProduct(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.print(product, func() table { return productsTable(*product) })
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create -f <file>",
+		Short: "Create a product from a JSON or YAML file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var product data.Product
+			if err := readFile(file, &product); err != nil {
+				return err
+			}
+			created, err := opts.client().AddProduct(product)
+			if err != nil {
+				return err
+			}
+			return opts.print(created, func() table { return productsTable(*created) })
+		},
+	}
+	create.Flags().StringVarP(&file, "file", "f", "-", "JSON or YAML file of the product, - for the standard input")
+
+	update := &cobra.Command{
+		Use:   "update <productId> -f <file>",
+		Short: "Replace a product and its features with a JSON or YAML file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := primitive.ObjectIDFromHex(args[0])
+			if err != nil {
+				return err
+			}
+			var product data.Product
+			if err := readFile(file, &product); err != nil {
+				return err
+			}
+			product.ID = id
+			updated, err := opts.client().UpdateProduct(product)
+			if err != nil {
+				return err
+			}
+			return opts.print(updated, func() table { return productsTable(*updated) })
+		},
+	}
+	update.Flags().StringVarP(&file, "file", "f", "-", "JSON or YAML file of the product, - for the standard input")
+
+	cmd.AddCommand(list, get, create, update)
+	return cmd
+}
+
+func productsTable(products ...data.Product) table {
+	t := table{headers: []string{"ID", "NAME", "FEATURES", "OWNERS", "REVISION"}}
+	for _, p := range products {
+		t.rows = append(t.rows, []string{p.ID.Hex(), p.Name, strconv.Itoa(len(p.Features)), strings.Join(p.Owners, ","), strconv.Itoa(p.Revision)})
+	}
+	return t
+}
@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// goTypes maps the types of the field spec to their Go types
+var goTypes = map[string]string{
+	"string":  "string",
+	"int":     "int",
+	"int64":   "int64",
+	"float64": "float64",
+	"bool":    "bool",
+	"time":    "time.Time",
+}
+
+// reserved holds the fields every entity has already
+var reserved = map[string]bool{"id": true, "tenant": true}
+
+// taken holds the names the generated code uses already, which can't be the variables of the entity
+var taken = map[string]bool{
+	"bson": true, "collection": true, "context": true, "ctx": true, "cur": true, "data": true, "dto": true,
+	"err": true, "fmt": true, "http": true, "id": true, "log": true, "mongo": true, "mux": true, "primitive": true,
+	"r": true, "result": true, "rw": true, "span": true, "tenant": true, "time": true, "updated": true, "before": true,
+}
+
+// Field defines a field of the entity
+type Field struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// GoType returns the Go type of the field
+func (f Field) GoType() string {
+	return goTypes[f.Type]
+}
+
+// JSONName returns the name of the field in JSON and in the database
+func (f Field) JSONName() string {
+	return lowerInitial(f.Name)
+}
+
+// Description returns the name of the field in lowercase words, such as stock count for StockCount
+func (f Field) Description() string {
+	return describe(f.Name)
+}
+
+// Validation returns the validate tag of the field in the dto.
+// A required bool can't be told apart from false, so it isn't validated
+func (f Field) Validation() string {
+	if !f.Required || f.Type == "bool" {
+		return ""
+	}
+	return ` validate:"required"`
+}
+
+// ParseField parses a field spec in the form name:type or name:type:required
+func ParseField(spec string) (Field, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Field{}, fmt.Errorf("Field '%s' must be in the form name:type or name:type:required", spec)
+	}
+	field := Field{Name: upperFirst(parts[0]), Type: parts[1]}
+	if !token.IsIdentifier(field.Name) {
+		return Field{}, fmt.Errorf("Field name '%s' is not a valid identifier", parts[0])
+	}
+	if reserved[strings.ToLower(field.Name)] {
+		return Field{}, fmt.Errorf("Field '%s' is added to every entity already", field.Name)
+	}
+	if _, ok := goTypes[field.Type]; !ok {
+		return Field{}, fmt.Errorf("Field '%s' has unknown type '%s', expected one of string, int, int64, float64, bool and time", field.Name, field.Type)
+	}
+	if len(parts) == 3 {
+		if parts[2] != "required" {
+			return Field{}, fmt.Errorf("Field '%s' has unknown option '%s', expected required", field.Name, parts[2])
+		}
+		field.Required = true
+	}
+	return field, nil
+}
+
+// Entity defines the resource which is scaffolded
+type Entity struct {
+	// Name is the exported Go name of the entity, such as OrderLine
+	Name   string
+	Fields []Field
+	// Module is the import path of the module the entity is added to
+	Module string
+}
+
+// NewEntity returns the entity with the given name and fields parsed from their specs
+func NewEntity(name string, module string, specs []string) (*Entity, error) {
+	if !token.IsIdentifier(name) || !unicode.IsUpper([]rune(name)[0]) {
+		return nil, fmt.Errorf("Entity name '%s' must be an exported Go identifier, such as OrderLine", name)
+	}
+	entity := &Entity{Name: name, Module: module}
+	for _, v := range []string{entity.Var(), entity.PluralVar()} {
+		if token.IsKeyword(v) || taken[v] {
+			return nil, fmt.Errorf("Entity name '%s' can't be used as it collides with '%s' in the generated code", name, v)
+		}
+	}
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		field, err := ParseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("Field '%s' is given more than once", field.Name)
+		}
+		seen[field.Name] = true
+		entity.Fields = append(entity.Fields, field)
+	}
+	return entity, nil
+}
+
+// Plural returns the plural of the name, such as OrderLines for OrderLine
+func (e *Entity) Plural() string {
+	return plural(e.Name)
+}
+
+// Var returns the name of a variable holding the entity, such as orderLine for OrderLine
+func (e *Entity) Var() string {
+	return lowerInitial(e.Name)
+}
+
+// PluralVar returns the name of a variable holding a list of the entity
+func (e *Entity) PluralVar() string {
+	return lowerInitial(e.Plural())
+}
+
+// File returns the base name of the files of the entity without the extension, such as orderline for OrderLine
+func (e *Entity) File() string {
+	return strings.ToLower(e.Name)
+}
+
+// Collection returns the name of the database collection and of the path of the entity
+func (e *Entity) Collection() string {
+	return strings.ToLower(e.Plural())
+}
+
+// Lower returns the name of the entity in lowercase words, such as order line for OrderLine
+func (e *Entity) Lower() string {
+	return describe(e.Name)
+}
+
+// LowerPlural returns the plural of the name in lowercase words, such as order lines for OrderLine
+func (e *Entity) LowerPlural() string {
+	return describe(e.Plural())
+}
+
+// A returns the name of the entity in lowercase words with its indefinite article
+func (e *Entity) A() string {
+	lower := e.Lower()
+	if strings.ContainsRune("aeiou", rune(lower[0])) {
+		return "an " + lower
+	}
+	return "a " + lower
+}
+
+// Title returns the name of the entity in words with the first one capitalized, such as Order line for OrderLine
+func (e *Entity) Title() string {
+	return upperFirst(e.Lower())
+}
+
+// UsesTime checks if a field of the entity is a time
+func (e *Entity) UsesTime() bool {
+	for _, f := range e.Fields {
+		if f.Type == "time" {
+			return true
+		}
+	}
+	return false
+}
+
+// describe splits the name into lowercase words, keeping initialisms together, such as api token for APIToken
+func describe(name string) string {
+	r := []rune(name)
+	var b strings.Builder
+	for i := range r {
+		if i > 0 && unicode.IsUpper(r[i]) && (!unicode.IsUpper(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(unicode.ToLower(r[i]))
+	}
+	return b.String()
+}
+
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// lowerInitial lowers the leading capitals of the name, keeping the last one of an initialism, such as apiKey for APIKey
+func lowerInitial(s string) string {
+	r := []rune(s)
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
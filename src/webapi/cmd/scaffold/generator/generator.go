@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// File defines a file written by the generator
+type File struct {
+	// Path is the path of the file relative to the root of the module
+	Path    string
+	Content []byte
+}
+
+// patch defines a snippet added to an existing file
+type patch struct {
+	path    string
+	snippet string
+	// anchor is the start of the line the snippet is added before, or after if after is true
+	anchor string
+	after  bool
+	// last adds the snippet at the last line starting with the anchor instead of the first one
+	last bool
+	// until stops looking for the anchor at the line starting with it, if it's not empty
+	until string
+}
+
+var patches = []patch{
+	{path: "interface/handlers/middleware.go", snippet: keySnippet, anchor: "// MiddlewareValidateNewProduct "},
+	{path: "interface/handlers/middleware.go", snippet: middlewareSnippet, anchor: "// validateBody "},
+	{path: "interface/handlers/docs.go", snippet: docsSnippet, anchor: "// No content is returned"},
+	{path: "main.go", snippet: getRoutesSnippet, anchor: "\tgetR.Handle(", after: true, last: true, until: "\t// handler for documentation"},
+	{path: "main.go", snippet: postRoutesSnippet, anchor: "\tpostR.Handle(", after: true, last: true},
+	{path: "main.go", snippet: putRoutesSnippet, anchor: "\tputR.Handle(", after: true, last: true},
+	{path: "main.go", snippet: deleteRoutesSnippet, anchor: "\tdeleteR.Handle(", after: true, last: true},
+}
+
+// Files returns the new files of the entity
+func (e *Entity) Files() ([]File, error) {
+	templates := []struct {
+		path     string
+		template string
+	}{
+		{"domain/" + e.File() + ".go", domainTemplate},
+		{"domain/" + e.File() + "_test.go", domainTestTemplate},
+		{"data/" + e.File() + ".go", dataTemplate},
+		{"dto/" + e.File() + ".go", dtoTemplate},
+		{"interface/handlers/" + e.File() + ".go", handlersTemplate},
+	}
+	files := []File{}
+	for _, t := range templates {
+		content, err := e.render(t.template)
+		if err != nil {
+			return nil, err
+		}
+		content, err = format.Source(content)
+		if err != nil {
+			return nil, fmt.Errorf("Error formatting %s: %v", t.path, err)
+		}
+		files = append(files, File{Path: t.path, Content: content})
+	}
+	return files, nil
+}
+
+// Patch returns the content of the existing file with the snippets of the entity added to it
+func (e *Entity) Patch(path string, content []byte) ([]byte, error) {
+	text := string(content)
+	for _, p := range patches {
+		if p.path != path {
+			continue
+		}
+		snippet, err := e.render(p.snippet)
+		if err != nil {
+			return nil, err
+		}
+		text, err = insert(text, string(snippet), p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return format.Source([]byte(text))
+}
+
+// Generate writes the new files of the entity and adds the entity to the handlers and the routes of the module in the root directory.
+// Nothing is written if one of the new files exists already
+func (e *Entity) Generate(root string) ([]string, error) {
+	files, err := e.Files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(root, f.Path)); err == nil {
+			return nil, fmt.Errorf("%s exists already", f.Path)
+		}
+	}
+	for _, path := range patchedPaths() {
+		content, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			return nil, err
+		}
+		content, err = e.Patch(path, content)
+		if err != nil {
+			return nil, fmt.Errorf("Error adding %s to %s: %v", e.Name, path, err)
+		}
+		files = append(files, File{Path: path, Content: content})
+	}
+	written := []string{}
+	for _, f := range files {
+		if err := ioutil.WriteFile(filepath.Join(root, f.Path), f.Content, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, f.Path)
+	}
+	return written, nil
+}
+
+// ModulePath returns the import path of the module declared in the go.mod of the root directory
+func ModulePath(root string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("Module path not found in go.mod")
+}
+
+func (e *Entity) render(text string) ([]byte, error) {
+	t, err := template.New(e.Name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	err = t.Execute(&b, e)
+	return b.Bytes(), err
+}
+
+// insert adds the snippet before or after the line starting with the anchor of the patch
+func insert(text string, snippet string, p patch) (string, error) {
+	lines := strings.SplitAfter(text, "\n")
+	index := -1
+	for i, line := range lines {
+		if p.until != "" && strings.HasPrefix(line, p.until) {
+			break
+		}
+		if strings.HasPrefix(line, p.anchor) {
+			index = i
+			if !p.last {
+				break
+			}
+		}
+	}
+	if index < 0 {
+		return "", fmt.Errorf("Line starting with '%s' not found", strings.TrimSpace(p.anchor))
+	}
+	if p.after {
+		index++
+	}
+	return strings.Join(lines[:index], "") + snippet + strings.Join(lines[index:], ""), nil
+}
+
+// patchedPaths returns the paths of the existing files the generator changes
+func patchedPaths() []string {
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, p := range patches {
+		if !seen[p.path] {
+			seen[p.path] = true
+			paths = append(paths, p.path)
+		}
+	}
+	return paths
+}
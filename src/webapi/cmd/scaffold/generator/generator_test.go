@@ -0,0 +1,112 @@
+package generator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/cmd/scaffold/generator"
+)
+
+const module = "github.com/serdarkalayci/goboiler/webapi"
+
+func Test_ParseField(t *testing.T) {
+	field, err := generator.ParseField("dueAt:time:required")
+	if err != nil || field.Name != "DueAt" || field.GoType() != "time.Time" || !field.Required || field.JSONName() != "dueAt" {
+		t.Errorf("Error parsing field. Expected required DueAt of time.Time, got %v (%v)", field, err)
+	}
+	for _, spec := range []string{"name", "name:decimal", "name:string:optional", "id:string", "1st:int"} {
+		if _, err := generator.ParseField(spec); err == nil {
+			t.Errorf("Error parsing invalid field %s. Expected an error, got nil", spec)
+		}
+	}
+}
+
+func Test_NewEntity(t *testing.T) {
+	for _, name := range []string{"orderLine", "Order-Line", "Type", "Data"} {
+		if _, err := generator.NewEntity(name, module, nil); err == nil {
+			t.Errorf("Error creating entity %s. Expected an error, got nil", name)
+		}
+	}
+	if _, err := generator.NewEntity("Supplier", module, []string{"name:string", "name:int"}); err == nil {
+		t.Errorf("Error creating entity with a duplicate field. Expected an error, got nil")
+	}
+}
+
+func Test_Names(t *testing.T) {
+	cases := []struct {
+		name, plural, variable, file, collection, lower string
+	}{
+		{"OrderLine", "OrderLines", "orderLine", "orderline", "orderlines", "order line"},
+		{"Category", "Categories", "category", "category", "categories", "category"},
+		{"Address", "Addresses", "address", "address", "addresses", "address"},
+		{"APIToken", "APITokens", "apiToken", "apitoken", "apitokens", "api token"},
+	}
+	for _, c := range cases {
+		e, err := generator.NewEntity(c.name, module, nil)
+		if err != nil {
+			t.Fatalf("Error creating entity %s: %v", c.name, err)
+		}
+		got := []string{e.Plural(), e.Var(), e.File(), e.Collection(), e.Lower()}
+		expected := []string{c.plural, c.variable, c.file, c.collection, c.lower}
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("Error naming entity %s. Expected %v, got %v", c.name, expected, got)
+		}
+	}
+}
+
+func Test_Files(t *testing.T) {
+	e, _ := generator.NewEntity("Supplier", module, []string{"name:string:required", "rating:float64", "active:bool:required", "since:time"})
+	files, err := e.Files()
+	if err != nil {
+		t.Fatalf("Error generating files. Expected no error, got %v", err)
+	}
+	paths := []string{}
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	expected := "domain/supplier.go domain/supplier_test.go data/supplier.go dto/supplier.go interface/handlers/supplier.go"
+	if strings.Join(paths, " ") != expected {
+		t.Errorf("Error generating files. Expected %s, got %v", expected, paths)
+	}
+	dto := string(files[3].Content)
+	if !strings.Contains(dto, "Name string `json:\"name\" validate:\"required\"`") || !strings.Contains(dto, "Active bool `json:\"active\"`") {
+		t.Errorf("Error generating dto. Expected name to be validated and active not, got\n%s", dto)
+	}
+}
+
+func Test_Patch(t *testing.T) {
+	e, _ := generator.NewEntity("Supplier", module, nil)
+	main := `package main
+
+func main() {
+	getR.Handle("/products", nil)
+	getR.Handle("/audit", nil)
+
+	postR.Handle("/products", nil)
+
+	putR.Handle("/products/{id}", nil)
+
+	deleteR.Handle("/products/{id}", nil)
+
+	// handler for documentation
+	getR.Handle("/docs", nil)
+}
+`
+	patched, err := e.Patch("main.go", []byte(main))
+	if err != nil {
+		t.Fatalf("Error patching main.go. Expected no error, got %v", err)
+	}
+	text := string(patched)
+	get := strings.Index(text, `getR.Handle("/suppliers/{id}"`)
+	if get < strings.Index(text, `getR.Handle("/audit"`) || get > strings.Index(text, `postR.Handle("/products"`) {
+		t.Errorf("Error patching main.go. Expected the GET routes after the last API route, got\n%s", text)
+	}
+	for _, route := range []string{`postR.Handle("/suppliers"`, `putR.Handle("/suppliers/{id}"`, `deleteR.Handle("/suppliers/{id}"`} {
+		if !strings.Contains(text, route) {
+			t.Errorf("Error patching main.go. Expected %s, got\n%s", route, text)
+		}
+	}
+	if _, err := e.Patch("main.go", []byte("package main\n")); err == nil {
+		t.Errorf("Error patching main.go without routes. Expected an error, got nil")
+	}
+}
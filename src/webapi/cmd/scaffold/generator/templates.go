@@ -0,0 +1,392 @@
+package generator
+
+const domainTemplate = `package domain
+{{if .UsesTime}}
+import "time"
+{{end}}
+// {{.Name}}Repository represents an interface for the outer layers to implement the actual low level operations
+type {{.Name}}Repository interface {
+	Store({{.Var}} {{.Name}})
+	Fetch(id string) {{.Name}}
+}
+
+// {{.Name}} defines the structure for {{.A}}
+type {{.Name}} struct {
+	// the id of the {{.Lower}}
+	//
+	// required: true
+	ID string
+{{range .Fields}}
+	// the {{.Description}} of the {{$.Lower}}
+	//
+	// required: {{.Required}}
+	{{.Name}} {{.GoType}}
+{{end}}}
+`
+
+const domainTestTemplate = `package domain_test
+
+import (
+	"testing"
+
+	"{{.Module}}/domain"
+)
+
+func Test_{{.Name}}(t *testing.T) {
+	{{.Var}} := create{{.Name}}("{{.Name}}1")
+	if {{.Var}}.ID != "{{.Name}}1" {
+		t.Errorf("Error creating {{.Lower}}. Expected %s, got %s", "{{.Name}}1", {{.Var}}.ID)
+	}
+}
+
+func create{{.Name}}(id string) domain.{{.Name}} {
+	return domain.{{.Name}}{
+		ID: id,
+	}
+}
+`
+
+const dataTemplate = `package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Err{{.Name}}NotFound is an error raised when {{.A}} can not be found in the database
+var Err{{.Name}}NotFound = fmt.Errorf("{{.Title}} not found")
+
+// {{.Name}} defines the structure for {{.A}}
+// swagger:model
+type {{.Name}} struct {
+	// the id of the {{.Lower}}
+	//
+	// required: false
+	ID primitive.ObjectID ` + "`json:\"id\" bson:\"_id\"`" + `
+{{range .Fields}}
+	// the {{.Description}} of the {{$.Lower}}
+	//
+	// required: {{.Required}}
+	{{.Name}} {{.GoType}} ` + "`json:\"{{.JSONName}}\" bson:\"{{.JSONName}}\"`" + `
+{{end}}
+	// the tenant the {{.Lower}} belongs to when tenants are isolated by field
+	Tenant string ` + "`json:\"-\" bson:\"tenant,omitempty\"`" + `
+}
+
+// Add{{.Name}} inserts the given {{.Name}} into the database and returns it with its new id
+func Add{{.Name}}(ctx context.Context, {{.Var}} {{.Name}}, tenant Tenant, dbClient mongo.Client, dbName string) (*{{.Name}}, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "{{.Collection}}")
+	{{.Var}}.ID = primitive.NewObjectID()
+	{{.Var}}.Tenant = tenant.field()
+	log.Ctx(ctx).Debug().Msgf("Adding the {{.Lower}} to database with id: %s", {{.Var}}.ID.Hex())
+	_, err := collection.InsertOne(ctx, {{.Var}})
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Var}}, nil
+}
+
+// Get{{.Plural}} returns all {{.Plural}} from the database
+func Get{{.Plural}}(ctx context.Context, tenant Tenant, dbClient mongo.Client, dbName string) (*[]{{.Name}}, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "{{.Collection}}")
+	{{.PluralVar}} := []{{.Name}}{}
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var {{.Var}} {{.Name}}
+		err := cur.Decode(&{{.Var}})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into {{.Name}}")
+			return nil, err
+		}
+		{{.PluralVar}} = append({{.PluralVar}}, {{.Var}})
+	}
+	return &{{.PluralVar}}, nil
+}
+
+// Get{{.Name}}ByID returns a single {{.Name}} which matches the id from the database.
+// If {{.A}} is not found this function returns a {{.Name}}NotFound error
+func Get{{.Name}}ByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*{{.Name}}, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "{{.Collection}}")
+	var {{.Var}} {{.Name}}
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"_id": id})).Decode(&{{.Var}})
+	if err == mongo.ErrNoDocuments {
+		return nil, Err{{.Name}}NotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Var}}, nil
+}
+
+// Update{{.Name}} replaces the {{.Name}} which matches the id of the given {{.Name}}.
+// If {{.A}} is not found this function returns a {{.Name}}NotFound error
+func Update{{.Name}}(ctx context.Context, {{.Var}} {{.Name}}, tenant Tenant, dbClient mongo.Client, dbName string) (*{{.Name}}, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "{{.Collection}}")
+	{{.Var}}.Tenant = tenant.field()
+	result, err := collection.ReplaceOne(ctx, tenant.filter(bson.M{"_id": {{.Var}}.ID}), {{.Var}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, Err{{.Name}}NotFound
+	}
+	return &{{.Var}}, nil
+}
+
+// Delete{{.Name}} removes the {{.Name}} which matches the id.
+// If {{.A}} is not found this function returns a {{.Name}}NotFound error
+func Delete{{.Name}}(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "{{.Collection}}")
+	result, err := collection.DeleteOne(ctx, tenant.filter(bson.M{"_id": id}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return Err{{.Name}}NotFound
+	}
+	return nil
+}
+`
+
+const dtoTemplate = `package dto
+{{if .UsesTime}}
+import "time"
+{{end}}
+// {{.Name}} defines the structure for a new {{.Lower}}
+// swagger:model
+type {{.Name}} struct {
+{{- range $i, $f := .Fields}}{{if $i}}
+{{end}}
+	// the {{.Description}} of the {{$.Lower}}
+	//
+	// required: {{.Required}}
+	{{.Name}} {{.GoType}} ` + "`json:\"{{.JSONName}}\"{{.Validation}}`" + `
+{{- end}}
+}
+`
+
+const handlersTemplate = `package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"{{.Module}}/data"
+	"{{.Module}}/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// audit{{.Plural}} is the name of the audited resource of {{.LowerPlural}}
+const audit{{.Plural}} = "{{.Collection}}"
+
+// GetAll{{.Plural}} gets all {{.LowerPlural}}
+// swagger:route GET /{{.Collection}} {{.Plural}} getAll{{.Plural}}
+// Return a list of {{.Name}} from the database
+// responses:
+//	200: {{.Plural}}Response
+//	500: errorResponse
+// GetAll{{.Plural}} handles GET requests
+func (ctx *DBContext) GetAll{{.Plural}}(rw http.ResponseWriter, r *http.Request) {
+	{{.PluralVar}}, err := data.Get{{.Plural}}(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting {{.Plural}}")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON({{.PluralVar}}, rw)
+}
+
+// GetSingle{{.Name}} gets a single {{.Lower}}
+// swagger:route GET /{{.Collection}}/{id} {{.Plural}} getSingle{{.Name}}
+// Return the {{.Name}} which matches the id
+// responses:
+//	200: {{.Name}}Response
+//	404: errorResponse
+// GetSingle{{.Name}} handles GET requests
+func (ctx *DBContext) GetSingle{{.Name}}(rw http.ResponseWriter, r *http.Request) {
+	id, ok := get{{.Name}}ID(rw, r)
+	if !ok {
+		return
+	}
+	{{.Var}}, err := data.Get{{.Name}}ByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		write{{.Name}}Error(rw, r, err)
+		return
+	}
+	data.ToJSON({{.Var}}, rw)
+}
+
+// Add{{.Name}} adds a new {{.Lower}}
+// swagger:route POST /{{.Collection}} {{.Plural}} add{{.Name}}
+// Create a new {{.Name}} in the database
+// responses:
+//	201: {{.Name}}Response
+//	422: errorValidation
+//	500: errorResponse
+// Add{{.Name}} handles POST requests
+func (ctx *DBContext) Add{{.Name}}(rw http.ResponseWriter, r *http.Request) {
+	{{.Var}}DTO := r.Context().Value(Key{{.Name}}{}).(*dto.{{.Name}})
+	{{.Var}}, err := data.Add{{.Name}}(r.Context(), to{{.Name}}Data({{.Var}}DTO), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		write{{.Name}}Error(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditCreate, audit{{.Plural}}, {{.Var}}.ID.Hex(), nil, {{.Var}})
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON({{.Var}}, rw)
+}
+
+// Update{{.Name}} replaces a {{.Lower}}
+// swagger:route PUT /{{.Collection}}/{id} {{.Plural}} update{{.Name}}
+// Update the {{.Name}} in the database
+// responses:
+//	200: {{.Name}}Response
+//	404: errorResponse
+//	422: errorValidation
+// Update{{.Name}} handles PUT requests
+func (ctx *DBContext) Update{{.Name}}(rw http.ResponseWriter, r *http.Request) {
+	id, ok := get{{.Name}}ID(rw, r)
+	if !ok {
+		return
+	}
+	{{.Var}}DTO := r.Context().Value(Key{{.Name}}{}).(*dto.{{.Name}})
+	updated := to{{.Name}}Data({{.Var}}DTO)
+	updated.ID = id
+	before, err := data.Get{{.Name}}ByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var {{.Var}} *data.{{.Name}}
+	if err == nil {
+		{{.Var}}, err = data.Update{{.Name}}(r.Context(), updated, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		write{{.Name}}Error(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditUpdate, audit{{.Plural}}, {{.Var}}.ID.Hex(), before, {{.Var}})
+	data.ToJSON({{.Var}}, rw)
+}
+
+// Delete{{.Name}} removes a {{.Lower}}
+// swagger:route DELETE /{{.Collection}}/{id} {{.Plural}} delete{{.Name}}
+// Remove the {{.Name}} from the database
+// responses:
+//	204: noContentResponse
+//	404: errorResponse
+// Delete{{.Name}} handles DELETE requests
+func (ctx *DBContext) Delete{{.Name}}(rw http.ResponseWriter, r *http.Request) {
+	id, ok := get{{.Name}}ID(rw, r)
+	if !ok {
+		return
+	}
+	before, err := data.Get{{.Name}}ByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err == nil {
+		err = data.Delete{{.Name}}(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		write{{.Name}}Error(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditDelete, audit{{.Plural}}, id.Hex(), before, nil)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// write{{.Name}}Error writes the response of an error raised while handling {{.A}}
+func write{{.Name}}Error(rw http.ResponseWriter, r *http.Request, err error) {
+	if err == data.Err{{.Name}}NotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	log.Ctx(r.Context()).Error().Err(err).Msg("Error handling {{.Name}}")
+
+	rw.WriteHeader(http.StatusInternalServerError)
+	data.ToJSON(&GenericError{Message: err.Error()}, rw)
+}
+
+// get{{.Name}}ID returns the id of the {{.Lower}} from the URL, writing a 404 response if it's not a valid id
+func get{{.Name}}ID(rw http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: data.Err{{.Name}}NotFound.Error()}, rw)
+		return id, false
+	}
+	return id, true
+}
+
+// to{{.Name}}Data converts the {{.Name}} received from the API into the {{.Name}} stored in the database
+func to{{.Name}}Data({{.Var}}DTO *dto.{{.Name}}) data.{{.Name}} {
+	return data.{{.Name}}{
+{{- range .Fields}}
+		{{.Name}}: {{$.Var}}DTO.{{.Name}},
+{{- end}}
+	}
+}
+`
+
+// the snippets below are added to the existing files of the handlers and the routes
+
+const keySnippet = `// Key{{.Name}} is a key used carrying the {{.Name}} object within the context
+type Key{{.Name}} struct{}
+
+`
+
+const middlewareSnippet = `// MiddlewareValidateNew{{.Name}} validates the new {{.Lower}} in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNew{{.Name}}(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "{{.Lower}}", Key{{.Name}}{}, func() interface{} { return &dto.{{.Name}}{} })
+}
+
+`
+
+const docsSnippet = `// A list of {{.LowerPlural}}
+// swagger:response {{.Plural}}Response
+type {{.PluralVar}}ResponseWrapper struct {
+	// All current {{.LowerPlural}}
+	// in: body
+	Body []data.{{.Name}}
+}
+
+// Data structure representing a single {{.Lower}}
+// swagger:response {{.Name}}Response
+type {{.Var}}ResponseWrapper struct {
+	// The {{.Lower}}
+	// in: body
+	Body data.{{.Name}}
+}
+
+`
+
+const getRoutesSnippet = `	getR.Handle("/{{.Collection}}", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetAll{{.Plural}})))
+	getR.Handle("/{{.Collection}}/{id}", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetSingle{{.Name}})))
+`
+
+const postRoutesSnippet = `	postR.Handle("/{{.Collection}}", handlers.Authorize(auth.WriteProducts, dbContext.MiddlewareValidateNew{{.Name}}(http.HandlerFunc(dbContext.Add{{.Name}}))))
+`
+
+const putRoutesSnippet = `	putR.Handle("/{{.Collection}}/{id}", handlers.Authorize(auth.WriteProducts, dbContext.MiddlewareValidateNew{{.Name}}(http.HandlerFunc(dbContext.Update{{.Name}}))))
+`
+
+const deleteRoutesSnippet = `	deleteR.Handle("/{{.Collection}}/{id}", handlers.Authorize(auth.WriteProducts, http.HandlerFunc(dbContext.Delete{{.Name}})))
+`
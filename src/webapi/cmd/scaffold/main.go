@@ -0,0 +1,56 @@
+// scaffold adds a new entity to every layer of the API: the domain type with its repository interface and a test stub,
+// the data functions, the dto, the handlers with their swagger annotations and the routes.
+//
+// Usage:
+//
+//	go run ./cmd/scaffold [-root dir] <Entity> [name:type[:required] ...]
+//
+// The types of the fields are string, int, int64, float64, bool and time. For example
+//
+//	go run ./cmd/scaffold Supplier name:string:required email:string rating:float64 since:time
+//
+// The routes are authorized like the products, reading with products:read and changing with products:write.
+// Run swagger generate spec afterwards to add the entity to swagger.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/serdarkalayci/goboiler/webapi/cmd/scaffold/generator"
+)
+
+func main() {
+	root := flag.String("root", ".", "Root directory of the module the entity is added to")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-root dir] <Entity> [name:type[:required] ...]\n\nTypes: string, int, int64, float64, bool, time\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	module, err := generator.ModulePath(*root)
+	if err != nil {
+		fail(err)
+	}
+	entity, err := generator.NewEntity(flag.Arg(0), module, flag.Args()[1:])
+	if err != nil {
+		fail(err)
+	}
+	written, err := entity.Generate(*root)
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,226 @@
+package configuration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config is the configuration of the server, read from the config file and the environment variables
+type Config struct {
+	Server    ServerConfig
+	Mongo     MongoConfig
+	Auth      AuthConfig
+	Tenancy   TenancyConfig
+	Tracing   TracingConfig
+	Logging   LoggingConfig
+	Health    HealthConfig
+	Products  ProductsConfig
+	Webhooks  WebhooksConfig
+	Analytics AnalyticsConfig
+}
+
+// ServerConfig is the configuration of the listeners of the server
+type ServerConfig struct {
+	// Address is the bind address of the API
+	Address string
+	// AdminAddress is the bind address of the admin server serving the profiles, build info, configuration and routes.
+	// It's disabled if empty, and requires an admin token unless bound to localhost
+	AdminAddress string
+}
+
+// MongoConfig is the configuration of the database
+type MongoConfig struct {
+	ConnectionString string
+	DatabaseName     string
+}
+
+// AuthConfig is the configuration of the authentication of the callers
+type AuthConfig struct {
+	// JWTSecret is the shared secret for validating HS256 bearer tokens
+	JWTSecret string
+	// JWKSFile is the path of the JWKS file holding the public keys for validating RS256 bearer tokens
+	JWKSFile string
+	// Issuer is the expected iss claim of the bearer tokens, which isn't checked if empty
+	Issuer string
+	// Audience is the expected aud claim of the bearer tokens, which isn't checked if empty
+	Audience string
+	// ExemptPaths are the paths which can be called without a bearer token
+	ExemptPaths []string
+}
+
+// TenancyConfig is the configuration of the multi-tenancy, which is disabled if the source is empty
+type TenancyConfig struct {
+	// Source is where the tenant of a request is read from, one of header, claim and subdomain
+	Source string
+	// Header is the header holding the tenant when the source is header
+	Header string
+	// Claim is the claim of the caller holding the tenant
+	Claim string
+	// Isolation is how the data of the tenants are kept apart, one of field and collection
+	Isolation string
+	// ExemptPaths are the paths which are called without a tenant
+	ExemptPaths []string
+}
+
+// TracingConfig is the configuration of the spans. The otlp exporter is configured with the OTEL_EXPORTER_OTLP_* variables
+type TracingConfig struct {
+	// Exporter is where the spans are sent, one of otlp, stdout and none
+	Exporter    string
+	ServiceName string
+	// SampleRatio is the ratio of the traces which are sampled when the caller hasn't sampled them already
+	SampleRatio float64
+}
+
+// LoggingConfig is the configuration of the logs, which is applied again when it changes in the config file
+type LoggingConfig struct {
+	LogLevel struct {
+		// Default is the minimum level of the logs, one of Debug, Info, Warn and Error
+		Default string
+	}
+}
+
+// HealthConfig is the configuration of the health checks
+type HealthConfig struct {
+	// CheckTimeout is the time a health check can take before its component is considered down
+	CheckTimeout time.Duration
+	// CacheTTL is the time the result of a health check is reused before the check is run again
+	CacheTTL time.Duration
+}
+
+// ProductsConfig is the configuration of purging the soft deleted products
+type ProductsConfig struct {
+	// Retention is the time a soft deleted product is kept before it's purged
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// WebhooksConfig is the configuration of the webhook deliveries
+type WebhooksConfig struct {
+	// MaxAttempts is the maximum number of attempts for each delivery
+	MaxAttempts int
+	// Backoff is the wait time before the first retry, doubled after each retry
+	Backoff time.Duration
+}
+
+// AnalyticsConfig is the configuration of the evaluation analytics
+type AnalyticsConfig struct {
+	// FlushInterval is the interval of writing the evaluation counts to the database
+	FlushInterval time.Duration
+}
+
+// defaults are the values of the settings which are neither in the config file nor in the environment variables
+var defaults = map[string]interface{}{
+	"Server.Address":          ":5500",
+	"Server.AdminAddress":     "",
+	"Mongo.ConnectionString":  "mongodb://localhost:27017",
+	"Mongo.DatabaseName":      "goboiler",
+	"Auth.JWTSecret":          "",
+	"Auth.JWKSFile":           "",
+	"Auth.Issuer":             "",
+	"Auth.Audience":           "",
+	"Auth.ExemptPaths":        []string{"/health/live", "/health/ready", "/health/startup", "/metrics"},
+	"Tenancy.Source":          "",
+	"Tenancy.Header":          "X-Tenant-ID",
+	"Tenancy.Claim":           "tenant",
+	"Tenancy.Isolation":       "field",
+	"Tenancy.ExemptPaths":     []string{"/health/live", "/health/ready", "/health/startup", "/metrics"},
+	"Tracing.Exporter":        "otlp",
+	"Tracing.ServiceName":     "GoBoiler.WebApi",
+	"Tracing.SampleRatio":     1.0,
+	logLevel:                  "Info",
+	"Health.CheckTimeout":     2 * time.Second,
+	"Health.CacheTTL":         5 * time.Second,
+	"Products.Retention":      30 * 24 * time.Hour,
+	"Products.PurgeInterval":  time.Hour,
+	"Webhooks.MaxAttempts":    5,
+	"Webhooks.Backoff":        2 * time.Second,
+	"Analytics.FlushInterval": time.Minute,
+}
+
+// legacyVariables are the environment variables the settings were read from before, which are not read anymore, with their settings
+var legacyVariables = map[string]string{
+	"BASE_URL":                 "Server.Address",
+	"ADMIN_BIND_ADDRESS":       "Server.AdminAddress",
+	"ConnectionString":         "Mongo.ConnectionString",
+	"DatabaseName":             "Mongo.DatabaseName",
+	"JWT_SECRET":               "Auth.JWTSecret",
+	"JWKS_FILE":                "Auth.JWKSFile",
+	"AUTH_EXEMPT_PATHS":        "Auth.ExemptPaths",
+	"TENANT_SOURCE":            "Tenancy.Source",
+	"TENANT_HEADER":            "Tenancy.Header",
+	"TENANT_CLAIM":             "Tenancy.Claim",
+	"TENANT_ISOLATION":         "Tenancy.Isolation",
+	"TRACE_EXPORTER":           "Tracing.Exporter",
+	"TRACE_SERVICE_NAME":       "Tracing.ServiceName",
+	"TRACE_SAMPLE_RATIO":       "Tracing.SampleRatio",
+	"HEALTH_CHECK_TIMEOUT":     "Health.CheckTimeout",
+	"HEALTH_CACHE_TTL":         "Health.CacheTTL",
+	"PRODUCT_RETENTION":        "Products.Retention",
+	"PURGE_INTERVAL":           "Products.PurgeInterval",
+	"WEBHOOK_MAX_ATTEMPTS":     "Webhooks.MaxAttempts",
+	"WEBHOOK_BACKOFF":          "Webhooks.Backoff",
+	"ANALYTICS_FLUSH_INTERVAL": "Analytics.FlushInterval",
+}
+
+// checkLegacyVariables returns an error listing the legacy environment variables which are set, with the variables replacing them,
+// so a server isn't started with the default values of the settings it was configured for
+func checkLegacyVariables(lookup func(key string) (string, bool)) error {
+	problems := []string{}
+	for variable, setting := range legacyVariables {
+		if _, ok := lookup(variable); ok {
+			problems = append(problems, fmt.Sprintf("%s is not read anymore, use %s or %s in the config file instead", variable, envVariable(setting), setting))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("Legacy environment variables are set: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// envVariable returns the name of the environment variable overriding the setting, such as GOBOILER_MONGO_CONNECTIONSTRING for Mongo.ConnectionString
+func envVariable(setting string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(setting, ".", "_"))
+}
+
+// Validate returns an error listing every setting which has an invalid value
+func (c Config) Validate() error {
+	problems := []string{}
+	check := func(valid bool, format string, args ...interface{}) {
+		if !valid {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	check(c.Server.Address != "", "Server.Address is required")
+	check(c.Server.AdminAddress == "" || c.Server.AdminAddress != c.Server.Address, "Server.AdminAddress '%s' is the same as Server.Address", c.Server.AdminAddress)
+	check(strings.HasPrefix(c.Mongo.ConnectionString, "mongodb://") || strings.HasPrefix(c.Mongo.ConnectionString, "mongodb+srv://"), "Mongo.ConnectionString should start with mongodb:// or mongodb+srv://")
+	check(c.Mongo.DatabaseName != "", "Mongo.DatabaseName is required")
+	check(oneOf(c.Tenancy.Source, "", "header", "claim", "subdomain"), "Tenancy.Source '%s' is not one of header, claim and subdomain", c.Tenancy.Source)
+	check(c.Tenancy.Source != "header" || c.Tenancy.Header != "", "Tenancy.Header is required when Tenancy.Source is header")
+	check(oneOf(c.Tenancy.Isolation, "field", "collection"), "Tenancy.Isolation '%s' is not one of field and collection", c.Tenancy.Isolation)
+	check(oneOf(c.Tracing.Exporter, "otlp", "stdout", "none"), "Tracing.Exporter '%s' is not one of otlp, stdout and none", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "Tracing.SampleRatio %v is not between 0 and 1", c.Tracing.SampleRatio)
+	check(oneOf(c.Logging.LogLevel.Default, "Debug", "Info", "Warn", "Error"), "Logging.LogLevel.Default '%s' is not one of Debug, Info, Warn and Error", c.Logging.LogLevel.Default)
+	check(c.Health.CheckTimeout > 0, "Health.CheckTimeout should be positive")
+	check(c.Health.CacheTTL >= 0, "Health.CacheTTL should not be negative")
+	check(c.Products.Retention > 0, "Products.Retention should be positive")
+	check(c.Products.PurgeInterval > 0, "Products.PurgeInterval should be positive")
+	check(c.Webhooks.MaxAttempts > 0, "Webhooks.MaxAttempts should be positive")
+	check(c.Webhooks.Backoff >= 0, "Webhooks.Backoff should not be negative")
+	check(c.Analytics.FlushInterval > 0, "Analytics.FlushInterval should be positive")
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func oneOf(value string, values ...string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
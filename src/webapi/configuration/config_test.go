@@ -0,0 +1,45 @@
+package configuration_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/configuration"
+)
+
+func Test_Load(t *testing.T) {
+	t.Setenv("GOBOILER_MONGO_DATABASENAME", "goboiler-test")
+	t.Setenv("GOBOILER_HEALTH_CHECKTIMEOUT", "500ms")
+	t.Setenv("GOBOILER_AUTH_EXEMPTPATHS", "/health/live,/metrics")
+	config, err := configuration.Load()
+	if err != nil {
+		t.Fatalf("Error loading the configuration. Expected no error, got %v", err)
+	}
+	if config.Server.Address != ":5500" || config.Mongo.ConnectionString != "mongodb://localhost:27017" || config.Webhooks.MaxAttempts != 5 {
+		t.Errorf("Error loading the default values. Expected :5500, the local database and 5 attempts, got %+v", config)
+	}
+	if config.Mongo.DatabaseName != "goboiler-test" || config.Health.CheckTimeout != 500*time.Millisecond || len(config.Auth.ExemptPaths) != 2 {
+		t.Errorf("Error overriding the values by the environment variables. Expected goboiler-test, 500ms and 2 exempt paths, got %+v", config)
+	}
+	if len(config.Tenancy.ExemptPaths) != 4 {
+		t.Errorf("Error loading the tenancy exempt paths apart from the auth ones. Expected the 4 default paths, got %v", config.Tenancy.ExemptPaths)
+	}
+
+	t.Setenv("ConnectionString", "mongodb://db:27017")
+	t.Setenv("TRACE_EXPORTER", "stdout")
+	_, err = configuration.Load()
+	if err == nil || !strings.Contains(err.Error(), "ConnectionString is not read anymore, use GOBOILER_MONGO_CONNECTIONSTRING or Mongo.ConnectionString") || !strings.Contains(err.Error(), "TRACE_EXPORTER") {
+		t.Errorf("Error loading the configuration with legacy variables. Expected ConnectionString and TRACE_EXPORTER to be reported, got %v", err)
+	}
+	os.Unsetenv("ConnectionString")
+	os.Unsetenv("TRACE_EXPORTER")
+
+	t.Setenv("GOBOILER_TRACING_EXPORTER", "jaeger")
+	t.Setenv("GOBOILER_TRACING_SAMPLERATIO", "2")
+	_, err = configuration.Load()
+	if err == nil || !strings.Contains(err.Error(), "Tracing.Exporter 'jaeger' is not one of otlp, stdout and none") || !strings.Contains(err.Error(), "Tracing.SampleRatio 2 is not between 0 and 1") {
+		t.Errorf("Error validating the configuration. Expected the exporter and the sample ratio to be reported, got %v", err)
+	}
+}
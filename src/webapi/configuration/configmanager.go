@@ -1,8 +1,14 @@
 package configuration
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -13,9 +19,26 @@ import (
 
 const pathToConfig = "config/livesettings.json"
 const logLevel = "Logging.LogLevel.Default"
+const envPrefix = "GOBOILER"
+
+// configFile is the path of the config file the values are read from, empty if the default values are used
+var configFile string
+
+// Load reads the configuration from the config file, overridden by the environment variables named after the settings
+// with the GOBOILER prefix such as GOBOILER_MONGO_CONNECTIONSTRING for Mongo.ConnectionString, and validates it.
+// It fails if one of the environment variables the settings were read from before, such as ConnectionString, is still set.
+// The log level is applied, and applied again when it changes in the config file
+func Load() (Config, error) {
+	if err := checkLegacyVariables(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	for key, value := range defaults {
+		viper.SetDefault(key, value)
+	}
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
-// SetConfigValues gets configuration values from the file and injects them
-func SetConfigValues() {
 	currentPath, _ := os.Getwd()
 	fullPath := path.Join(currentPath, pathToConfig)
 	viper.SetConfigFile(fullPath)
@@ -25,16 +48,64 @@ func SetConfigValues() {
 	if _, ok := err.(*os.PathError); ok {
 		log.Warn().Msgf("No config file '%s' not found. Using default values", fullPath)
 	} else if err != nil { // Handle other errors that occurred while reading the config file
-		log.Err(err).Msgf("Error while reading the config file")
+		return Config{}, fmt.Errorf("Error while reading the config file: %w", err)
+	} else {
+		configFile = fullPath
+	}
+
+	var config Config
+	if err := viper.Unmarshal(&config); err != nil {
+		return Config{}, fmt.Errorf("Error while reading the configuration: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
 	}
-	log.Info().Msgf("Log Level from config: %s", viper.GetString(logLevel))
-	setLogLevel(viper.GetString(logLevel))
+
+	log.Info().Msgf("Log Level from config: %s", config.Logging.LogLevel.Default)
+	setLogLevel(config.Logging.LogLevel.Default)
 	// monitor the changes in the config file
-	viper.WatchConfig()
-	viper.OnConfigChange(func(e fsnotify.Event) {
-		log.Info().Msgf("Log Level from config: %s", viper.GetString(logLevel))
-		setLogLevel(viper.GetString(logLevel))
-	})
+	if configFile != "" {
+		viper.WatchConfig()
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			log.Info().Msgf("Log Level from config: %s", viper.GetString(logLevel))
+			setLogLevel(viper.GetString(logLevel))
+		})
+	}
+	return config, nil
+}
+
+// Settings returns the values of all the settings by their lower case names, including the changes in the config file since it was loaded.
+// The durations are formatted such as 1m0s
+func Settings() map[string]interface{} {
+	return formatDurations(viper.AllSettings())
+}
+
+func formatDurations(settings map[string]interface{}) map[string]interface{} {
+	for name, value := range settings {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			settings[name] = formatDurations(v)
+		case time.Duration:
+			settings[name] = v.String()
+		}
+	}
+	return settings
+}
+
+// Check returns an error if the watched config file can't be read or is not valid JSON anymore, so its changes can't be applied.
+// It returns nil if the default values are used
+func Check(ctx context.Context) error {
+	if configFile == "" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(content) {
+		return fmt.Errorf("Config file '%s' is not valid JSON", configFile)
+	}
+	return nil
 }
 
 func setLogLevel(level string) {
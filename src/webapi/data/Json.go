@@ -3,8 +3,18 @@ package data
 import (
 	"encoding/json"
 	"io"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// jsonCollection is used for the collections having free form fields received as JSON. It decodes the embedded documents
+// in these fields as maps instead of the default ordered documents, so they're serialized into JSON as they've been received
+var jsonCollection = options.Collection().SetRegistry(
+	bson.NewRegistryBuilder().RegisterTypeMapEntry(bsontype.EmbeddedDocument, reflect.TypeOf(bson.M{})).Build())
+
 // ToJSON serializes the given interface into a string based JSON format
 func ToJSON(i interface{}, w io.Writer) error {
 	e := json.NewEncoder(w)
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EvaluationCount defines the structure for the number of times a feature has been evaluated to a value in a day
+// swagger:model
+type EvaluationCount struct {
+	// the id of the product of the feature
+	//
+	// required: true
+	ProductID primitive.ObjectID `json:"productId" bson:"productId"`
+
+	// the code of the feature
+	//
+	// required: true
+	Code string `json:"code" bson:"code"`
+
+	// shows whether the feature was on
+	//
+	// required: true
+	Enabled bool `json:"enabled" bson:"enabled"`
+
+	// the key of the variant served, only set for multivariate features
+	//
+	// required: false
+	Variant string `json:"variant,omitempty" bson:"variant,omitempty"`
+
+	// the value served, serialized as JSON
+	//
+	// required: true
+	Value string `json:"value" bson:"value"`
+
+	// the number of evaluations
+	//
+	// required: true
+	Count int64 `json:"count" bson:"count"`
+
+	// the day of the evaluations, the counts are kept per day
+	//
+	// required: true
+	Day time.Time `json:"day" bson:"day"`
+
+	// the date of the last evaluation
+	//
+	// required: true
+	LastEvaluated time.Time `json:"lastEvaluated" bson:"lastEvaluated"`
+}
+
+// UnwrittenCountsError is returned when some of the evaluation counts are written and some are not
+type UnwrittenCountsError struct {
+	// Counts are the counts which are not written
+	Counts []EvaluationCount
+	Err    error
+}
+
+func (e *UnwrittenCountsError) Error() string {
+	return fmt.Sprintf("%d evaluation counts are not written: %v", len(e.Counts), e.Err)
+}
+
+func (e *UnwrittenCountsError) Unwrap() error {
+	return e.Err
+}
+
+// AddEvaluationCounts adds the given counts to the stored counts of the same feature, value and day of the tenant.
+// If only some of them fail to be written, the error is an UnwrittenCountsError holding the failed ones
+func AddEvaluationCounts(ctx context.Context, counts []EvaluationCount, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "evaluations")
+	models := []mongo.WriteModel{}
+	for _, c := range counts {
+		filter := tenant.filter(bson.M{"productId": c.ProductID, "code": c.Code, "enabled": c.Enabled, "variant": c.Variant, "value": c.Value, "day": c.Day})
+		update := bson.M{
+			"$inc": bson.M{"count": c.Count},
+			"$max": bson.M{"lastEvaluated": c.LastEvaluated},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+	log.Ctx(ctx).Debug().Msgf("Adding %d evaluation counts to database", len(counts))
+	_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		// the writes are unordered, so every model without a write error is written
+		unwritten := []EvaluationCount{}
+		for _, writeErr := range bulkErr.WriteErrors {
+			unwritten = append(unwritten, counts[writeErr.Index])
+		}
+		return &UnwrittenCountsError{Counts: unwritten, Err: err}
+	}
+	return err
+}
+
+// GetEvaluationCounts returns the daily evaluation counts of the features of the Product which matches the id from the day of since on
+func GetEvaluationCounts(ctx context.Context, productID primitive.ObjectID, since time.Time, tenant Tenant, dbClient mongo.Client, dbName string) (*[]EvaluationCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "evaluations")
+	counts := []EvaluationCount{}
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{"productId": productID, "day": bson.M{"$gte": since.UTC().Truncate(24 * time.Hour)}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var count EvaluationCount
+		err := cur.Decode(&count)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into EvaluationCount")
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return &counts, nil
+}
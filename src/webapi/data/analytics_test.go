@@ -0,0 +1,53 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_AddEvaluationCountsPartialFailure(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("partial failure", func(mt *mtest.T) {
+		counts := []data.EvaluationCount{{Code: "checkout", Value: "true", Count: 3}, {Code: "banner", Value: "false", Count: 2}}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}, {Key: "writeErrors", Value: bson.A{
+			bson.D{{Key: "index", Value: 1}, {Key: "code", Value: 11000}, {Key: "errmsg", Value: "duplicate key"}},
+		}}})
+		err := data.AddEvaluationCounts(context.Background(), counts, data.Tenant{}, *mt.Client, "goboiler")
+		var unwritten *data.UnwrittenCountsError
+		if !errors.As(err, &unwritten) {
+			mt.Fatalf("Error adding the counts. Expected an UnwrittenCountsError, got %v", err)
+		}
+		if len(unwritten.Counts) != 1 || unwritten.Counts[0].Code != "banner" {
+			mt.Errorf("Error adding the counts. Expected the banner count to be unwritten, got %v", unwritten.Counts)
+		}
+	})
+	mt.Run("failure", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 91, Message: "shutting down"}))
+		err := data.AddEvaluationCounts(context.Background(), []data.EvaluationCount{{Code: "checkout"}}, data.Tenant{}, *mt.Client, "goboiler")
+		var unwritten *data.UnwrittenCountsError
+		if err == nil || errors.As(err, &unwritten) {
+			mt.Errorf("Error adding the counts. Expected a failure of every count, got %v", err)
+		}
+	})
+}
+
+func Test_GetEvaluationCountsSince(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("since", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.evaluations", mtest.FirstBatch))
+		since := time.Date(2026, 9, 19, 15, 30, 0, 0, time.UTC)
+		_, err := data.GetEvaluationCounts(context.Background(), primitive.NewObjectID(), since, data.Tenant{}, *mt.Client, "goboiler")
+		filter := startedCommands(mt)["find"].Command.Lookup("filter").Document()
+		day, ok := filter.Lookup("day", "$gte").TimeOK()
+		if err != nil || !ok || !day.Equal(time.Date(2026, 9, 19, 0, 0, 0, 0, time.UTC)) {
+			mt.Errorf("Error filtering the counts by day. Expected day from 2026-09-19, got %v (%v)", filter, err)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrAPIKeyNotFound is an error raised when an API key can not be found in the database
+var ErrAPIKeyNotFound = fmt.Errorf("API key not found")
+
+// APIKeyScope is the enum that enumerates what an API key is allowed to do
+type APIKeyScope string
+
+const (
+	// ReadOnly keys can only make GET requests
+	ReadOnly APIKeyScope = "read"
+	// ReadWrite keys can make every request
+	ReadWrite APIKeyScope = "readwrite"
+)
+
+// APIKey defines the structure for an API key used by service-to-service callers
+// swagger:model
+type APIKey struct {
+	// the id of the API key
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the name describing the owner of the API key
+	//
+	// required: true
+	Name string `json:"name" bson:"name"`
+
+	// the first characters of the key to help recognizing it
+	//
+	// required: false
+	Hint string `json:"hint" bson:"hint"`
+
+	// the SHA-256 hash of the key, the key itself is never stored
+	//
+	// required: true
+	Hash string `json:"-" bson:"hash"`
+
+	// the scope of the API key
+	//
+	// required: true
+	Scope APIKeyScope `json:"scope" bson:"scope"`
+
+	// the products the API key is restricted to, empty for every product. A restricted key can only reach the routes of its products
+	//
+	// required: false
+	Products []primitive.ObjectID `json:"products" bson:"products"`
+
+	// the creation date of the API key
+	//
+	// required: false
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+
+	// the date the API key has last been rotated
+	//
+	// required: false
+	RotatedAt *time.Time `json:"rotatedAt,omitempty" bson:"rotatedAt,omitempty"`
+
+	// the date the API key has been revoked
+	//
+	// required: false
+	RevokedAt *time.Time `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+
+	// the tenant the API key belongs to, empty if multi-tenancy is disabled
+	//
+	// required: false
+	Tenant string `json:"tenant,omitempty" bson:"tenant,omitempty"`
+}
+
+// AddAPIKey inserts the given APIKey of the tenant into the database and returns it with its new id.
+// API keys of every tenant are kept in the same collection, as the tenant is only known after the key is found
+func AddAPIKey(ctx context.Context, apiKey APIKey, tenant Tenant, dbClient mongo.Client, dbName string) (*APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := dbClient.Database(dbName).Collection("apikeys")
+	apiKey.ID = primitive.NewObjectID()
+	apiKey.CreatedAt = time.Now().UTC()
+	apiKey.Tenant = tenant.ID
+	log.Ctx(ctx).Debug().Msgf("Adding the API key to database with id: %s", apiKey.ID.Hex())
+	_, err := collection.InsertOne(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetAPIKeys returns all APIKeys of the tenant from the database, including the revoked ones
+func GetAPIKeys(ctx context.Context, tenant Tenant, dbClient mongo.Client, dbName string) (*[]APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := dbClient.Database(dbName).Collection("apikeys")
+	apiKeys := []APIKey{}
+	cur, err := collection.Find(ctx, apiKeyFilter(bson.M{}, tenant))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var apiKey APIKey
+		err := cur.Decode(&apiKey)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into APIKey")
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return &apiKeys, nil
+}
+
+// GetAPIKeyByHash returns the APIKey which is not revoked and matches the hash from the database, whichever tenant it belongs to.
+// If an APIKey is not found this function returns an APIKeyNotFound error
+func GetAPIKeyByHash(ctx context.Context, hash string, dbClient mongo.Client, dbName string) (*APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := dbClient.Database(dbName).Collection("apikeys")
+	var apiKey APIKey
+	err := collection.FindOne(ctx, bson.M{"hash": hash, "revokedAt": bson.M{"$exists": false}}).Decode(&apiKey)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetAPIKeyByID returns the APIKey of the tenant which matches the id and is not revoked from the database.
+// If an APIKey is not found this function returns an APIKeyNotFound error
+func GetAPIKeyByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := dbClient.Database(dbName).Collection("apikeys")
+	var apiKey APIKey
+	err := collection.FindOne(ctx, apiKeyFilter(bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}}, tenant)).Decode(&apiKey)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RotateAPIKey replaces the hash of the APIKey which matches the id and is not revoked.
+// If an APIKey is not found this function returns an APIKeyNotFound error
+func RotateAPIKey(ctx context.Context, id primitive.ObjectID, hint string, hash string, tenant Tenant, dbClient mongo.Client, dbName string) (*APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := dbClient.Database(dbName).Collection("apikeys")
+	now := time.Now().UTC()
+	filter := apiKeyFilter(bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}}, tenant)
+	update := bson.M{"$set": bson.M{"hint": hint, "hash": hash, "rotatedAt": now}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrAPIKeyNotFound
+	}
+	var apiKey APIKey
+	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(&apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RevokeAPIKey marks the APIKey which matches the id as revoked, after which it can not be used anymore.
+// If an APIKey is not found this function returns an APIKeyNotFound error
+func RevokeAPIKey(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := dbClient.Database(dbName).Collection("apikeys")
+	filter := apiKeyFilter(bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}}, tenant)
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revokedAt": time.Now().UTC()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// apiKeyFilter adds the tenant to the filter when multi-tenancy is enabled
+func apiKeyFilter(filter bson.M, tenant Tenant) bson.M {
+	if tenant.ID != "" {
+		filter["tenant"] = tenant.ID
+	}
+	return filter
+}
@@ -0,0 +1,219 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditEntry defines the structure for the record of a mutating API call
+// swagger:model
+type AuditEntry struct {
+	// the id of the audit entry
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the date of the call
+	//
+	// required: true
+	Date time.Time `json:"date" bson:"date"`
+
+	// the subject of the caller
+	//
+	// required: true
+	Actor string `json:"actor" bson:"actor"`
+
+	// the action taken, such as create, update or delete
+	//
+	// required: true
+	Action string `json:"action" bson:"action"`
+
+	// the kind of the changed resource, such as products
+	//
+	// required: true
+	Resource string `json:"resource" bson:"resource"`
+
+	// the id of the changed resource
+	//
+	// required: true
+	ResourceID string `json:"resourceId" bson:"resourceId"`
+
+	// the state of the resource before the call, empty for creations
+	//
+	// required: false
+	Before map[string]interface{} `json:"before,omitempty" bson:"before,omitempty"`
+
+	// the state of the resource after the call, empty for deletions
+	//
+	// required: false
+	After map[string]interface{} `json:"after,omitempty" bson:"after,omitempty"`
+
+	// the fields changed by the call
+	//
+	// required: false
+	Changes []FieldChange `json:"changes" bson:"changes"`
+
+	// the id of the request
+	//
+	// required: false
+	RequestID string `json:"requestId,omitempty" bson:"requestId,omitempty"`
+
+	// the id of the trace of the request
+	//
+	// required: false
+	TraceID string `json:"traceId,omitempty" bson:"traceId,omitempty"`
+
+	// the tenant the entry belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// FieldChange defines the structure for a single field changed between two states of a document
+// swagger:model
+type FieldChange struct {
+	// the name of the changed field
+	//
+	// required: true
+	Field string `json:"field" bson:"field"`
+
+	// the value of the field in the earlier state
+	//
+	// required: false
+	Before interface{} `json:"before" bson:"before"`
+
+	// the value of the field in the later state
+	//
+	// required: false
+	After interface{} `json:"after" bson:"after"`
+}
+
+// MaxAuditLimit is the maximum number of audit entries returned at once
+const MaxAuditLimit = 1000
+
+// AuditFilter defines the criteria for querying the audit log. Empty fields are not filtered on
+type AuditFilter struct {
+	Resource   string
+	ResourceID string
+	Actor      string
+	From       time.Time
+	To         time.Time
+	// Limit is the maximum number of entries returned, MaxAuditLimit if it's not between 1 and MaxAuditLimit
+	Limit int64
+}
+
+// NewAuditEntry returns an AuditEntry with the JSON representations of before and after and the changes between them.
+// Either of before and after can be nil
+func NewAuditEntry(actor, action, resource, resourceID string, before, after interface{}) AuditEntry {
+	entry := AuditEntry{
+		Date:       time.Now().UTC(),
+		Actor:      actor,
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Before:     toMap(before),
+		After:      toMap(after),
+	}
+	entry.Changes = Diff(entry.Before, entry.After)
+	return entry
+}
+
+// Diff returns the top level fields which differ between the two documents, sorted by field name
+func Diff(before, after map[string]interface{}) []FieldChange {
+	changes := []FieldChange{}
+	for field, b := range before {
+		a, ok := after[field]
+		if !ok || !reflect.DeepEqual(a, b) {
+			changes = append(changes, FieldChange{Field: field, Before: b, After: a})
+		}
+	}
+	for field, a := range after {
+		if _, ok := before[field]; !ok {
+			changes = append(changes, FieldChange{Field: field, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// toMap converts the value into its JSON representation as a map, nil if the value is nil or not an object
+func toMap(i interface{}) map[string]interface{} {
+	if i == nil || (reflect.ValueOf(i).Kind() == reflect.Ptr && reflect.ValueOf(i).IsNil()) {
+		return nil
+	}
+	b, err := json.Marshal(i)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(b, &m) != nil {
+		return nil
+	}
+	return m
+}
+
+// AddAuditEntry inserts the given AuditEntry into the audit log
+func AddAuditEntry(ctx context.Context, entry AuditEntry, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "audit")
+	entry.ID = primitive.NewObjectID()
+	entry.Tenant = tenant.field()
+	_, err := collection.InsertOne(ctx, entry)
+	return err
+}
+
+// GetAuditEntries returns the AuditEntries matching the filter from the audit log, newest first
+func GetAuditEntries(ctx context.Context, filter AuditFilter, tenant Tenant, dbClient mongo.Client, dbName string) (*[]AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "audit", jsonCollection)
+	query := tenant.filter(bson.M{})
+	if filter.Resource != "" {
+		query["resource"] = filter.Resource
+	}
+	if filter.ResourceID != "" {
+		query["resourceId"] = filter.ResourceID
+	}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	date := bson.M{}
+	if !filter.From.IsZero() {
+		date["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		date["$lt"] = filter.To
+	}
+	if len(date) > 0 {
+		query["date"] = date
+	}
+	limit := filter.Limit
+	if limit < 1 || limit > MaxAuditLimit {
+		limit = MaxAuditLimit
+	}
+	opts := options.Find().SetSort(bson.M{"date": -1}).SetLimit(limit)
+	entries := []AuditEntry{}
+	cur, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var entry AuditEntry
+		err := cur.Decode(&entry)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into AuditEntry")
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return &entries, nil
+}
@@ -0,0 +1,51 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_NewAuditEntry(t *testing.T) {
+	id := primitive.NewObjectID()
+	before := &data.Product{ID: id, Name: "Product One", Features: []data.Feature{{Name: "Feature", Code: "feature", Type: data.Bool}}}
+	after := &data.Product{ID: id, Name: "Product Two", Features: before.Features}
+	entry := data.NewAuditEntry("user1", "update", "products", id.Hex(), before, after)
+	if len(entry.Changes) != 1 || entry.Changes[0].Field != "name" {
+		t.Errorf("Error diffing an updated product. Expected a single change on name, got %v", entry.Changes)
+	}
+	if entry.Changes[0].Before != "Product One" || entry.Changes[0].After != "Product Two" {
+		t.Errorf("Error diffing an updated product. Expected Product One -> Product Two, got %v -> %v", entry.Changes[0].Before, entry.Changes[0].After)
+	}
+	var nilProduct *data.Product
+	entry = data.NewAuditEntry("user1", "create", "products", id.Hex(), nilProduct, after)
+	if entry.Before != nil || len(entry.Changes) != len(entry.After) {
+		t.Errorf("Error diffing a created product. Expected every field as a change, got %d changes for %d fields", len(entry.Changes), len(entry.After))
+	}
+}
+
+func Test_GetAuditEntriesLimit(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	tests := []struct {
+		name  string
+		limit int64
+		sent  int64
+	}{
+		{"no limit", 0, data.MaxAuditLimit},
+		{"limit", 10, 10},
+		{"limit over the maximum", data.MaxAuditLimit + 1, data.MaxAuditLimit},
+	}
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.audit", mtest.FirstBatch))
+			_, err := data.GetAuditEntries(context.Background(), data.AuditFilter{Limit: test.limit}, data.Tenant{}, *mt.Client, "goboiler")
+			limit, ok := startedCommands(mt)["find"].Command.Lookup("limit").AsInt64OK()
+			if err != nil || !ok || limit != test.sent {
+				mt.Errorf("Error limiting the audit entries. Expected limit %d, got %d (%v)", test.sent, limit, err)
+			}
+		})
+	}
+}
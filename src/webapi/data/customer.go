@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrCustomerNotFound is an error raised when a customer can not be found in the database
+var ErrCustomerNotFound = fmt.Errorf("Customer not found")
+
+// Customer defines the structure for a customer
+// swagger:model
+type Customer struct {
+	// the id of the customer
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the name of the customer
+	//
+	// required: true
+	Name string `json:"name" bson:"name"`
+
+	// the balance of the customer
+	//
+	// required: false
+	Balance float64 `json:"balance" bson:"balance"`
+
+	// the tenant the customer belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// AddCustomer inserts the given Customer into the database and returns it with its new id
+func AddCustomer(ctx context.Context, customer Customer, tenant Tenant, dbClient mongo.Client, dbName string) (*Customer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "customers")
+	customer.ID = primitive.NewObjectID()
+	customer.Tenant = tenant.field()
+	log.Ctx(ctx).Debug().Msgf("Adding the customer to database with id: %s", customer.ID.Hex())
+	_, err := collection.InsertOne(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetCustomers returns all Customers from the database
+func GetCustomers(ctx context.Context, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Customer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "customers")
+	customers := []Customer{}
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var customer Customer
+		err := cur.Decode(&customer)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Customer")
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return &customers, nil
+}
+
+// GetCustomerByID returns a single Customer which matches the id from the database.
+// If a customer is not found this function returns a CustomerNotFound error
+func GetCustomerByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Customer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "customers")
+	var customer Customer
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"_id": id})).Decode(&customer)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer replaces the Customer which matches the id of the given Customer.
+// If a customer is not found this function returns a CustomerNotFound error
+func UpdateCustomer(ctx context.Context, customer Customer, tenant Tenant, dbClient mongo.Client, dbName string) (*Customer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "customers")
+	customer.Tenant = tenant.field()
+	result, err := collection.ReplaceOne(ctx, tenant.filter(bson.M{"_id": customer.ID}), customer)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrCustomerNotFound
+	}
+	return &customer, nil
+}
+
+// DeleteCustomer removes the Customer which matches the id.
+// If a customer is not found this function returns a CustomerNotFound error
+func DeleteCustomer(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "customers")
+	result, err := collection.DeleteOne(ctx, tenant.filter(bson.M{"_id": id}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCustomerNotFound
+	}
+	return nil
+}
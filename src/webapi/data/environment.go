@@ -0,0 +1,127 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrEnvironmentNotFound is an error raised when an environment can not be found in the database
+var ErrEnvironmentNotFound = fmt.Errorf("Environment not found")
+
+// ErrEnvironmentExists is an error raised when an environment with the same key already exists
+var ErrEnvironmentExists = fmt.Errorf("Environment already exists")
+
+// Environment defines the structure for an environment the features are configured for, such as dev, staging or prod
+// swagger:model
+type Environment struct {
+	// the id of the environment
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the code friendly key of the environment
+	//
+	// required: true
+	Key string `json:"key" bson:"key"`
+
+	// the user friendly name of the environment
+	//
+	// required: true
+	Name string `json:"name" bson:"name"`
+
+	// the position of the environment in the promotion order, such as 0 for dev and 2 for prod
+	//
+	// required: false
+	Order int `json:"order" bson:"order"`
+
+	// the tenant the environment belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// AddEnvironment inserts the given Environment into the database and returns it with its new id.
+// If an Environment with the same key exists this function returns an EnvironmentExists error
+func AddEnvironment(ctx context.Context, environment Environment, tenant Tenant, dbClient mongo.Client, dbName string) (*Environment, error) {
+	_, err := GetEnvironmentByKey(ctx, environment.Key, tenant, dbClient, dbName)
+	if err == nil {
+		return nil, ErrEnvironmentExists
+	}
+	if err != ErrEnvironmentNotFound {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "environments")
+	environment.ID = primitive.NewObjectID()
+	environment.Tenant = tenant.field()
+	log.Ctx(ctx).Debug().Msgf("Adding the environment to database with key: %s", environment.Key)
+	_, err = collection.InsertOne(ctx, environment)
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+// GetEnvironments returns all Environments from the database in their promotion order
+func GetEnvironments(ctx context.Context, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Environment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "environments")
+	environments := []Environment{}
+	opts := options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "key", Value: 1}})
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var environment Environment
+		err := cur.Decode(&environment)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Environment")
+			return nil, err
+		}
+		environments = append(environments, environment)
+	}
+	return &environments, nil
+}
+
+// GetEnvironmentByKey returns a single Environment which matches the key from the database.
+// If an Environment is not found this function returns an EnvironmentNotFound error
+func GetEnvironmentByKey(ctx context.Context, key string, tenant Tenant, dbClient mongo.Client, dbName string) (*Environment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "environments")
+	var environment Environment
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"key": key})).Decode(&environment)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrEnvironmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+// DeleteEnvironment removes the Environment which matches the key.
+// The configuration of the features in the environment is kept, so recreating the environment brings it back.
+// If an Environment is not found this function returns an EnvironmentNotFound error
+func DeleteEnvironment(ctx context.Context, key string, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "environments")
+	result, err := collection.DeleteOne(ctx, tenant.filter(bson.M{"key": key}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrEnvironmentNotFound
+	}
+	return nil
+}
@@ -9,8 +9,8 @@ import (
 )
 
 // GetHealth chects if it can connect to the database and returns error if it's not possible
-func GetHealth(dbClient mongo.Client, dbName string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func GetHealth(ctx context.Context, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	err := dbClient.Ping(ctx, readpref.Primary())
 	return err
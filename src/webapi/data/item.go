@@ -0,0 +1,114 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrItemNotFound is an error raised when an item can not be found in the database
+var ErrItemNotFound = fmt.Errorf("Item not found")
+
+// Item defines the structure for an item sold in the shop
+// swagger:model
+type Item struct {
+	// the id of the item
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the name of the item
+	//
+	// required: true
+	Name string `json:"name" bson:"name"`
+
+	// the price of the item
+	//
+	// required: false
+	Price float64 `json:"price" bson:"price"`
+
+	// the count of the item in the stock
+	//
+	// required: false
+	StockCount int `json:"stockCount" bson:"stockCount"`
+
+	// the tenant the item belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// AddItem inserts the given Item into the database and returns it with its new id
+func AddItem(ctx context.Context, item Item, tenant Tenant, dbClient mongo.Client, dbName string) (*Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "items")
+	item.ID = primitive.NewObjectID()
+	item.Tenant = tenant.field()
+	log.Ctx(ctx).Debug().Msgf("Adding the item to database with id: %s", item.ID.Hex())
+	_, err := collection.InsertOne(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetItems returns all Items from the database
+func GetItems(ctx context.Context, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "items")
+	items := []Item{}
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var item Item
+		err := cur.Decode(&item)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Item")
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &items, nil
+}
+
+// GetItemByID returns a single Item which matches the id from the database.
+// If an item is not found this function returns an ItemNotFound error
+func GetItemByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "items")
+	var item Item
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"_id": id})).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateItem replaces the Item which matches the id of the given Item.
+// If an item is not found this function returns an ItemNotFound error
+func UpdateItem(ctx context.Context, item Item, tenant Tenant, dbClient mongo.Client, dbName string) (*Item, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "items")
+	item.Tenant = tenant.field()
+	result, err := collection.ReplaceOne(ctx, tenant.filter(bson.M{"_id": item.ID}), item)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrItemNotFound
+	}
+	return &item, nil
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandCollection returns the collection the command runs on, which is the value of the command itself
+// for most of the commands, or the collection field for the commands such as getMore
+func CommandCollection(e *event.CommandStartedEvent) string {
+	if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+		return collection
+	}
+	if collection, ok := e.Command.Lookup("collection").StringValueOK(); ok {
+		return collection
+	}
+	return ""
+}
+
+// CommandMonitors returns the command monitor which passes each event to all of the given monitors in order,
+// as the Mongo client accepts a single one
+func CommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func Test_CommandMonitors(t *testing.T) {
+	collections := []string{}
+	record := &event.CommandMonitor{Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+		collections = append(collections, data.CommandCollection(e))
+	}}
+	monitor := data.CommandMonitors(record, &event.CommandMonitor{}, record)
+	command, _ := bson.Marshal(bson.D{{Key: "getMore", Value: int64(42)}, {Key: "collection", Value: "products"}})
+	monitor.Started(context.Background(), &event.CommandStartedEvent{Command: command, CommandName: "getMore"})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{})
+	if len(collections) != 2 || collections[0] != "products" {
+		t.Errorf("Error passing the events to the monitors. Expected products twice, got %v", collections)
+	}
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrOrderNotFound is an error raised when an order can not be found in the database
+var ErrOrderNotFound = fmt.Errorf("Order not found")
+
+// Order defines the structure for an order of a customer
+// swagger:model
+type Order struct {
+	// the id of the order
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the id of the customer the order belongs to
+	//
+	// required: false
+	CustomerID primitive.ObjectID `json:"customerId" bson:"customerId"`
+
+	// the time the order is placed, empty until then
+	//
+	// required: false
+	PlacedAt *time.Time `json:"placedAt,omitempty" bson:"placedAt,omitempty"`
+
+	// the items in the order
+	//
+	// required: false
+	Items []OrderItem `json:"items" bson:"items"`
+
+	// the total price of the items in the order
+	//
+	// required: false
+	Total float64 `json:"total" bson:"total"`
+
+	// the tenant the order belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// OrderItem defines the structure for an item in an order and its count
+type OrderItem struct {
+	// the id of the item
+	//
+	// required: true
+	ItemID primitive.ObjectID `json:"itemId" bson:"itemId"`
+
+	// the name of the item
+	//
+	// required: false
+	Name string `json:"name" bson:"name"`
+
+	// the price of the item
+	//
+	// required: false
+	Price float64 `json:"price" bson:"price"`
+
+	// the count of the item in the order
+	//
+	// required: true
+	Count int `json:"count" bson:"count"`
+}
+
+// AddOrder inserts the given Order into the database and returns it with its new id
+func AddOrder(ctx context.Context, order Order, tenant Tenant, dbClient mongo.Client, dbName string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "orders")
+	order.ID = primitive.NewObjectID()
+	order.Tenant = tenant.field()
+	if order.Items == nil {
+		order.Items = []OrderItem{}
+	}
+	log.Ctx(ctx).Debug().Msgf("Adding the order to database with id: %s", order.ID.Hex())
+	_, err := collection.InsertOne(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrders returns the Orders of the customer from the database, the latest first
+func GetOrders(ctx context.Context, customerID primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "orders")
+	orders := []Order{}
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{"customerId": customerID}), options.Find().SetSort(bson.M{"_id": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var order Order
+		err := cur.Decode(&order)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Order")
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return &orders, nil
+}
+
+// GetOrderByID returns a single Order which matches the id from the database.
+// If an order is not found this function returns an OrderNotFound error
+func GetOrderByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "orders")
+	var order Order
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"_id": id})).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateOrder replaces the Order which matches the id of the given Order.
+// If an order is not found this function returns an OrderNotFound error
+func UpdateOrder(ctx context.Context, order Order, tenant Tenant, dbClient mongo.Client, dbName string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "orders")
+	order.Tenant = tenant.field()
+	result, err := collection.ReplaceOne(ctx, tenant.filter(bson.M{"_id": order.ID}), order)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrOrderNotFound
+	}
+	return &order, nil
+}
@@ -9,11 +9,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ErrProductNotFound is an error raised when a product can not be found in the database
 var ErrProductNotFound = fmt.Errorf("Product not found")
 
+// ErrProductConflict is an error raised when a product is changed by another call while it's being updated
+var ErrProductConflict = fmt.Errorf("Product has been changed by another call")
+
 // Product defines the structure for a product
 // swagger:model
 type Product struct {
@@ -31,6 +35,24 @@ type Product struct {
 	//
 	// required: false
 	Features []Feature `json:"features" bson:"features"`
+
+	// the subjects who can change the product without being an editor
+	//
+	// required: false
+	Owners []string `json:"owners" bson:"owners"`
+
+	// the revision number of the product, incremented on every change
+	//
+	// required: false
+	Revision int `json:"revision" bson:"revision"`
+
+	// the date the product has been soft deleted, empty if it's not deleted
+	//
+	// required: false
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+
+	// the tenant the product belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
 }
 
 // Feature defines the structure for each feature of a product
@@ -56,6 +78,110 @@ type Feature struct {
 	//
 	// required: true
 	Type FlagType `json:"type" bson:"type" validate:"required"`
+
+	// the value served when the feature is off or its environment doesn't set a value.
+	// For multivariate features it's the key of the variant served when the feature is off
+	//
+	// required: false
+	DefaultValue interface{} `json:"defaultValue" bson:"defaultValue"`
+
+	// the variants of a multivariate feature
+	//
+	// required: false
+	Variants []Variant `json:"variants" bson:"variants"`
+
+	// the features which must be on for the feature to be on, possibly of other products
+	//
+	// required: false
+	Prerequisites []Prerequisite `json:"prerequisites" bson:"prerequisites"`
+
+	// the configuration of the feature in each environment, keyed by the environment key
+	//
+	// required: false
+	Environments map[string]FeatureEnvironment `json:"environments" bson:"environments"`
+}
+
+// FeatureEnvironment defines the structure for the configuration of a feature in an environment
+// swagger:model
+type FeatureEnvironment struct {
+	// shows whether the feature is on in the environment
+	//
+	// required: true
+	Enabled bool `json:"enabled" bson:"enabled"`
+
+	// the value served when the feature is on and none of the rules match.
+	// For multivariate features it's the key of a variant, empty to split the callers by the weights of the variants
+	//
+	// required: false
+	Value interface{} `json:"value" bson:"value"`
+
+	// the rules evaluated in order, the first matching rule decides the value
+	//
+	// required: false
+	Rules []Rule `json:"rules" bson:"rules"`
+}
+
+// Rule defines the structure for a targeting rule of a feature
+// swagger:model
+type Rule struct {
+	// the name of the attribute of the evaluation context the rule checks
+	//
+	// required: true
+	Attribute string `json:"attribute" bson:"attribute"`
+
+	// the comparison made, one of equals, notEquals, in and notIn
+	//
+	// required: true
+	Operator string `json:"operator" bson:"operator"`
+
+	// the values the attribute is compared with
+	//
+	// required: true
+	Values []string `json:"values" bson:"values"`
+
+	// the value served when the rule matches.
+	// For multivariate features it's the key of a variant, empty to split the callers by the weights of the variants
+	//
+	// required: false
+	Value interface{} `json:"value" bson:"value"`
+}
+
+// Prerequisite defines the structure for a feature another feature depends on
+// swagger:model
+type Prerequisite struct {
+	// the id of the product of the feature, empty for a feature of the same product
+	//
+	// required: false
+	ProductID *primitive.ObjectID `json:"productId,omitempty" bson:"productId,omitempty"`
+
+	// the code of the feature
+	//
+	// required: true
+	Code string `json:"code" bson:"code"`
+
+	// the key of the variant the feature must serve if it's a multivariate feature, empty for any variant
+	//
+	// required: false
+	Variant string `json:"variant,omitempty" bson:"variant,omitempty"`
+}
+
+// Variant defines the structure for a named value of a multivariate feature
+// swagger:model
+type Variant struct {
+	// the key of the variant
+	//
+	// required: true
+	Key string `json:"key" bson:"key"`
+
+	// the value of the variant
+	//
+	// required: false
+	Value interface{} `json:"value" bson:"value"`
+
+	// the share of the callers the variant is served to when the callers are split
+	//
+	// required: true
+	Weight int `json:"weight" bson:"weight"`
 }
 
 // FlagType is the enum that enumerates the type of feature flag
@@ -68,33 +194,60 @@ const (
 	Int
 	// Date indicates the flag is date bound flag
 	Date
+	// String indicates the flag is a string
+	String
+	// Float indicates the flag is a floating point number
+	Float
+	// JSON indicates the flag is an arbitrary JSON document
+	JSON
+	// Multivariate indicates the flag is one of the named variants of the feature
+	Multivariate
 )
 
+// flagTypeNames are the names of the flag types returned by the evaluation
+var flagTypeNames = map[FlagType]string{
+	Bool:         "bool",
+	Int:          "int",
+	Date:         "date",
+	String:       "string",
+	Float:        "float",
+	JSON:         "json",
+	Multivariate: "multivariate",
+}
+
+// Name returns the name of the flag type
+func (t FlagType) Name() string {
+	return flagTypeNames[t]
+}
+
 // GetProductByID returns a single Product which matches the id from the
-// database.
+// database. Soft deleted products are returned only if includeDeleted is true.
 // If a Product is not found this function returns a ProductNotFound error
-func GetProductByID(id primitive.ObjectID, dbClient mongo.Client, dbName string) (*Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func GetProductByID(ctx context.Context, id primitive.ObjectID, includeDeleted bool, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
-	collection := dbClient.Database(dbName).Collection("products")
+	collection := tenant.collection(dbClient, dbName, "products", jsonCollection)
 	var product Product
 	za := primitive.ObjectID.String(id)
-	log.Debug().Msgf("Getting the project from database with id: %s", za)
-	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&product)
+	log.Ctx(ctx).Debug().Msgf("Getting the project from database with id: %s", za)
+	err := collection.FindOne(ctx, productFilter(tenant.filter(bson.M{"_id": id}), includeDeleted)).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrProductNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-// GetProducts returns all Products from the database.
-func GetProducts(dbClient mongo.Client, dbName string) (*[]Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// GetProducts returns all Products from the database. Soft deleted products are returned only if includeDeleted is true.
+func GetProducts(ctx context.Context, includeDeleted bool, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
-	collection := dbClient.Database(dbName).Collection("products")
+	collection := tenant.collection(dbClient, dbName, "products", jsonCollection)
 	var products []Product
-	log.Debug().Msg("Getting all the projects from database")
-	cur, err := collection.Find(ctx, bson.M{})
+	log.Ctx(ctx).Debug().Msg("Getting all the projects from database")
+	cur, err := collection.Find(ctx, productFilter(tenant.filter(bson.M{}), includeDeleted))
 	if err != nil {
 		return nil, err
 	}
@@ -103,10 +256,170 @@ func GetProducts(dbClient mongo.Client, dbName string) (*[]Product, error) {
 		var product Product
 		err := cur.Decode(&product)
 		if err != nil {
-			log.Error().Err(err).Msg("Result cannot be decoded into Product")
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Product")
 			return nil, err
 		}
 		products = append(products, product)
 	}
 	return &products, nil
 }
+
+// AddProduct inserts the given Product into the database, generating new ids for the product and its features.
+// Returns the inserted Product
+func AddProduct(ctx context.Context, product Product, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "products", jsonCollection)
+	product.ID = primitive.NewObjectID()
+	product.Revision = 1
+	product.Tenant = tenant.field()
+	for i := range product.Features {
+		product.Features[i].ID = primitive.NewObjectID()
+	}
+	log.Ctx(ctx).Debug().Msgf("Adding the product to database with id: %s", product.ID.Hex())
+	_, err := collection.InsertOne(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ImportProduct inserts the given Product into the database keeping its id and the ids of its features, generating the missing ones.
+// Returns the inserted Product
+func ImportProduct(ctx context.Context, product Product, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "products", jsonCollection)
+	if product.ID.IsZero() {
+		product.ID = primitive.NewObjectID()
+	}
+	product.Revision = 1
+	product.DeletedAt = nil
+	product.Tenant = tenant.field()
+	for i := range product.Features {
+		if product.Features[i].ID.IsZero() {
+			product.Features[i].ID = primitive.NewObjectID()
+		}
+	}
+	log.Ctx(ctx).Debug().Msgf("Importing the product to database with id: %s", product.ID.Hex())
+	_, err := collection.InsertOne(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// UpdateProduct replaces the Product which matches the id of the given Product in the database and increments its revision.
+// Features without an id are given a new one.
+// If a Product is not found this function returns a ProductNotFound error,
+// if it's changed by another call meanwhile this function returns a ProductConflict error
+func UpdateProduct(ctx context.Context, product Product, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	current, err := GetProductByID(ctx, product.ID, false, tenant, dbClient, dbName)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "products", jsonCollection)
+	for i := range product.Features {
+		if product.Features[i].ID.IsZero() {
+			product.Features[i].ID = primitive.NewObjectID()
+		}
+	}
+	product.Revision = current.Revision + 1
+	product.Tenant = tenant.field()
+	log.Ctx(ctx).Debug().Msgf("Updating the product in database with id: %s to revision %d", product.ID.Hex(), product.Revision)
+	filter := tenant.filter(bson.M{"_id": product.ID, "revision": current.Revision})
+	if current.Revision == 0 {
+		// products stored before revisions were introduced don't have the field at all
+		filter["revision"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	result, err := collection.ReplaceOne(ctx, filter, product)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrProductConflict
+	}
+	return &product, nil
+}
+
+// DeleteProduct soft deletes the Product which matches the id by marking it with the deletion date and incrementing its revision.
+// If a Product is not found or is already deleted this function returns a ProductNotFound error
+func DeleteProduct(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	return setDeletedAt(ctx, id, false, bson.M{"$set": bson.M{"deletedAt": time.Now().UTC()}, "$inc": bson.M{"revision": 1}}, tenant, dbClient, dbName)
+}
+
+// RestoreProduct removes the deletion mark of the soft deleted Product which matches the id and increments its revision.
+// If a deleted Product is not found this function returns a ProductNotFound error
+func RestoreProduct(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	return setDeletedAt(ctx, id, true, bson.M{"$unset": bson.M{"deletedAt": ""}, "$inc": bson.M{"revision": 1}}, tenant, dbClient, dbName)
+}
+
+// PurgeDeletedProducts removes the Products of the tenant soft deleted before the given date together with their revisions.
+// A Product restored or deleted again while it's purged is kept with its revisions.
+// Returns the number of removed Products
+func PurgeDeletedProducts(ctx context.Context, deletedBefore time.Time, tenant Tenant, dbClient mongo.Client, dbName string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	products := tenant.collection(dbClient, dbName, "products", jsonCollection)
+	filter := func(f bson.M) bson.M {
+		f["deletedAt"] = bson.M{"$lt": deletedBefore}
+		return tenant.filter(f)
+	}
+	cur, err := products.Find(ctx, filter(bson.M{}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var deleted []Product
+	err = cur.All(ctx, &deleted)
+	if err != nil {
+		return 0, err
+	}
+	purged := bson.A{}
+	for _, p := range deleted {
+		var result *mongo.DeleteResult
+		result, err = products.DeleteOne(ctx, filter(bson.M{"_id": p.ID}))
+		if err != nil {
+			break
+		}
+		if result.DeletedCount == 1 {
+			purged = append(purged, p.ID)
+		}
+	}
+	if len(purged) > 0 {
+		// the revisions and the evaluation counts of the purged products are removed with them
+		for _, name := range []string{"productrevisions", "evaluations"} {
+			_, deleteErr := tenant.collection(dbClient, dbName, name).DeleteMany(ctx, tenant.filter(bson.M{"productId": bson.M{"$in": purged}}))
+			if err == nil {
+				err = deleteErr
+			}
+		}
+	}
+	return int64(len(purged)), err
+}
+
+func setDeletedAt(ctx context.Context, id primitive.ObjectID, deleted bool, update bson.M, tenant Tenant, dbClient mongo.Client, dbName string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "products", jsonCollection)
+	filter := tenant.filter(bson.M{"_id": id, "deletedAt": bson.M{"$exists": deleted}})
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var product Product
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// productFilter adds the exclusion of soft deleted products to the filter unless includeDeleted is true
+func productFilter(filter bson.M, includeDeleted bool) bson.M {
+	if !includeDeleted {
+		filter["deletedAt"] = bson.M{"$exists": false}
+	}
+	return filter
+}
@@ -0,0 +1,150 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_GetProductByIDIncludeDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("excludes deleted", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "Product One"}}))
+		product, err := data.GetProductByID(context.Background(), id, false, data.Tenant{}, *mt.Client, "goboiler")
+		if err != nil || product.Name != "Product One" {
+			mt.Errorf("Error getting the product. Expected Product One, got %v (%v)", product, err)
+		}
+		filter := startedCommands(mt)["find"].Command.Lookup("filter").Document()
+		if exists, ok := filter.Lookup("deletedAt", "$exists").BooleanOK(); !ok || exists {
+			mt.Errorf("Error filtering the deleted products. Expected deletedAt not to exist, got %v", filter)
+		}
+	})
+	mt.Run("includes deleted", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, bson.D{{Key: "_id", Value: id}}))
+		_, err := data.GetProductByID(context.Background(), id, true, data.Tenant{}, *mt.Client, "goboiler")
+		filter := startedCommands(mt)["find"].Command.Lookup("filter").Document()
+		if _, lookupErr := filter.LookupErr("deletedAt"); err != nil || lookupErr == nil {
+			mt.Errorf("Error including the deleted products. Expected no deletedAt in the filter, got %v (%v)", filter, err)
+		}
+	})
+	mt.Run("not found", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch))
+		_, err := data.GetProductByID(context.Background(), primitive.NewObjectID(), false, data.Tenant{}, *mt.Client, "goboiler")
+		if err != data.ErrProductNotFound {
+			mt.Errorf("Error getting a missing product. Expected %v, got %v", data.ErrProductNotFound, err)
+		}
+	})
+}
+
+func Test_DeleteAndRestoreProduct(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("delete", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: bson.D{{Key: "_id", Value: id}, {Key: "revision", Value: 2}}}})
+		product, err := data.DeleteProduct(context.Background(), id, data.Tenant{}, *mt.Client, "goboiler")
+		if err != nil || product.Revision != 2 {
+			mt.Errorf("Error deleting the product. Expected revision 2, got %v (%v)", product, err)
+		}
+		command := startedCommands(mt)["findAndModify"].Command
+		if exists, ok := command.Lookup("query", "deletedAt", "$exists").BooleanOK(); !ok || exists {
+			mt.Errorf("Error deleting the product. Expected only a product which is not deleted to match, got %v", command.Lookup("query"))
+		}
+		if _, err := command.Lookup("update", "$set").Document().LookupErr("deletedAt"); err != nil {
+			mt.Errorf("Error deleting the product. Expected deletedAt to be set, got %v", command.Lookup("update"))
+		}
+	})
+	mt.Run("restore", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: bson.D{{Key: "_id", Value: id}, {Key: "revision", Value: 3}}}})
+		_, err := data.RestoreProduct(context.Background(), id, data.Tenant{}, *mt.Client, "goboiler")
+		command := startedCommands(mt)["findAndModify"].Command
+		if exists, ok := command.Lookup("query", "deletedAt", "$exists").BooleanOK(); err != nil || !ok || !exists {
+			mt.Errorf("Error restoring the product. Expected only a deleted product to match, got %v (%v)", command.Lookup("query"), err)
+		}
+		if _, err := command.Lookup("update", "$unset").Document().LookupErr("deletedAt"); err != nil {
+			mt.Errorf("Error restoring the product. Expected deletedAt to be unset, got %v", command.Lookup("update"))
+		}
+	})
+	mt.Run("already deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}})
+		_, err := data.DeleteProduct(context.Background(), primitive.NewObjectID(), data.Tenant{}, *mt.Client, "goboiler")
+		if err != data.ErrProductNotFound {
+			mt.Errorf("Error deleting a deleted product. Expected %v, got %v", data.ErrProductNotFound, err)
+		}
+	})
+}
+
+func Test_PurgeDeletedProducts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("purge", func(mt *mtest.T) {
+		purged, restored := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, bson.D{{Key: "_id", Value: purged}}, bson.D{{Key: "_id", Value: restored}}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+			// the second product is restored after it's found
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 3}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 5}},
+		)
+		deletedBefore := time.Now().Add(-time.Hour)
+		count, err := data.PurgeDeletedProducts(context.Background(), deletedBefore, data.Tenant{}, *mt.Client, "goboiler")
+		if err != nil || count != 1 {
+			mt.Errorf("Error purging the deleted products. Expected 1 product purged, got %d (%v)", count, err)
+		}
+		deletes := []bson.Raw{}
+		for _, e := range startedEvents(mt) {
+			if e.CommandName == "delete" {
+				deletes = append(deletes, e.Command)
+			}
+		}
+		if len(deletes) != 4 {
+			mt.Fatalf("Error purging the deleted products. Expected 4 delete commands, got %d", len(deletes))
+		}
+		for _, d := range deletes[:2] {
+			q := d.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+			if date, ok := q.Lookup("deletedAt", "$lt").DateTimeOK(); !ok || date != deletedBefore.UnixMilli() {
+				mt.Errorf("Error purging the deleted products. Expected only the products deleted before %v to be removed, got %v", deletedBefore, q)
+			}
+		}
+		for i, collection := range []string{"productrevisions", "evaluations"} {
+			ids := deletes[2+i].Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "productId", "$in").Array()
+			values, _ := ids.Values()
+			if d := deletes[2+i].Lookup("delete").StringValue(); d != collection || len(values) != 1 || values[0].ObjectID() != purged {
+				mt.Errorf("Error purging the products. Expected the documents of %s to be removed from %s, got %v from %s", purged.Hex(), collection, ids, d)
+			}
+		}
+	})
+	mt.Run("nothing to purge", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch))
+		count, err := data.PurgeDeletedProducts(context.Background(), time.Now(), data.Tenant{}, *mt.Client, "goboiler")
+		if err != nil || count != 0 || len(startedEvents(mt)) != 1 {
+			mt.Errorf("Error purging without deleted products. Expected nothing removed, got %d (%v)", count, err)
+		}
+	})
+}
+
+// startedEvents returns the commands sent by the mock client
+func startedEvents(mt *mtest.T) []*event.CommandStartedEvent {
+	events := []*event.CommandStartedEvent{}
+	for e := mt.GetStartedEvent(); e != nil; e = mt.GetStartedEvent() {
+		events = append(events, e)
+	}
+	return events
+}
+
+// startedCommands returns the last command of each name sent by the mock client
+func startedCommands(mt *mtest.T) map[string]*event.CommandStartedEvent {
+	commands := map[string]*event.CommandStartedEvent{}
+	for _, e := range startedEvents(mt) {
+		commands[e.CommandName] = e
+	}
+	return commands
+}
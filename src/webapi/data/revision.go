@@ -0,0 +1,122 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrRevisionNotFound is an error raised when a product revision can not be found in the database
+var ErrRevisionNotFound = fmt.Errorf("Revision not found")
+
+// ProductRevision defines the structure for an immutable snapshot of a product
+// swagger:model
+type ProductRevision struct {
+	// the id of the revision document
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the id of the product
+	//
+	// required: true
+	ProductID primitive.ObjectID `json:"productId" bson:"productId"`
+
+	// the revision number of the product
+	//
+	// required: true
+	Revision int `json:"revision" bson:"revision"`
+
+	// the date of the revision
+	//
+	// required: true
+	Date time.Time `json:"date" bson:"date"`
+
+	// the subject of the caller who made the change
+	//
+	// required: false
+	Author string `json:"author" bson:"author"`
+
+	// the state of the product at this revision
+	//
+	// required: true
+	Product Product `json:"product" bson:"product"`
+
+	// the tenant the revision belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// AddProductRevision stores the current state of the product as an immutable revision
+func AddProductRevision(ctx context.Context, product Product, author string, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "productrevisions", jsonCollection)
+	revision := ProductRevision{
+		ID:        primitive.NewObjectID(),
+		ProductID: product.ID,
+		Revision:  product.Revision,
+		Date:      time.Now().UTC(),
+		Author:    author,
+		Product:   product,
+		Tenant:    tenant.field(),
+	}
+	log.Ctx(ctx).Debug().Msgf("Adding revision %d of the product with id: %s", product.Revision, product.ID.Hex())
+	_, err := collection.InsertOne(ctx, revision)
+	return err
+}
+
+// GetProductRevisions returns the revisions of the Product which matches the id, newest first
+func GetProductRevisions(ctx context.Context, productID primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*[]ProductRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "productrevisions", jsonCollection)
+	revisions := []ProductRevision{}
+	opts := options.Find().SetSort(bson.M{"revision": -1})
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{"productId": productID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var revision ProductRevision
+		err := cur.Decode(&revision)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into ProductRevision")
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return &revisions, nil
+}
+
+// GetProductRevision returns the given revision of the Product which matches the id.
+// If the revision is not found this function returns a RevisionNotFound error
+func GetProductRevision(ctx context.Context, productID primitive.ObjectID, revision int, tenant Tenant, dbClient mongo.Client, dbName string) (*ProductRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "productrevisions", jsonCollection)
+	var productRevision ProductRevision
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"productId": productID, "revision": revision})).Decode(&productRevision)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &productRevision, nil
+}
+
+// DiffProductRevisions returns the fields of the product which differ between the two revisions
+func DiffProductRevisions(from, to *ProductRevision) []FieldChange {
+	before, after := toMap(from.Product), toMap(to.Product)
+	// the revision number differs by definition, it's not a change of the product
+	delete(before, "revision")
+	delete(after, "revision")
+	return Diff(before, after)
+}
@@ -0,0 +1,22 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DiffProductRevisions(t *testing.T) {
+	id := primitive.NewObjectID()
+	from := &data.ProductRevision{ProductID: id, Revision: 1, Product: data.Product{ID: id, Name: "Product One", Revision: 1}}
+	to := &data.ProductRevision{ProductID: id, Revision: 2, Product: data.Product{ID: id, Name: "Product One", Revision: 2}}
+	if changes := data.DiffProductRevisions(from, to); len(changes) != 0 {
+		t.Errorf("Error diffing identical revisions. Expected no changes, got %v", changes)
+	}
+	to.Product.Features = []data.Feature{{Name: "Feature", Code: "feature", Type: data.Int}}
+	changes := data.DiffProductRevisions(from, to)
+	if len(changes) != 1 || changes[0].Field != "features" {
+		t.Errorf("Error diffing revisions with a new feature. Expected a single change on features, got %v", changes)
+	}
+}
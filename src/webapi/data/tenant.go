@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Isolation is the enum that enumerates how the data of the tenants are kept apart
+type Isolation string
+
+const (
+	// FieldIsolation keeps the data of every tenant in the same collections, marked with a tenant field
+	FieldIsolation Isolation = "field"
+	// CollectionIsolation keeps the data of every tenant in their own collections, prefixed with the tenant id
+	CollectionIsolation Isolation = "collection"
+)
+
+// Tenant identifies the tenant the data belongs to and how it's isolated.
+// The zero value is used when multi-tenancy is disabled and does not scope anything
+type Tenant struct {
+	ID        string
+	Isolation Isolation
+}
+
+// field returns the value of the tenant field of the documents, which is only set with field isolation
+func (t Tenant) field() string {
+	if t.Isolation == FieldIsolation {
+		return t.ID
+	}
+	return ""
+}
+
+// collection returns the collection with the given name holding the data of the tenant
+func (t Tenant) collection(dbClient mongo.Client, dbName string, name string, opts ...*options.CollectionOptions) *mongo.Collection {
+	if t.Isolation == CollectionIsolation && t.ID != "" {
+		name = t.ID + "_" + name
+	}
+	return dbClient.Database(dbName).Collection(name, opts...)
+}
+
+// filter adds the tenant field to the filter when the data is isolated by field
+func (t Tenant) filter(filter bson.M) bson.M {
+	if f := t.field(); f != "" {
+		filter["tenant"] = f
+	}
+	return filter
+}
+
+// GetTenants returns the tenants which have products in the database with the given isolation.
+// If multi-tenancy is disabled the zero Tenant is the only one
+func GetTenants(ctx context.Context, isolation Isolation, dbClient mongo.Client, dbName string) ([]Tenant, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	db := dbClient.Database(dbName)
+	tenants := []Tenant{}
+	switch isolation {
+	case FieldIsolation:
+		ids, err := db.Collection("products").Distinct(ctx, "tenant", bson.M{"tenant": bson.M{"$exists": true}})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if s, ok := id.(string); ok {
+				tenants = append(tenants, Tenant{ID: s, Isolation: isolation})
+			}
+		}
+	case CollectionIsolation:
+		names, err := db.ListCollectionNames(ctx, bson.M{"name": bson.M{"$regex": "_products$"}})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			tenants = append(tenants, Tenant{ID: strings.TrimSuffix(name, "_products"), Isolation: isolation})
+		}
+	default:
+		tenants = append(tenants, Tenant{})
+	}
+	return tenants, nil
+}
@@ -0,0 +1,272 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSubscriptionNotFound is an error raised when a webhook subscription can not be found in the database
+var ErrSubscriptionNotFound = fmt.Errorf("Subscription not found")
+
+// ErrDeliveryNotFound is an error raised when a webhook delivery can not be found in the database
+var ErrDeliveryNotFound = fmt.Errorf("Delivery not found")
+
+// Subscription defines the structure for a webhook subscription
+// swagger:model
+type Subscription struct {
+	// the id of the subscription
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the url the events will be posted to
+	//
+	// required: true
+	URL string `json:"url" bson:"url"`
+
+	// the secret used to sign the deliveries, never returned by the API
+	//
+	// required: true
+	Secret string `json:"-" bson:"secret"`
+
+	// the list of events the subscription is interested in
+	//
+	// required: true
+	Events []string `json:"events" bson:"events"`
+
+	// the creation date of the subscription
+	//
+	// required: false
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+
+	// the tenant the subscription belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// Delivery defines the structure for a single delivery of an event to a subscription
+// swagger:model
+type Delivery struct {
+	// the id of the delivery
+	//
+	// required: false
+	ID primitive.ObjectID `json:"id" bson:"_id"`
+
+	// the id of the subscription the event is delivered to
+	//
+	// required: true
+	SubscriptionID primitive.ObjectID `json:"subscriptionId" bson:"subscriptionId"`
+
+	// the id of the delivered event, shared by the redeliveries of the same event
+	//
+	// required: true
+	EventID string `json:"eventId" bson:"eventId"`
+
+	// the name of the delivered event
+	//
+	// required: true
+	Event string `json:"event" bson:"event"`
+
+	// the JSON body that has been posted
+	//
+	// required: true
+	Payload string `json:"payload" bson:"payload"`
+
+	// the list of attempts made for this delivery
+	//
+	// required: true
+	Attempts []DeliveryAttempt `json:"attempts" bson:"attempts"`
+
+	// shows whether one of the attempts has succeeded
+	//
+	// required: true
+	Succeeded bool `json:"succeeded" bson:"succeeded"`
+
+	// shows whether the delivery is still being attempted. A delivery left pending by a restart can be redelivered
+	//
+	// required: true
+	Pending bool `json:"pending" bson:"pending"`
+
+	// the date the delivery has been created
+	//
+	// required: true
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+
+	// the tenant the delivery belongs to when tenants are isolated by field
+	Tenant string `json:"-" bson:"tenant,omitempty"`
+}
+
+// DeliveryAttempt defines the structure for each attempt of a delivery
+// swagger:model
+type DeliveryAttempt struct {
+	// the date of the attempt
+	//
+	// required: true
+	Date time.Time `json:"date" bson:"date"`
+
+	// the HTTP status code returned by the receiver, 0 if the request could not be made
+	//
+	// required: false
+	StatusCode int `json:"statusCode" bson:"statusCode"`
+
+	// the error occured while making the request
+	//
+	// required: false
+	Error string `json:"error,omitempty" bson:"error,omitempty"`
+
+	// the duration of the request in milliseconds
+	//
+	// required: true
+	Duration int64 `json:"duration" bson:"duration"`
+}
+
+// AddSubscription inserts the given Subscription into the database and returns it with its new id
+func AddSubscription(ctx context.Context, subscription Subscription, tenant Tenant, dbClient mongo.Client, dbName string) (*Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhooks")
+	subscription.ID = primitive.NewObjectID()
+	subscription.CreatedAt = time.Now().UTC()
+	subscription.Tenant = tenant.field()
+	log.Ctx(ctx).Debug().Msgf("Adding the webhook subscription to database with id: %s", subscription.ID.Hex())
+	_, err := collection.InsertOne(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetSubscriptionByID returns a single Subscription which matches the id from the database.
+// If a Subscription is not found this function returns a SubscriptionNotFound error
+func GetSubscriptionByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhooks")
+	var subscription Subscription
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"_id": id})).Decode(&subscription)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetSubscriptions returns all Subscriptions from the database
+func GetSubscriptions(ctx context.Context, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Subscription, error) {
+	return findSubscriptions(ctx, bson.M{}, tenant, dbClient, dbName)
+}
+
+// GetSubscriptionsForEvent returns the Subscriptions which are interested in the given event
+func GetSubscriptionsForEvent(ctx context.Context, event string, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Subscription, error) {
+	return findSubscriptions(ctx, bson.M{"events": event}, tenant, dbClient, dbName)
+}
+
+// DeleteSubscription removes the Subscription which matches the id together with its delivery log.
+// If a Subscription is not found this function returns a SubscriptionNotFound error
+func DeleteSubscription(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	result, err := tenant.collection(dbClient, dbName, "webhooks").DeleteOne(ctx, tenant.filter(bson.M{"_id": id}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrSubscriptionNotFound
+	}
+	_, err = tenant.collection(dbClient, dbName, "webhookdeliveries").DeleteMany(ctx, tenant.filter(bson.M{"subscriptionId": id}))
+	return err
+}
+
+// AddDelivery inserts the given Delivery into the delivery log
+func AddDelivery(ctx context.Context, delivery Delivery, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhookdeliveries")
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+	delivery.Tenant = tenant.field()
+	_, err := collection.InsertOne(ctx, delivery)
+	return err
+}
+
+// UpdateDelivery replaces the Delivery in the delivery log with the given one, inserting it if it's not there
+func UpdateDelivery(ctx context.Context, delivery Delivery, tenant Tenant, dbClient mongo.Client, dbName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhookdeliveries")
+	delivery.Tenant = tenant.field()
+	_, err := collection.ReplaceOne(ctx, tenant.filter(bson.M{"_id": delivery.ID}), delivery, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetDeliveries returns the delivery log of the Subscription which matches the id, newest first
+func GetDeliveries(ctx context.Context, subscriptionID primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhookdeliveries")
+	deliveries := []Delivery{}
+	opts := options.Find().SetSort(bson.M{"createdAt": -1})
+	cur, err := collection.Find(ctx, tenant.filter(bson.M{"subscriptionId": subscriptionID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var delivery Delivery
+		err := cur.Decode(&delivery)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Delivery")
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return &deliveries, nil
+}
+
+// GetDeliveryByID returns a single Delivery which matches the id from the database.
+// If a Delivery is not found this function returns a DeliveryNotFound error
+func GetDeliveryByID(ctx context.Context, id primitive.ObjectID, tenant Tenant, dbClient mongo.Client, dbName string) (*Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhookdeliveries")
+	var delivery Delivery
+	err := collection.FindOne(ctx, tenant.filter(bson.M{"_id": id})).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func findSubscriptions(ctx context.Context, filter bson.M, tenant Tenant, dbClient mongo.Client, dbName string) (*[]Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	collection := tenant.collection(dbClient, dbName, "webhooks")
+	subscriptions := []Subscription{}
+	cur, err := collection.Find(ctx, tenant.filter(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var subscription Subscription
+		err := cur.Decode(&subscription)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Result cannot be decoded into Subscription")
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return &subscriptions, nil
+}
@@ -1,11 +1,17 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
+
+// ErrInsufficientBalance is an error raised when the balance of the customer is not enough for the amount
+var ErrInsufficientBalance = errors.New("The customer balance cannot go below 0")
 
 // CustomerRepository represents an interface for the outer layers to implement the actual low level operations
 type CustomerRepository interface {
-	Store(customer Customer)
-	Fetch(customerID string) Customer
+	Store(ctx context.Context, customer Customer) error
+	Fetch(ctx context.Context, customerID string) (Customer, error)
 }
 
 // Customer defines the structure for an customer
@@ -13,23 +19,23 @@ type Customer struct {
 	// the id of the customer
 	//
 	// required: true
-	ID string
+	ID string `json:"id"`
 
 	// the name of the customer
 	//
 	// required: true
-	Name string
+	Name string `json:"name"`
 
 	// current balance of the customer within our shop
 	//
 	// required: false
-	Balance float64
+	Balance float64 `json:"balance"`
 }
 
 // Rebalance calculates the current balance of the customer with the given (positive or negative) amount
 func (customer *Customer) Rebalance(amount float64) error {
 	if customer.Balance+amount < 0 {
-		return errors.New("The customer balance cannot go below 0")
+		return ErrInsufficientBalance
 	}
 	customer.Balance += amount
 	return nil
@@ -1,14 +1,21 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"time"
 )
 
+// ErrProductNotInOrder is an error raised when the product to be removed is not in the order
+var ErrProductNotInOrder = errors.New("The order does not contain the Product requested")
+
+// ErrNotEnoughInOrder is an error raised when the order contains less of the product than the count to be removed
+var ErrNotEnoughInOrder = errors.New("The order does not contain enough of the Product requested")
+
 // OrderRepository represents an interface for the outer layers to implement the actual low level operations
 type OrderRepository interface {
-	Store(order Order)
-	Fetch(orderID string) Order
+	Store(ctx context.Context, order Order) error
+	Fetch(ctx context.Context, orderID string) (Order, error)
 }
 
 // Order defines the structure for an order
@@ -16,24 +23,24 @@ type Order struct {
 	// the id of the order
 	//
 	// required: true
-	ID string
+	ID string `json:"id"`
 
 	// the placement date of the order
 	//
 	// required: true
-	Date time.Time
+	Date time.Time `json:"date"`
 	// the list of the products within the order
 	//
 	// required: true
-	Items []OrderItem
+	Items []OrderItem `json:"items"`
 	// the total value of added items
 	//
 	// required: true
-	Total float64
+	Total float64 `json:"total"`
 	// the customer refenrence of the order
 	//
 	// required: true
-	Customer Customer
+	Customer Customer `json:"customer"`
 }
 
 // OrderItem represents the products and their counts to be added to the order
@@ -41,41 +48,51 @@ type OrderItem struct {
 	// describes how many of this produıct will be added to order
 	//
 	// required: true
-	ItemCount int
+	ItemCount int `json:"count"`
 	// describes which product will be added to the order
 	//
 	// required: true
-	Item Product
+	Item Product `json:"item"`
 }
 
 // AddProduct adds new Product and increase the count if the order already has that spesific product
-// The Product is passed as OrderItem which includes the Product and the count to be added
-// Returns an error if the Customer's balance is not enough for the requested amount
-func (order *Order) AddProduct(orderItem OrderItem) error {
+// The Product is passed as OrderItem which includes the Product and the count to be added, its stock count is decreased
+// The price of the order line becomes the average price paid if the price of the Product has changed since it was added
+// Returns ErrOutOfStock if the Product's stock count is below the requested count
+// Returns ErrInsufficientBalance if the Customer's balance is not enough for the requested amount
+func (order *Order) AddProduct(orderItem *OrderItem) error {
+	err := orderItem.Item.Unshelf(orderItem.ItemCount)
+	if err != nil {
+		return err
+	}
 	newAmount := float64(orderItem.ItemCount) * orderItem.Item.Price
-	err := order.Customer.Rebalance(newAmount * -1)
+	err = order.Customer.Rebalance(newAmount * -1)
 	if err != nil {
-		return errors.New("Customer balance is not enough for adding these items")
+		orderItem.Item.Shelf(orderItem.ItemCount)
+		return err
 	}
-	err = orderItem.Item.Unshelf(orderItem.ItemCount)
 	found := false
 	for i := 0; i < len(order.Items) && found == false; i++ {
 		if order.Items[i].Item.ID == orderItem.Item.ID {
-			order.Items[i].ItemCount += orderItem.ItemCount
+			line := &order.Items[i]
+			paid := float64(line.ItemCount)*line.Item.Price + newAmount
+			line.ItemCount += orderItem.ItemCount
+			line.Item.Price = paid / float64(line.ItemCount)
 			found = true
 		}
 	}
 	if !found {
-		order.Items = append(order.Items, orderItem)
+		order.Items = append(order.Items, *orderItem)
 	}
 	order.Total += newAmount
 	return nil
 }
 
 // RemoveProduct decrease the count od a Product in the order
-// The Product is passed as OrderItem which includes the Product and the count to be added
+// The Product is passed as OrderItem which includes the Product and the count to be removed, its stock count is increased
+// The Customer is refunded the price on the order line, which is the price paid rather than the current price of the Product
 // Returns an error if the Order does not contain that Product or the count is already below the requested amount
-func (order *Order) RemoveProduct(orderItem OrderItem) error {
+func (order *Order) RemoveProduct(orderItem *OrderItem) error {
 	found := false
 	i := 0
 	for i = 0; i < len(order.Items) && found == false; i++ {
@@ -84,17 +101,18 @@ func (order *Order) RemoveProduct(orderItem OrderItem) error {
 		}
 	}
 	if !found {
-		return errors.New("The order does not contain the Product requested")
+		return ErrProductNotInOrder
 	}
+	price := order.Items[i-1].Item.Price
 	if order.Items[i-1].ItemCount < orderItem.ItemCount { // There's not enough items to be removed
-		return errors.New("The order does not contain enough of the Product requested")
+		return ErrNotEnoughInOrder
 	} else if order.Items[i-1].ItemCount == orderItem.ItemCount { // There's exactly the number of items to be removed
 		order.Items = append(order.Items[:i-1], order.Items[i:]...) // Remove that item from the array completely
 	} else { // There're more items than to be removed
 		order.Items[i-1].ItemCount -= orderItem.ItemCount
 	}
 	orderItem.Item.Shelf(orderItem.ItemCount)
-	newAmount := float64(orderItem.ItemCount) * orderItem.Item.Price
+	newAmount := float64(orderItem.ItemCount) * price
 	order.Customer.Rebalance(newAmount)
 	order.Total -= newAmount
 	return nil
@@ -12,55 +12,92 @@ func Test_AddProduct(t *testing.T) {
 	order := createOrder("Order1", customer)
 	product1 := createProduct("Product1", "Product One", 7.75, 20)
 	orderItem1 := createOrderItem(product1, 2)
-	err := order.AddProduct(orderItem1)
+	err := order.AddProduct(&orderItem1)
 	if err != nil || order.Total != 15.50 || order.Customer.Balance != 14.50 {
 		t.Errorf("Error while adding first items. Total expected: 15.50 got: %f, Customer balance expected: 14.50 got: %f", order.Total, order.Customer.Balance)
 	}
+	if orderItem1.Item.StockCount != 18 {
+		t.Errorf("Error while adding first items. Stock count expected: 18 got: %d", orderItem1.Item.StockCount)
+	}
 	product2 := createProduct("Product2", "Product Two", 1.25, 20)
 	orderItem2 := createOrderItem(product2, 2)
-	err = order.AddProduct(orderItem2)
+	err = order.AddProduct(&orderItem2)
 	if err != nil || order.Total != 18 || order.Customer.Balance != 12 {
 		t.Errorf("Error while adding new items. Total expected: 18.00 got: %f, Customer balance expected: 12.00 got: %f", order.Total, order.Customer.Balance)
 	}
-	err = order.AddProduct(orderItem1)
+	err = order.AddProduct(&orderItem1)
 	if err == nil || order.Total != 18 || order.Customer.Balance != 12 {
 		t.Errorf("Error while adding items that is out of Customer's balance. Total expected: 18.00 got: %f, Customer balance expected: 12.00 got: %f", order.Total, order.Customer.Balance)
 	}
-	err = order.AddProduct(orderItem2)
+	err = order.AddProduct(&orderItem2)
 	if err != nil || order.Total != 20.50 || order.Customer.Balance != 9.50 {
 		t.Errorf("Error while adding items of the same kind. Total expected: 20.50 got: %f, Customer balance expected: 9.50 got: %f", order.Total, order.Customer.Balance)
 	}
 }
 
+func Test_AddProductOutOfStock(t *testing.T) {
+	customer := createCustomer("Customer1", "Customer Name1", 30)
+	order := createOrder("Order1", customer)
+	product := createProduct("Product1", "Product One", 1.25, 2)
+	err := order.AddProduct(&domain.OrderItem{Item: product, ItemCount: 3})
+	if err != domain.ErrOutOfStock || order.Total != 0 || order.Customer.Balance != 30 || len(order.Items) != 0 {
+		t.Errorf("Error while adding items that are out of stock. Expected %v with the Customer's balance unchanged, got %v with balance %f", domain.ErrOutOfStock, err, order.Customer.Balance)
+	}
+	product = createProduct("Product2", "Product Two", 40, 5)
+	err = order.AddProduct(&domain.OrderItem{Item: product, ItemCount: 1})
+	if err != domain.ErrInsufficientBalance || order.Total != 0 {
+		t.Errorf("Error while adding items that are out of Customer's balance. Expected %v, got %v", domain.ErrInsufficientBalance, err)
+	}
+}
+
 func Test_RemoveProduct(t *testing.T) {
 	customer := createCustomer("Customer1", "Customer Name1", 30)
 	order := createOrder("Order1", customer)
 	product1 := createProduct("Product1", "Product One", 7.75, 20)
 	orderItem1 := createOrderItem(product1, 3)
-	order.AddProduct(orderItem1)
+	order.AddProduct(&orderItem1)
 	product2 := createProduct("Product2", "Product Two", 1.25, 20)
 	orderItem2 := createOrderItem(product2, 2)
 	removeItem := createOrderItem(product1, 1)
-	err := order.RemoveProduct(removeItem)
+	err := order.RemoveProduct(&removeItem)
 	if err != nil || order.Total != 15.50 || order.Customer.Balance != 14.50 {
 		t.Errorf("Error while removing first items. Total expected: 15.50 got: %f, Customer balance expected: 14.50 got: %f", order.Total, order.Customer.Balance)
 	}
-	err = order.RemoveProduct(orderItem2)
-	if err == nil || order.Total != 15.50 || order.Customer.Balance != 14.50 {
+	err = order.RemoveProduct(&orderItem2)
+	if err != domain.ErrProductNotInOrder || order.Total != 15.50 || order.Customer.Balance != 14.50 {
 		t.Errorf("Error while trying to remove non-order items. Total expected: 15.50 got: %f, Customer balance expected: 14.50 got: %f", order.Total, order.Customer.Balance)
 	}
 	removeItem = createOrderItem(product1, 3)
-	err = order.RemoveProduct(removeItem)
+	err = order.RemoveProduct(&removeItem)
 	if err == nil || order.Total != 15.50 || order.Customer.Balance != 14.50 {
 		t.Errorf("Error while trying to remove items more than those included in the Order. Total expected: 15.50 got: %f, Customer balance expected: 14.50 got: %f", order.Total, order.Customer.Balance)
 	}
 	removeItem = createOrderItem(product1, 2)
-	err = order.RemoveProduct(removeItem)
+	err = order.RemoveProduct(&removeItem)
 	if err != nil || order.Total != 0 || order.Customer.Balance != 30 || len(order.Items) != 0 {
 		t.Errorf("Error while trying to remove all items in the Order. Total expected: 0 got: %f, Customer balance expected: 30 got: %f", order.Total, order.Customer.Balance)
 	}
 }
 
+func Test_AddProductAfterPriceChange(t *testing.T) {
+	customer := createCustomer("Customer1", "Customer Name1", 30)
+	order := createOrder("Order1", customer)
+	product := createProduct("Product1", "Product One", 2, 20)
+	orderItem := createOrderItem(product, 2)
+	order.AddProduct(&orderItem)
+	orderItem.Item.Price = 8
+	addItem := createOrderItem(orderItem.Item, 2)
+	err := order.AddProduct(&addItem)
+	if err != nil || order.Total != 20 || order.Items[0].Item.Price != 5 {
+		t.Errorf("Error while adding items after a price change. Total expected: 20 got: %f, line price expected: 5 got: %f", order.Total, order.Items[0].Item.Price)
+	}
+	removeItem := createOrderItem(addItem.Item, 4)
+	err = order.RemoveProduct(&removeItem)
+	if err != nil || order.Total != 0 || order.Customer.Balance != 30 {
+		t.Errorf("Error while removing items added at different prices. Total expected: 0 got: %f, Customer balance expected: 30 got: %f", order.Total, order.Customer.Balance)
+	}
+}
+
 func createOrder(id string, customer domain.Customer) domain.Order {
 	return domain.Order{
 		ID:       id,
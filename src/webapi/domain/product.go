@@ -1,43 +1,47 @@
 package domain
 
 import (
+	"context"
 	"errors"
 )
 
+// ErrOutOfStock is an error raised when the stock count of the product is below the requested count
+var ErrOutOfStock = errors.New("Not enough of that product in the stock")
+
 // Product defines the structure for a product
 type Product struct {
 	// the id of the product
 	//
 	// required: true
-	ID string
+	ID string `json:"id"`
 
 	// the name of the product
 	//
 	// required: true
-	Name string
+	Name string `json:"name"`
 
 	// the Price of the product
 	//
 	// required: false
-	Price float64
+	Price float64 `json:"price"`
 
 	// The count of items in the stock
 	//
 	// required: true
-	StockCount int
+	StockCount int `json:"stockCount"`
 }
 
 // ProductRepository represents an interface for the outer layers to implement the actual low level operations
 type ProductRepository interface {
-	Store(product Product)
-	Fetch(id string) Product
+	Store(ctx context.Context, product Product) error
+	Fetch(ctx context.Context, id string) (Product, error)
 }
 
 // Unshelf removes product from the stock when it's added to an order
 // Returns error when the requested count is above the stock count
 func (product *Product) Unshelf(count int) error {
 	if product.StockCount < count {
-		return errors.New("Not enough of that product in the stock")
+		return ErrOutOfStock
 	}
 	product.StockCount -= count
 	return nil
@@ -0,0 +1,37 @@
+package dto
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// APIKey defines the structure for a new API key
+// swagger:model NewAPIKey
+type APIKey struct {
+	// the name describing the owner of the API key
+	//
+	// required: true
+	Name string `json:"name" validate:"required"`
+
+	// the scope of the API key, either read or readwrite
+	//
+	// required: true
+	Scope string `json:"scope" validate:"required,oneof=read readwrite"`
+
+	// the products the API key is restricted to, empty for every product. A restricted key can only reach the routes of its products
+	//
+	// required: false
+	Products []primitive.ObjectID `json:"products"`
+}
+
+// IssuedAPIKey defines the structure returned when an API key is issued or rotated.
+// This is the only time the key itself is returned
+// swagger:model
+type IssuedAPIKey struct {
+	// the id of the API key
+	//
+	// required: true
+	ID primitive.ObjectID `json:"id"`
+
+	// the key to be sent in the X-API-Key header
+	//
+	// required: true
+	Key string `json:"key"`
+}
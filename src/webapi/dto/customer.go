@@ -0,0 +1,15 @@
+package dto
+
+// Customer defines the structure for a new customer
+// swagger:model NewCustomer
+type Customer struct {
+	// the name of the customer
+	//
+	// required: true
+	Name string `json:"name" validate:"required"`
+
+	// the balance of the customer
+	//
+	// required: false
+	Balance float64 `json:"balance" validate:"min=0"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "net/url"
+
+// Environment defines the structure for a new environment
+// swagger:model NewEnvironment
+type Environment struct {
+	// the code friendly key of the environment
+	//
+	// required: true
+	Key string `json:"key" validate:"required,alphanum,lowercase"`
+
+	// the user friendly name of the environment
+	//
+	// required: true
+	Name string `json:"name" validate:"required"`
+
+	// the position of the environment in the promotion order, such as 0 for dev and 2 for prod
+	//
+	// required: false
+	Order int `json:"order" validate:"min=0"`
+}
+
+// Evaluation defines the structure for a request to evaluate the features of a product
+// swagger:model
+type Evaluation struct {
+	// the key of the environment the features are evaluated in
+	//
+	// required: true
+	Environment string `json:"environment" validate:"required"`
+
+	// the attributes of the caller the targeting rules are checked against, such as userId or country
+	//
+	// required: false
+	Attributes map[string]string `json:"attributes"`
+}
+
+// ApplyQuery overrides the environment in the body with the environment query parameter if it's given
+func (e *Evaluation) ApplyQuery(query url.Values) {
+	if env := query.Get("environment"); env != "" {
+		e.Environment = env
+	}
+}
@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// validateFeature checks the values of the feature against its type and the variants of multivariate features
+func validateFeature(sl validator.StructLevel) {
+	feature := sl.Current().Interface().(Feature)
+	if feature.Type == Multivariate {
+		validateVariants(sl, feature.Variants)
+	}
+	if !validValue(feature, feature.DefaultValue) {
+		sl.ReportError(feature.DefaultValue, "DefaultValue", "DefaultValue", "flagvalue", "")
+	}
+	for key, env := range feature.Environments {
+		if !validValue(feature, env.Value) {
+			name := fmt.Sprintf("Environments[%s].Value", key)
+			sl.ReportError(env.Value, name, name, "flagvalue", "")
+		}
+		for i, rule := range env.Rules {
+			if !validValue(feature, rule.Value) {
+				name := fmt.Sprintf("Environments[%s].Rules[%d].Value", key, i)
+				sl.ReportError(rule.Value, name, name, "flagvalue", "")
+			}
+		}
+	}
+}
+
+// validateVariants checks that a multivariate feature has variants with unique keys and a positive total weight
+func validateVariants(sl validator.StructLevel, variants []Variant) {
+	if len(variants) == 0 {
+		sl.ReportError(variants, "Variants", "Variants", "required", "")
+		return
+	}
+	keys := map[string]bool{}
+	total := 0
+	for i, v := range variants {
+		if keys[v.Key] {
+			name := fmt.Sprintf("Variants[%d].Key", i)
+			sl.ReportError(v.Key, name, name, "unique", "")
+		}
+		keys[v.Key] = true
+		total += v.Weight
+	}
+	if total <= 0 {
+		sl.ReportError(variants, "Variants", "Variants", "weights", "")
+	}
+}
+
+// validValue checks if the value can be served by the feature. An empty value is always valid
+func validValue(feature Feature, value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch feature.Type {
+	case Bool:
+		_, ok := value.(bool)
+		return ok
+	case Int:
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case Float:
+		_, ok := value.(float64)
+		return ok
+	case String:
+		_, ok := value.(string)
+		return ok
+	case Date:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case JSON:
+		return true
+	case Multivariate:
+		key, ok := value.(string)
+		if !ok {
+			return false
+		}
+		for _, v := range feature.Variants {
+			if v.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+)
+
+func Test_ValidateFeature(t *testing.T) {
+	variants := []dto.Variant{{Key: "blue", Weight: 50}, {Key: "green", Weight: 50}}
+	tests := []struct {
+		name    string
+		feature dto.Feature
+		// the tags of the failed validations, empty if the feature is valid
+		tags []string
+	}{
+		{"bool", dto.Feature{Type: dto.Bool, DefaultValue: true}, nil},
+		{"bool as string", dto.Feature{Type: dto.Bool, DefaultValue: "true"}, []string{"flagvalue"}},
+		{"empty value", dto.Feature{Type: dto.Int}, nil},
+		{"int", dto.Feature{Type: dto.Int, DefaultValue: float64(3)}, nil},
+		{"float as int", dto.Feature{Type: dto.Int, DefaultValue: 3.5}, []string{"flagvalue"}},
+		{"int as float", dto.Feature{Type: dto.Float, DefaultValue: float64(3)}, nil},
+		{"float", dto.Feature{Type: dto.Float, DefaultValue: 3.5}, nil},
+		{"string as float", dto.Feature{Type: dto.Float, DefaultValue: "3.5"}, []string{"flagvalue"}},
+		{"string", dto.Feature{Type: dto.String, DefaultValue: "on"}, nil},
+		{"date", dto.Feature{Type: dto.Date, DefaultValue: "2026-10-19T12:00:00Z"}, nil},
+		{"date without time", dto.Feature{Type: dto.Date, DefaultValue: "2026-10-19"}, []string{"flagvalue"}},
+		{"date with a bad month", dto.Feature{Type: dto.Date, DefaultValue: "2026-13-19T12:00:00Z"}, []string{"flagvalue"}},
+		{"date as number", dto.Feature{Type: dto.Date, DefaultValue: float64(20261019)}, []string{"flagvalue"}},
+		{"json", dto.Feature{Type: dto.JSON, DefaultValue: map[string]interface{}{"a": 1}}, nil},
+		{"multivariate", dto.Feature{Type: dto.Multivariate, Variants: variants, DefaultValue: "blue"}, nil},
+		{"unknown variant", dto.Feature{Type: dto.Multivariate, Variants: variants, DefaultValue: "red"}, []string{"flagvalue"}},
+		{"variant as number", dto.Feature{Type: dto.Multivariate, Variants: variants, DefaultValue: float64(1)}, []string{"flagvalue"}},
+		{"no variants", dto.Feature{Type: dto.Multivariate}, []string{"required"}},
+		{"duplicate variants", dto.Feature{Type: dto.Multivariate, Variants: []dto.Variant{{Key: "blue", Weight: 1}, {Key: "blue", Weight: 1}}}, []string{"unique"}},
+		{"zero total weight", dto.Feature{Type: dto.Multivariate, Variants: []dto.Variant{{Key: "blue"}, {Key: "green"}}}, []string{"weights"}},
+		{"unknown variant in an environment", dto.Feature{Type: dto.Multivariate, Variants: variants, Environments: map[string]dto.FeatureEnvironment{
+			"prod": {Enabled: true, Value: "red"},
+		}}, []string{"flagvalue"}},
+		{"float in a rule of an int", dto.Feature{Type: dto.Int, Environments: map[string]dto.FeatureEnvironment{
+			"prod": {Enabled: true, Value: float64(1), Rules: []dto.Rule{{Attribute: "country", Operator: "in", Values: []string{"NL"}, Value: 1.5}}},
+		}}, []string{"flagvalue"}},
+	}
+	v := dto.NewValidation()
+	for _, test := range tests {
+		test.feature.Name, test.feature.Code = "Feature", "feature"
+		errs := v.Validate(&test.feature)
+		tags := []string{}
+		for _, err := range errs {
+			tags = append(tags, err.Tag())
+		}
+		if len(tags) != len(test.tags) {
+			t.Errorf("Error validating the %s feature. Expected %v, got %v", test.name, test.tags, errs.Errors())
+			continue
+		}
+		for i := range tags {
+			if tags[i] != test.tags[i] {
+				t.Errorf("Error validating the %s feature. Expected %v, got %v", test.name, test.tags, errs.Errors())
+			}
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+// Item defines the structure for a new item of the shop
+// swagger:model NewItem
+type Item struct {
+	// the name of the item
+	//
+	// required: true
+	Name string `json:"name" validate:"required"`
+
+	// the price of the item
+	//
+	// required: false
+	Price float64 `json:"price" validate:"min=0"`
+
+	// the count of the item in the stock
+	//
+	// required: false
+	StockCount int `json:"stockCount" validate:"min=0"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+// OrderItem defines the structure for adding an item to an order
+// swagger:model NewOrderItem
+type OrderItem struct {
+	// the id of the item
+	//
+	// required: true
+	ItemID string `json:"itemId" validate:"required"`
+
+	// the count of the item to be added
+	//
+	// required: true
+	Count int `json:"count" validate:"min=1"`
+}
@@ -3,7 +3,7 @@ package dto
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 // Product defines the structure for a product
-// swagger:model
+// swagger:model NewProduct
 type Product struct {
 	// the id of the product
 	//
@@ -18,7 +18,12 @@ type Product struct {
 	// the ISBNFeature list of the product
 	//
 	// required: false
-	Features []Feature `json:"features" bson:"features"`
+	Features []Feature `json:"features" bson:"features" validate:"dive"`
+
+	// the subjects who can change the product without being an editor
+	//
+	// required: false
+	Owners []string `json:"owners" bson:"owners"`
 }
 
 // Feature defines the structure for each feature of a product
@@ -43,7 +48,111 @@ type Feature struct {
 	// the code friendly name of the feature
 	//
 	// required: true
-	Type FlagType `json:"type" bson:"type" validate:"required"`
+	Type FlagType `json:"type" bson:"type" validate:"min=0,max=6"`
+
+	// the value served when the feature is off or its environment doesn't set a value.
+	// For multivariate features it's the key of the variant served when the feature is off
+	//
+	// required: false
+	DefaultValue interface{} `json:"defaultValue" bson:"defaultValue"`
+
+	// the variants of a multivariate feature
+	//
+	// required: false
+	Variants []Variant `json:"variants" bson:"variants" validate:"dive"`
+
+	// the features which must be on for the feature to be on, possibly of other products
+	//
+	// required: false
+	Prerequisites []Prerequisite `json:"prerequisites" bson:"prerequisites" validate:"dive"`
+
+	// the configuration of the feature in each environment, keyed by the environment key
+	//
+	// required: false
+	Environments map[string]FeatureEnvironment `json:"environments" bson:"environments" validate:"dive"`
+}
+
+// FeatureEnvironment defines the structure for the configuration of a feature in an environment
+// swagger:model
+type FeatureEnvironment struct {
+	// shows whether the feature is on in the environment
+	//
+	// required: true
+	Enabled bool `json:"enabled" bson:"enabled"`
+
+	// the value served when the feature is on and none of the rules match.
+	// For multivariate features it's the key of a variant, empty to split the callers by the weights of the variants
+	//
+	// required: false
+	Value interface{} `json:"value" bson:"value"`
+
+	// the rules evaluated in order, the first matching rule decides the value
+	//
+	// required: false
+	Rules []Rule `json:"rules" bson:"rules" validate:"dive"`
+}
+
+// Rule defines the structure for a targeting rule of a feature
+// swagger:model
+type Rule struct {
+	// the name of the attribute of the evaluation context the rule checks
+	//
+	// required: true
+	Attribute string `json:"attribute" bson:"attribute" validate:"required"`
+
+	// the comparison made, one of equals, notEquals, in and notIn
+	//
+	// required: true
+	Operator string `json:"operator" bson:"operator" validate:"required,oneof=equals notEquals in notIn"`
+
+	// the values the attribute is compared with
+	//
+	// required: true
+	Values []string `json:"values" bson:"values" validate:"required,min=1"`
+
+	// the value served when the rule matches.
+	// For multivariate features it's the key of a variant, empty to split the callers by the weights of the variants
+	//
+	// required: false
+	Value interface{} `json:"value" bson:"value"`
+}
+
+// Prerequisite defines the structure for a feature another feature depends on
+// swagger:model
+type Prerequisite struct {
+	// the id of the product of the feature, empty for a feature of the same product
+	//
+	// required: false
+	ProductID *primitive.ObjectID `json:"productId,omitempty" bson:"productId,omitempty"`
+
+	// the code of the feature
+	//
+	// required: true
+	Code string `json:"code" bson:"code" validate:"required"`
+
+	// the key of the variant the feature must serve if it's a multivariate feature, empty for any variant
+	//
+	// required: false
+	Variant string `json:"variant,omitempty" bson:"variant,omitempty"`
+}
+
+// Variant defines the structure for a named value of a multivariate feature
+// swagger:model
+type Variant struct {
+	// the key of the variant
+	//
+	// required: true
+	Key string `json:"key" bson:"key" validate:"required"`
+
+	// the value of the variant
+	//
+	// required: false
+	Value interface{} `json:"value" bson:"value"`
+
+	// the share of the callers the variant is served to when the callers are split
+	//
+	// required: true
+	Weight int `json:"weight" bson:"weight" validate:"min=0"`
 }
 
 // FlagType is the enum that enumerates the type of feature flag
@@ -56,4 +165,12 @@ const (
 	Int
 	// Date indicates the flag is date bound flag
 	Date
+	// String indicates the flag is a string
+	String
+	// Float indicates the flag is a floating point number
+	Float
+	// JSON indicates the flag is an arbitrary JSON document
+	JSON
+	// Multivariate indicates the flag is one of the named variants of the feature
+	Multivariate
 )
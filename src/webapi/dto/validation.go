@@ -42,16 +42,18 @@ type Validation struct {
 // NewValidation creates a new Validation type
 func NewValidation() *Validation {
 	validate := validator.New()
+	validate.RegisterStructValidation(validateFeature, Feature{})
+	validate.RegisterValidation("webhookurl", validateWebhookURL)
 	return &Validation{validate}
 }
 
 // Validate validates the models
 func (v *Validation) Validate(i interface{}) ValidationErrors {
-	errs := v.validate.Struct(i).(validator.ValidationErrors)
-
-	if len(errs) == 0 {
+	err := v.validate.Struct(i)
+	if err == nil {
 		return nil
 	}
+	errs := err.(validator.ValidationErrors)
 
 	var returnErrs []ValidationError
 	for _, err := range errs {
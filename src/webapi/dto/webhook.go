@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"net"
+	"net/url"
+
+	"github.com/go-playground/validator"
+)
+
+// Subscription defines the structure for a new webhook subscription
+// swagger:model NewSubscription
+type Subscription struct {
+	// the url the events will be posted to
+	//
+	// required: true
+	URL string `json:"url" validate:"required,url,webhookurl"`
+
+	// the secret used to sign the deliveries with HMAC-SHA256
+	//
+	// required: true
+	Secret string `json:"secret" validate:"required,min=16"`
+
+	// the list of events the subscription is interested in
+	//
+	// required: true
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=product.created product.updated product.deleted product.restored order.placed"`
+}
+
+// metadataAddresses are the addresses of the instance metadata services of the cloud providers
+// which are not private, loopback or link-local addresses
+var metadataAddresses = []net.IP{
+	net.ParseIP("100.100.100.200"),
+}
+
+// PublicIP checks if the address can be the target of a webhook. Private, loopback, link-local, multicast,
+// unspecified and instance metadata addresses are not, so the subscriptions can't reach into the network of the API
+func PublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, metadata := range metadataAddresses {
+		if ip.Equal(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateWebhookURL checks that the url is an http or https url of a host which only resolves to public addresses
+func validateWebhookURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return false
+	}
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		ips, err = net.LookupIP(u.Hostname())
+		if err != nil || len(ips) == 0 {
+			return false
+		}
+	}
+	for _, ip := range ips {
+		if !PublicIP(ip) {
+			return false
+		}
+	}
+	return true
+}
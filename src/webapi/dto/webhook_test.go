@@ -0,0 +1,37 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+)
+
+func Test_ValidateSubscriptionURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://93.184.215.14/hooks", true},
+		{"http://93.184.215.14:8080/hooks", true},
+		{"ftp://93.184.215.14/hooks", false},
+		{"file:///etc/passwd", false},
+		{"http://127.0.0.1:5500/hooks", false},
+		{"http://localhost/hooks", false},
+		{"http://10.0.0.5/hooks", false},
+		{"http://192.168.1.10/hooks", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://100.100.100.200/latest/meta-data", false},
+		{"http://[::1]/hooks", false},
+		{"http://[fd00:ec2::254]/latest/meta-data", false},
+		{"http://[::ffff:127.0.0.1]/hooks", false},
+		{"http://0.0.0.0/hooks", false},
+	}
+	v := dto.NewValidation()
+	for _, test := range tests {
+		subscription := dto.Subscription{URL: test.url, Secret: "0123456789abcdef", Events: []string{"order.placed"}}
+		errs := v.Validate(&subscription)
+		if (len(errs) == 0) != test.valid {
+			t.Errorf("Error validating the url %s. Expected valid to be %t, got %v", test.url, test.valid, errs.Errors())
+		}
+	}
+}
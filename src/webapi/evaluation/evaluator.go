@@ -0,0 +1,255 @@
+package evaluation
+
+import (
+	"hash/fnv"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BucketAttribute is the attribute of the evaluation context the callers are split by between the variants of a multivariate feature
+const BucketAttribute = "key"
+
+// Reasons explaining how the value of a feature has been decided
+const (
+	// ReasonNotConfigured means the feature is not configured in the environment, so it's off
+	ReasonNotConfigured = "NOT_CONFIGURED"
+	// ReasonDisabled means the feature is turned off in the environment
+	ReasonDisabled = "DISABLED"
+	// ReasonPrerequisiteFailed means one of the prerequisites of the feature is off or serves another variant
+	ReasonPrerequisiteFailed = "PREREQUISITE_FAILED"
+	// ReasonRuleMatch means one of the targeting rules matched the attributes
+	ReasonRuleMatch = "RULE_MATCH"
+	// ReasonDefault means the feature is on and none of the rules matched
+	ReasonDefault = "DEFAULT"
+	// ReasonSplit means the variant has been chosen by the weights of the variants of a multivariate feature
+	ReasonSplit = "SPLIT"
+)
+
+// Context defines the environment and the attributes of the caller the features are evaluated for,
+// together with the product of the features and where the features of the prerequisites are found
+type Context struct {
+	Environment string
+	Attributes  map[string]string
+	Product     primitive.ObjectID
+	Features    FeatureSource
+}
+
+// Result defines the structure for the outcome of evaluating a feature
+// swagger:model EvaluationResult
+type Result struct {
+	// the code of the feature
+	//
+	// required: true
+	Code string `json:"code"`
+
+	// shows whether the feature is on for the caller
+	//
+	// required: true
+	Enabled bool `json:"enabled"`
+
+	// the type of the value, one of bool, int, date, string, float, json and multivariate
+	//
+	// required: true
+	Type string `json:"type"`
+
+	// the value of the feature for the caller in its type, the default value of the feature if it's off
+	//
+	// required: false
+	Value interface{} `json:"value"`
+
+	// the key of the variant served to the caller, only set for multivariate features
+	//
+	// required: false
+	Variant string `json:"variant,omitempty"`
+
+	// the reason of the outcome
+	//
+	// required: true
+	Reason string `json:"reason"`
+
+	// the index of the matched rule, only set when the reason is RULE_MATCH
+	//
+	// required: false
+	RuleIndex *int `json:"ruleIndex,omitempty"`
+
+	// the node id of the prerequisite which is not met, only set when the reason is PREREQUISITE_FAILED
+	//
+	// required: false
+	Prerequisite string `json:"prerequisite,omitempty"`
+}
+
+// Evaluate returns the outcome of every feature of the product for the context, keyed by the feature code
+func Evaluate(product data.Product, ctx Context) map[string]Result {
+	ctx.Product = product.ID
+	ctx.Features = WithProduct(product, ctx.Features)
+	results := map[string]Result{}
+	for _, feature := range product.Features {
+		results[feature.Code] = EvaluateFeature(feature, ctx)
+	}
+	return results
+}
+
+// EvaluateFeature returns the outcome of the feature of the product in the context
+func EvaluateFeature(feature data.Feature, ctx Context) Result {
+	return evaluateFeature(feature, ctx, map[string]bool{NodeID(ctx.Product, feature.Code): true})
+}
+
+// evaluateFeature returns the outcome of the feature, evaluating its prerequisites recursively.
+// A prerequisite which is being evaluated already is a cycle and is never met
+func evaluateFeature(feature data.Feature, ctx Context, evaluating map[string]bool) Result {
+	result := Result{Code: feature.Code, Type: feature.Type.Name()}
+	env, ok := feature.Environments[ctx.Environment]
+	if !ok {
+		result.Reason = ReasonNotConfigured
+		serve(&result, feature, feature.DefaultValue, ctx)
+		return result
+	}
+	if !env.Enabled {
+		result.Reason = ReasonDisabled
+		serve(&result, feature, feature.DefaultValue, ctx)
+		return result
+	}
+	if failed := failedPrerequisite(feature, ctx, evaluating); failed != "" {
+		result.Reason = ReasonPrerequisiteFailed
+		result.Prerequisite = failed
+		serve(&result, feature, feature.DefaultValue, ctx)
+		return result
+	}
+	result.Enabled = true
+	for i, rule := range env.Rules {
+		if Matches(rule, ctx.Attributes) {
+			index := i
+			result.Reason = ReasonRuleMatch
+			result.RuleIndex = &index
+			serve(&result, feature, rule.Value, ctx)
+			return result
+		}
+	}
+	result.Reason = ReasonDefault
+	serve(&result, feature, env.Value, ctx)
+	return result
+}
+
+// failedPrerequisite returns the node id of the first prerequisite of the feature which is not met, empty if all are met
+func failedPrerequisite(feature data.Feature, ctx Context, evaluating map[string]bool) string {
+	for _, p := range feature.Prerequisites {
+		productID := prerequisiteProduct(ctx.Product, p)
+		id := NodeID(productID, p.Code)
+		if evaluating[id] || ctx.Features == nil {
+			return id
+		}
+		prerequisite, ok := ctx.Features.GetFeature(productID, p.Code)
+		if !ok {
+			return id
+		}
+		evaluating[id] = true
+		pctx := ctx
+		pctx.Product = productID
+		result := evaluateFeature(*prerequisite, pctx, evaluating)
+		delete(evaluating, id)
+		if !result.Enabled || (p.Variant != "" && result.Variant != p.Variant) {
+			return id
+		}
+	}
+	return ""
+}
+
+// serve sets the value of the result to the given value in the type of the feature, falling back to the default value of the feature.
+// For multivariate features the value is the key of the variant, callers are split by the weights of the variants if it's empty
+// and the feature is on
+func serve(result *Result, feature data.Feature, value interface{}, ctx Context) {
+	if feature.Type != data.Multivariate {
+		if value == nil {
+			value = feature.DefaultValue
+		}
+		result.Value = Typed(feature.Type, value)
+		return
+	}
+	key, _ := value.(string)
+	if !result.Enabled {
+		key, _ = feature.DefaultValue.(string)
+	} else if key == "" {
+		key = split(feature, ctx.Attributes[BucketAttribute])
+		result.Reason = ReasonSplit
+	}
+	for _, v := range feature.Variants {
+		if v.Key == key {
+			result.Variant = v.Key
+			result.Value = v.Value
+			return
+		}
+	}
+}
+
+// split returns the key of the variant the bucket falls into by the weights of the variants.
+// The same bucket always gets the same variant as long as the variants don't change
+func split(feature data.Feature, bucket string) string {
+	total := 0
+	for _, v := range feature.Variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(feature.Code + ":" + bucket))
+	point := int(h.Sum32() % uint32(total))
+	for _, v := range feature.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+		if point < v.Weight {
+			return v.Key
+		}
+		point -= v.Weight
+	}
+	return ""
+}
+
+// Matches checks if the attributes satisfy the rule. A missing attribute only satisfies the negative operators
+func Matches(rule data.Rule, attributes map[string]string) bool {
+	value, ok := attributes[rule.Attribute]
+	switch rule.Operator {
+	case "equals":
+		return ok && len(rule.Values) > 0 && value == rule.Values[0]
+	case "notEquals":
+		return !ok || len(rule.Values) == 0 || value != rule.Values[0]
+	case "in":
+		return ok && contains(rule.Values, value)
+	case "notIn":
+		return !ok || !contains(rule.Values, value)
+	}
+	return false
+}
+
+// Promote copies the configuration of every feature of the product in the source environment to the target environment.
+// Features not configured in the source environment are removed from the target environment
+func Promote(product *data.Product, source, target string) {
+	for i := range product.Features {
+		feature := &product.Features[i]
+		env, ok := feature.Environments[source]
+		if !ok {
+			delete(feature.Environments, target)
+			continue
+		}
+		if feature.Environments == nil {
+			feature.Environments = map[string]data.FeatureEnvironment{}
+		}
+		rules := make([]data.Rule, len(env.Rules))
+		copy(rules, env.Rules)
+		env.Rules = rules
+		feature.Environments[target] = env
+	}
+}
+
+func contains(a []string, x string) bool {
+	for _, n := range a {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package evaluation_test
+
+import (
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+)
+
+func Test_EvaluateFeature(t *testing.T) {
+	feature := createFeature()
+	result := evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "dev"})
+	if result.Enabled || result.Reason != evaluation.ReasonNotConfigured {
+		t.Errorf("Error evaluating in an unconfigured environment. Expected %s, got %s", evaluation.ReasonNotConfigured, result.Reason)
+	}
+	result = evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "prod"})
+	if result.Enabled || result.Reason != evaluation.ReasonDisabled {
+		t.Errorf("Error evaluating in a disabled environment. Expected %s, got %s", evaluation.ReasonDisabled, result.Reason)
+	}
+	result = evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "staging", Attributes: map[string]string{"country": "TR"}})
+	if !result.Enabled || result.Reason != evaluation.ReasonRuleMatch || result.Value != "beta" {
+		t.Errorf("Error evaluating a matching rule. Expected %s with value beta, got %s with value %v", evaluation.ReasonRuleMatch, result.Reason, result.Value)
+	}
+	result = evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "staging", Attributes: map[string]string{"country": "NL"}})
+	if !result.Enabled || result.Reason != evaluation.ReasonDefault || result.Value != "stable" {
+		t.Errorf("Error evaluating without a matching rule. Expected %s with value stable, got %s with value %v", evaluation.ReasonDefault, result.Reason, result.Value)
+	}
+}
+
+func Test_EvaluateTypedFeature(t *testing.T) {
+	feature := data.Feature{
+		Code:         "limit",
+		Type:         data.Int,
+		DefaultValue: float64(10),
+		Environments: map[string]data.FeatureEnvironment{"prod": {Enabled: true, Value: float64(25)}},
+	}
+	result := evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "prod"})
+	if result.Type != "int" || result.Value != int64(25) {
+		t.Errorf("Error evaluating an int feature. Expected int 25, got %s %v (%T)", result.Type, result.Value, result.Value)
+	}
+	result = evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "dev"})
+	if result.Enabled || result.Value != int64(10) {
+		t.Errorf("Error evaluating an unconfigured int feature. Expected the default value 10, got %v", result.Value)
+	}
+}
+
+func Test_EvaluateMultivariateFeature(t *testing.T) {
+	feature := data.Feature{
+		Code:         "theme",
+		Type:         data.Multivariate,
+		DefaultValue: "light",
+		Variants: []data.Variant{
+			{Key: "light", Value: "#ffffff", Weight: 50},
+			{Key: "dark", Value: "#000000", Weight: 50},
+		},
+		Environments: map[string]data.FeatureEnvironment{
+			"prod": {
+				Enabled: true,
+				Rules:   []data.Rule{{Attribute: "country", Operator: "equals", Values: []string{"TR"}, Value: "dark"}},
+			},
+		},
+	}
+	result := evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "prod", Attributes: map[string]string{"country": "TR"}})
+	if result.Variant != "dark" || result.Value != "#000000" {
+		t.Errorf("Error evaluating a matching rule of a multivariate feature. Expected variant dark, got %s", result.Variant)
+	}
+	served := map[string]bool{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		ctx := evaluation.Context{Environment: "prod", Attributes: map[string]string{evaluation.BucketAttribute: key}}
+		first, second := evaluation.EvaluateFeature(feature, ctx), evaluation.EvaluateFeature(feature, ctx)
+		if first.Reason != evaluation.ReasonSplit || first.Variant != second.Variant {
+			t.Errorf("Error splitting callers. Expected a stable variant with reason %s, got %s and %s with reason %s", evaluation.ReasonSplit, first.Variant, second.Variant, first.Reason)
+		}
+		served[first.Variant] = true
+	}
+	if !served["light"] || !served["dark"] {
+		t.Errorf("Error splitting callers. Expected both variants to be served, got %v", served)
+	}
+	result = evaluation.EvaluateFeature(feature, evaluation.Context{Environment: "dev"})
+	if result.Variant != "light" {
+		t.Errorf("Error evaluating an unconfigured multivariate feature. Expected the default variant light, got %s", result.Variant)
+	}
+}
+
+func Test_Matches(t *testing.T) {
+	in := data.Rule{Attribute: "country", Operator: "in", Values: []string{"TR", "DE"}}
+	notIn := data.Rule{Attribute: "country", Operator: "notIn", Values: []string{"TR", "DE"}}
+	if !evaluation.Matches(in, map[string]string{"country": "DE"}) || evaluation.Matches(in, map[string]string{}) {
+		t.Errorf("Error matching the in operator. Expected to match only a listed value")
+	}
+	if evaluation.Matches(notIn, map[string]string{"country": "DE"}) || !evaluation.Matches(notIn, map[string]string{}) {
+		t.Errorf("Error matching the notIn operator. Expected to match an unlisted or missing value")
+	}
+}
+
+func Test_Promote(t *testing.T) {
+	product := data.Product{Features: []data.Feature{createFeature(), {Code: "unconfigured"}}}
+	evaluation.Promote(&product, "staging", "prod")
+	prod := product.Features[0].Environments["prod"]
+	if !prod.Enabled || prod.Value != "stable" || len(prod.Rules) != 1 {
+		t.Errorf("Error promoting a configured feature. Expected the staging configuration in prod, got %v", prod)
+	}
+	if _, ok := product.Features[1].Environments["prod"]; ok {
+		t.Errorf("Error promoting an unconfigured feature. Expected no configuration in prod")
+	}
+}
+
+func createFeature() data.Feature {
+	return data.Feature{
+		Code: "checkout",
+		Environments: map[string]data.FeatureEnvironment{
+			"staging": {
+				Enabled: true,
+				Value:   "stable",
+				Rules:   []data.Rule{{Attribute: "country", Operator: "equals", Values: []string{"TR"}, Value: "beta"}},
+			},
+			"prod": {Enabled: false, Value: "stable"},
+		},
+	}
+}
@@ -0,0 +1,224 @@
+package evaluation
+
+import (
+	"sort"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeatureSource finds the features the prerequisites refer to
+type FeatureSource interface {
+	GetFeature(productID primitive.ObjectID, code string) (*data.Feature, bool)
+}
+
+// Node defines the structure for a feature in the dependency graph
+// swagger:model DependencyNode
+type Node struct {
+	// the id of the node, the product id and the feature code separated by a slash
+	//
+	// required: true
+	ID string `json:"id"`
+
+	// the id of the product of the feature
+	//
+	// required: true
+	ProductID primitive.ObjectID `json:"productId"`
+
+	// the code of the feature
+	//
+	// required: true
+	Code string `json:"code"`
+
+	// the user friendly name of the feature, empty if it doesn't exist
+	//
+	// required: false
+	Name string `json:"name"`
+
+	// shows whether the feature a prerequisite refers to doesn't exist
+	//
+	// required: false
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Edge defines the structure for a dependency between two features in the dependency graph
+// swagger:model DependencyEdge
+type Edge struct {
+	// the id of the node of the dependent feature
+	//
+	// required: true
+	From string `json:"from"`
+
+	// the id of the node of the prerequisite feature
+	//
+	// required: true
+	To string `json:"to"`
+
+	// the variant the prerequisite feature must serve, empty for any variant
+	//
+	// required: false
+	Variant string `json:"variant,omitempty"`
+}
+
+// Graph defines the structure for the dependency graph of the features of a product
+// swagger:model DependencyGraph
+type Graph struct {
+	// the features of the product and every feature they depend on, directly or not
+	//
+	// required: true
+	Nodes []Node `json:"nodes"`
+
+	// the dependencies between the features
+	//
+	// required: true
+	Edges []Edge `json:"edges"`
+}
+
+// NodeID returns the id of the node of the feature in the dependency graph
+func NodeID(productID primitive.ObjectID, code string) string {
+	return productID.Hex() + "/" + code
+}
+
+// WithProduct returns a FeatureSource which finds the features of the given product in it and the others in the source.
+// It's used to check a product before it's stored
+func WithProduct(product data.Product, source FeatureSource) FeatureSource {
+	return productSource{product, source}
+}
+
+type productSource struct {
+	product data.Product
+	source  FeatureSource
+}
+
+func (s productSource) GetFeature(productID primitive.ObjectID, code string) (*data.Feature, bool) {
+	if productID == s.product.ID {
+		for i := range s.product.Features {
+			if s.product.Features[i].Code == code {
+				return &s.product.Features[i], true
+			}
+		}
+		return nil, false
+	}
+	if s.source == nil {
+		return nil, false
+	}
+	return s.source.GetFeature(productID, code)
+}
+
+// prerequisiteProduct returns the id of the product of the prerequisite of a feature of the given product
+func prerequisiteProduct(productID primitive.ObjectID, p data.Prerequisite) primitive.ObjectID {
+	if p.ProductID != nil {
+		return *p.ProductID
+	}
+	return productID
+}
+
+// DependencyGraph returns the features of the product and every feature they depend on, directly or not, with their dependencies
+func DependencyGraph(product data.Product, source FeatureSource) Graph {
+	source = WithProduct(product, source)
+	graph := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	seen := map[string]bool{}
+	type item struct {
+		productID primitive.ObjectID
+		code      string
+	}
+	queue := []item{}
+	for _, f := range product.Features {
+		queue = append(queue, item{product.ID, f.Code})
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		id := NodeID(current.productID, current.code)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		node := Node{ID: id, ProductID: current.productID, Code: current.code}
+		feature, ok := source.GetFeature(current.productID, current.code)
+		if !ok {
+			node.Missing = true
+			graph.Nodes = append(graph.Nodes, node)
+			continue
+		}
+		node.Name = feature.Name
+		graph.Nodes = append(graph.Nodes, node)
+		for _, p := range feature.Prerequisites {
+			productID := prerequisiteProduct(current.productID, p)
+			graph.Edges = append(graph.Edges, Edge{From: id, To: NodeID(productID, p.Code), Variant: p.Variant})
+			queue = append(queue, item{productID, p.Code})
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	return graph
+}
+
+// FindCycle returns the ids of the nodes of a cycle in the dependency graph of the product, starting and ending with the same node.
+// Returns nil if there's no cycle
+func FindCycle(product data.Product, source FeatureSource) []string {
+	graph := DependencyGraph(product, source)
+	edges := map[string][]string{}
+	for _, e := range graph.Edges {
+		edges[e.From] = append(edges[e.From], e.To)
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	path := []string{}
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, next := range edges[id] {
+			switch state[next] {
+			case visiting:
+				for i, p := range path {
+					if p == next {
+						return append(append([]string{}, path[i:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, n := range graph.Nodes {
+		if state[n.ID] == unvisited {
+			if cycle := visit(n.ID); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// MissingPrerequisites returns the ids of the nodes of the features the prerequisites of the features of the product refer to
+// which don't exist. Only the direct prerequisites are checked, the missing prerequisites of the other products are theirs to fix
+func MissingPrerequisites(product data.Product, source FeatureSource) []string {
+	source = WithProduct(product, source)
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, f := range product.Features {
+		for _, p := range f.Prerequisites {
+			productID := prerequisiteProduct(product.ID, p)
+			id := NodeID(productID, p.Code)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if _, ok := source.GetFeature(productID, p.Code); !ok {
+				missing = append(missing, id)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
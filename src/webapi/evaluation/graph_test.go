@@ -0,0 +1,91 @@
+package evaluation_test
+
+import (
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeSource map[string]data.Feature
+
+func (s fakeSource) GetFeature(productID primitive.ObjectID, code string) (*data.Feature, bool) {
+	feature, ok := s[evaluation.NodeID(productID, code)]
+	return &feature, ok
+}
+
+func Test_EvaluatePrerequisites(t *testing.T) {
+	billing := primitive.NewObjectID()
+	source := fakeSource{
+		evaluation.NodeID(billing, "payments"): {Code: "payments", Environments: map[string]data.FeatureEnvironment{"prod": {Enabled: true}}},
+	}
+	product := data.Product{
+		ID: primitive.NewObjectID(),
+		Features: []data.Feature{
+			{Code: "checkout", Environments: map[string]data.FeatureEnvironment{"prod": {Enabled: true}, "dev": {Enabled: false}}},
+			{
+				Code:          "express",
+				Prerequisites: []data.Prerequisite{{Code: "checkout"}, {ProductID: &billing, Code: "payments"}},
+				Environments:  map[string]data.FeatureEnvironment{"prod": {Enabled: true}, "dev": {Enabled: true}},
+			},
+		},
+	}
+	results := evaluation.Evaluate(product, evaluation.Context{Environment: "prod", Features: source})
+	if !results["express"].Enabled {
+		t.Errorf("Error evaluating met prerequisites. Expected express to be on, got %s", results["express"].Reason)
+	}
+	results = evaluation.Evaluate(product, evaluation.Context{Environment: "dev", Features: source})
+	if results["express"].Enabled || results["express"].Reason != evaluation.ReasonPrerequisiteFailed || results["express"].Prerequisite != evaluation.NodeID(product.ID, "checkout") {
+		t.Errorf("Error evaluating a failed prerequisite. Expected %s on checkout, got %s on %s", evaluation.ReasonPrerequisiteFailed, results["express"].Reason, results["express"].Prerequisite)
+	}
+}
+
+func Test_FindCycle(t *testing.T) {
+	prod := map[string]data.FeatureEnvironment{"prod": {Enabled: true}}
+	product := data.Product{
+		ID: primitive.NewObjectID(),
+		Features: []data.Feature{
+			{Code: "a", Prerequisites: []data.Prerequisite{{Code: "b"}}, Environments: prod},
+			{Code: "b", Prerequisites: []data.Prerequisite{{Code: "c"}}, Environments: prod},
+			{Code: "c", Environments: prod},
+		},
+	}
+	if cycle := evaluation.FindCycle(product, nil); cycle != nil {
+		t.Errorf("Error finding a cycle in an acyclic graph. Expected none, got %v", cycle)
+	}
+	product.Features[2].Prerequisites = []data.Prerequisite{{Code: "a"}}
+	cycle := evaluation.FindCycle(product, nil)
+	if len(cycle) != 4 || cycle[0] != cycle[3] {
+		t.Errorf("Error finding a cycle. Expected a -> b -> c -> a, got %v", cycle)
+	}
+	results := evaluation.Evaluate(product, evaluation.Context{Environment: "prod"})
+	if results["a"].Enabled || results["a"].Reason != evaluation.ReasonPrerequisiteFailed {
+		t.Errorf("Error evaluating a cyclic prerequisite. Expected a to be off with %s, got %s", evaluation.ReasonPrerequisiteFailed, results["a"].Reason)
+	}
+}
+
+func Test_DependencyGraph(t *testing.T) {
+	other := primitive.NewObjectID()
+	product := data.Product{
+		ID: primitive.NewObjectID(),
+		Features: []data.Feature{
+			{Code: "a", Prerequisites: []data.Prerequisite{{Code: "b"}, {ProductID: &other, Code: "x"}}},
+			{Code: "b"},
+		},
+	}
+	graph := evaluation.DependencyGraph(product, fakeSource{})
+	if len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+		t.Errorf("Error building the dependency graph. Expected 3 nodes and 2 edges, got %d and %d", len(graph.Nodes), len(graph.Edges))
+	}
+	missing := evaluation.MissingPrerequisites(product, fakeSource{})
+	if len(missing) != 1 || missing[0] != evaluation.NodeID(other, "x") {
+		t.Errorf("Error finding missing prerequisites. Expected %s, got %v", evaluation.NodeID(other, "x"), missing)
+	}
+	// the missing prerequisites of the other products are not the product's
+	third := primitive.NewObjectID()
+	source := fakeSource{evaluation.NodeID(other, "x"): {Code: "x", Prerequisites: []data.Prerequisite{{ProductID: &third, Code: "y"}}}}
+	if missing := evaluation.MissingPrerequisites(product, source); len(missing) != 0 {
+		t.Errorf("Error finding missing prerequisites. Expected only the direct ones, got %v", missing)
+	}
+}
@@ -0,0 +1,45 @@
+package evaluation
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+)
+
+// Typed returns the value converted to the Go type of the flag type. Values which can not be converted are returned as they are
+func Typed(t data.FlagType, value interface{}) interface{} {
+	switch t {
+	case data.Bool:
+		if s, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	case data.Int:
+		switch n := value.(type) {
+		case float64:
+			return int64(n)
+		case int32:
+			return int64(n)
+		case int:
+			return int64(n)
+		}
+	case data.Float:
+		switch n := value.(type) {
+		case int32:
+			return float64(n)
+		case int64:
+			return float64(n)
+		case int:
+			return float64(n)
+		}
+	case data.Date:
+		if s, ok := value.(string); ok {
+			if d, err := time.Parse(time.RFC3339, s); err == nil {
+				return d
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+)
+
+// Route is a route registered on the API router
+type Route struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods,omitempty"`
+}
+
+// started is the time the server started, used for its uptime
+var started = time.Now()
+
+func init() {
+	// expvar serves the command line and the memory statistics by itself, the build info and the runtime stats are added next to them
+	expvar.Publish("build", expvar.Func(buildInfo))
+	expvar.Publish("runtime", expvar.Func(runtimeStats))
+}
+
+// NewRouter returns the router of the admin port, which serves the profiles of the server under /debug/pprof/,
+// its build info, runtime stats and memory statistics on /debug/vars, the effective configuration returned by settings
+// with the secrets redacted on /debug/config, and the routes registered on the API router on /debug/routes
+func NewRouter(api *mux.Router, settings func() map[string]interface{}) *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	// the index serves the named profiles such as heap and goroutine under it
+	r.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+	r.Handle("/debug/vars", expvar.Handler())
+	r.HandleFunc("/debug/config", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		data.ToJSON(Redact(settings()), rw)
+	})
+	r.HandleFunc("/debug/routes", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		data.ToJSON(Routes(api), rw)
+	})
+	return r
+}
+
+// Routes returns the routes registered on the router with their path templates and methods
+func Routes(router *mux.Router) []Route {
+	routes := []Route{}
+	router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			// the routes of the subrouters only match the methods, their routes are listed on their own
+			return nil
+		}
+		// the methods of the subrouters are copied to their routes
+		methods, _ := route.GetMethods()
+		routes = append(routes, Route{Path: path, Methods: methods})
+		return nil
+	})
+	return routes
+}
+
+// IsLoopback checks if the bind address only accepts connections from the local host, such as localhost:6060 or 127.0.0.1:6060.
+// An address without a host such as :6060 listens on every interface
+func IsLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// buildInfo returns the version of Go and the module the server is built with, and the commit it's built from if known
+func buildInfo() interface{} {
+	build := map[string]string{"goVersion": runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	build["path"] = info.Main.Path
+	build["version"] = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			build["revision"] = s.Value
+		case "vcs.time":
+			build["time"] = s.Value
+		case "vcs.modified":
+			build["modified"] = s.Value
+		}
+	}
+	return build
+}
+
+// runtimeStats returns the uptime of the server and the goroutines and processors it uses
+func runtimeStats() interface{} {
+	return map[string]interface{}{
+		"uptimeSeconds": int64(time.Since(started).Seconds()),
+		"goroutines":    runtime.NumGoroutine(),
+		"cpus":          runtime.NumCPU(),
+		"maxProcs":      runtime.GOMAXPROCS(0),
+	}
+}
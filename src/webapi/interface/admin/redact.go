@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces the values of the secret settings
+const Redacted = "[REDACTED]"
+
+// secretNames are the parts of the names of the settings which hold secrets
+var secretNames = []string{"secret", "password", "token", "credential", "privatekey"}
+
+// Redact returns a copy of the settings with the values of the secret settings replaced, and the passwords in the URLs such as
+// the connection string of the database masked. Nested settings, as read from the config file, are redacted as well
+func Redact(settings map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(settings))
+	for name, value := range settings {
+		redacted[name] = redact(name, value)
+	}
+	return redacted
+}
+
+func redact(name string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Redact(v)
+	case string:
+		if v != "" && isSecret(name) {
+			return Redacted
+		}
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				return u.Redacted()
+			}
+		}
+		return v
+	default:
+		if isSecret(name) {
+			return Redacted
+		}
+		return v
+	}
+}
+
+// isSecret checks if the name of the setting, such as jwtsecret, says it holds a secret
+func isSecret(name string) bool {
+	name = strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(name))
+	for _, s := range secretNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store represents an interface for the outer layers to implement the persistence of the evaluation counts
+type Store interface {
+	AddEvaluationCounts(ctx context.Context, tenant data.Tenant, counts []data.EvaluationCount) error
+}
+
+// Recorder counts the evaluations of the features in memory and flushes the counts to the store periodically
+type Recorder struct {
+	sync.Mutex
+	store  Store
+	counts map[key]*data.EvaluationCount
+}
+
+// key identifies the counts of a value of a feature of a tenant in a day
+type key struct {
+	tenant    data.Tenant
+	productID primitive.ObjectID
+	code      string
+	enabled   bool
+	variant   string
+	value     string
+	day       time.Time
+}
+
+// countKey returns the key of the count of the tenant
+func countKey(tenant data.Tenant, c data.EvaluationCount) key {
+	return key{tenant, c.ProductID, c.Code, c.Enabled, c.Variant, c.Value, c.Day}
+}
+
+// NewRecorder returns a new Recorder flushing the counts to the given store
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{store: store, counts: map[key]*data.EvaluationCount{}}
+}
+
+// Record counts the outcomes of evaluating the features of the product for the tenant
+func (rec *Recorder) Record(tenant data.Tenant, productID primitive.ObjectID, results map[string]evaluation.Result) {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	rec.Lock()
+	defer rec.Unlock()
+	for _, result := range results {
+		value, _ := json.Marshal(result.Value)
+		c := data.EvaluationCount{ProductID: productID, Code: result.Code, Enabled: result.Enabled, Variant: result.Variant, Value: string(value), Day: day}
+		k := countKey(tenant, c)
+		count, ok := rec.counts[k]
+		if !ok {
+			count = &c
+			rec.counts[k] = count
+		}
+		count.Count++
+		count.LastEvaluated = now
+		// the products are not labelled, so the number of series doesn't grow with every product created and deleted
+		middleware.EvaluationCounterVec.WithLabelValues(result.Code, result.Variant, strconv.FormatBool(result.Enabled)).Inc()
+	}
+}
+
+// Flush writes the counts recorded since the last flush to the store. Counts which can not be written are kept for the next flush,
+// the ones written before a partial failure are not
+func (rec *Recorder) Flush(ctx context.Context) {
+	rec.Lock()
+	counts := rec.counts
+	rec.counts = map[key]*data.EvaluationCount{}
+	rec.Unlock()
+
+	byTenant := map[data.Tenant][]data.EvaluationCount{}
+	for k, c := range counts {
+		byTenant[k.tenant] = append(byTenant[k.tenant], *c)
+	}
+	for tenant, tenantCounts := range byTenant {
+		err := rec.store.AddEvaluationCounts(ctx, tenant, tenantCounts)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error flushing the evaluation counts of tenant '%s'", tenant.ID)
+			var unwritten *data.UnwrittenCountsError
+			if errors.As(err, &unwritten) {
+				tenantCounts = unwritten.Counts
+			}
+			rec.restore(tenant, tenantCounts)
+		}
+	}
+}
+
+// restore adds the counts of the tenant back to the counts recorded meanwhile
+func (rec *Recorder) restore(tenant data.Tenant, counts []data.EvaluationCount) {
+	rec.Lock()
+	defer rec.Unlock()
+	for _, c := range counts {
+		k := countKey(tenant, c)
+		if current, ok := rec.counts[k]; ok {
+			current.Count += c.Count
+			continue
+		}
+		c := c
+		rec.counts[k] = &c
+	}
+}
+
+// Start flushes the counts every interval in the background until the context is done
+func (rec *Recorder) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rec.Flush(ctx)
+			}
+		}
+	}()
+}
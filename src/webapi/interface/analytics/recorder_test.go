@@ -0,0 +1,85 @@
+package analytics_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"github.com/serdarkalayci/goboiler/webapi/interface/analytics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeStore struct {
+	counts []data.EvaluationCount
+	err    error
+}
+
+func (s *fakeStore) AddEvaluationCounts(ctx context.Context, tenant data.Tenant, counts []data.EvaluationCount) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.counts = append(s.counts, counts...)
+	return nil
+}
+
+func Test_RecordAndFlush(t *testing.T) {
+	store := &fakeStore{err: fmt.Errorf("database is down")}
+	recorder := analytics.NewRecorder(store)
+	productID := primitive.NewObjectID()
+	results := map[string]evaluation.Result{"checkout": {Code: "checkout", Enabled: true, Value: true}}
+	recorder.Record(data.Tenant{}, productID, results)
+	recorder.Flush(context.Background())
+	recorder.Record(data.Tenant{}, productID, results)
+	store.err = nil
+	recorder.Flush(context.Background())
+	if len(store.counts) != 1 || store.counts[0].Count != 2 || store.counts[0].Value != "true" {
+		t.Errorf("Error flushing the counts. Expected a single count of 2 kept over the failed flush, got %v", store.counts)
+	}
+	recorder.Flush(context.Background())
+	if len(store.counts) != 1 {
+		t.Errorf("Error flushing the counts. Expected nothing to be flushed again, got %v", store.counts)
+	}
+}
+
+func Test_FlushPartialFailure(t *testing.T) {
+	productID := primitive.NewObjectID()
+	store := &fakeStore{}
+	recorder := analytics.NewRecorder(store)
+	recorder.Record(data.Tenant{}, productID, map[string]evaluation.Result{
+		"checkout": {Code: "checkout", Enabled: true, Value: true},
+		"banner":   {Code: "banner", Enabled: false, Value: false},
+	})
+	// the store writes the checkout count and fails to write the banner count
+	store.err = &data.UnwrittenCountsError{Counts: []data.EvaluationCount{{ProductID: productID, Code: "banner", Value: "false", Count: 1, Day: time.Now().UTC().Truncate(24 * time.Hour)}}, Err: fmt.Errorf("write error")}
+	recorder.Flush(context.Background())
+	store.err = nil
+	recorder.Flush(context.Background())
+	if len(store.counts) != 1 || store.counts[0].Code != "banner" || store.counts[0].Count != 1 {
+		t.Errorf("Error flushing after a partial failure. Expected only the banner count to be written again, got %v", store.counts)
+	}
+}
+
+func Test_StaleFeatures(t *testing.T) {
+	now := time.Now().UTC()
+	product := data.Product{Features: []data.Feature{{Code: "unused"}, {Code: "constant"}, {Code: "active"}}}
+	counts := []data.EvaluationCount{
+		{Code: "unused", Value: "true", Count: 5, LastEvaluated: now.AddDate(0, 0, -40)},
+		{Code: "constant", Value: "true", Count: 9, LastEvaluated: now},
+		{Code: "constant", Value: "false", Count: 2, LastEvaluated: now.AddDate(0, 0, -45)},
+		{Code: "active", Value: "true", Count: 3, LastEvaluated: now},
+		{Code: "active", Value: "false", Count: 4, LastEvaluated: now},
+	}
+	stale := analytics.StaleFeatures(product, counts, now.AddDate(0, 0, -30))
+	if len(stale) != 2 {
+		t.Fatalf("Error finding stale features. Expected 2, got %d", len(stale))
+	}
+	if stale[0].Code != "unused" || stale[0].Reason != analytics.ReasonNotEvaluated {
+		t.Errorf("Error finding an unused feature. Expected unused with %s, got %s with %s", analytics.ReasonNotEvaluated, stale[0].Code, stale[0].Reason)
+	}
+	if stale[1].Code != "constant" || stale[1].Reason != analytics.ReasonSingleValue || stale[1].Evaluations != 9 {
+		t.Errorf("Error finding a constant feature. Expected constant with %s, got %s with %s", analytics.ReasonSingleValue, stale[1].Code, stale[1].Reason)
+	}
+}
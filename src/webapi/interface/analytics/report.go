@@ -0,0 +1,87 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+)
+
+// Reasons a feature is reported as stale
+const (
+	// ReasonNotEvaluated means the feature has not been evaluated in the reported period
+	ReasonNotEvaluated = "NOT_EVALUATED"
+	// ReasonSingleValue means every evaluation of the feature returned the same value
+	ReasonSingleValue = "SINGLE_VALUE"
+)
+
+// StaleFeature defines the structure for a feature which is likely not needed anymore
+// swagger:model
+type StaleFeature struct {
+	// the code of the feature
+	//
+	// required: true
+	Code string `json:"code"`
+
+	// the user friendly name of the feature
+	//
+	// required: true
+	Name string `json:"name"`
+
+	// the reason the feature is stale, one of NOT_EVALUATED and SINGLE_VALUE
+	//
+	// required: true
+	Reason string `json:"reason"`
+
+	// the date of the last evaluation, empty if it's not been evaluated in the reported period
+	//
+	// required: false
+	LastEvaluated *time.Time `json:"lastEvaluated,omitempty"`
+
+	// the number of evaluations in the reported period
+	//
+	// required: true
+	Evaluations int64 `json:"evaluations"`
+
+	// the only value returned in the reported period, serialized as JSON, only set when the reason is SINGLE_VALUE
+	//
+	// required: false
+	Value string `json:"value,omitempty"`
+}
+
+// StaleFeatures returns the features of the product which have not been evaluated since the given date
+// or which have always returned the same value since then. The counts are kept per day, so the counts of the day
+// of the given date are included in full
+func StaleFeatures(product data.Product, counts []data.EvaluationCount, since time.Time) []StaleFeature {
+	byCode := map[string][]data.EvaluationCount{}
+	for _, c := range counts {
+		byCode[c.Code] = append(byCode[c.Code], c)
+	}
+	stale := []StaleFeature{}
+	for _, f := range product.Features {
+		feature := StaleFeature{Code: f.Code, Name: f.Name}
+		values := map[string]bool{}
+		for _, c := range byCode[f.Code] {
+			if feature.LastEvaluated == nil || c.LastEvaluated.After(*feature.LastEvaluated) {
+				last := c.LastEvaluated
+				feature.LastEvaluated = &last
+			}
+			if c.LastEvaluated.Before(since) {
+				continue
+			}
+			feature.Evaluations += c.Count
+			values[c.Value] = true
+			feature.Value = c.Value
+		}
+		switch {
+		case feature.LastEvaluated == nil || feature.LastEvaluated.Before(since):
+			feature.Reason = ReasonNotEvaluated
+			feature.Value = ""
+		case len(values) == 1:
+			feature.Reason = ReasonSingleValue
+		default:
+			continue
+		}
+		stale = append(stale, feature)
+	}
+	return stale
+}
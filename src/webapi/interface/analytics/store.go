@@ -0,0 +1,24 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoStore is the Store implementation which keeps the evaluation counts in MongoDB
+type MongoStore struct {
+	MongoClient  mongo.Client
+	DatabaseName string
+}
+
+// NewMongoStore returns a new MongoStore using the given client and database
+func NewMongoStore(client mongo.Client, databaseName string) *MongoStore {
+	return &MongoStore{client, databaseName}
+}
+
+// AddEvaluationCounts adds the given counts to the stored counts of the tenant
+func (s *MongoStore) AddEvaluationCounts(ctx context.Context, tenant data.Tenant, counts []data.EvaluationCount) error {
+	return data.AddEvaluationCounts(ctx, counts, tenant, s.MongoClient, s.DatabaseName)
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// APIKeyPrefix is the prefix of every issued API key, making them easy to recognize in logs and secret scanners
+const APIKeyPrefix = "gbk_"
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash of the API key which is stored instead of the key itself.
+// The keys are random enough that a plain SHA-256 is sufficient
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+func Test_GenerateAPIKey(t *testing.T) {
+	key, err := auth.GenerateAPIKey()
+	if err != nil || !strings.HasPrefix(key, auth.APIKeyPrefix) || len(key) != len(auth.APIKeyPrefix)+43 {
+		t.Errorf("Error generating an API key. Expected %s followed by 43 characters, got %s (%v)", auth.APIKeyPrefix, key, err)
+	}
+	other, _ := auth.GenerateAPIKey()
+	if other == key {
+		t.Errorf("Error generating an API key. Expected a new key each time, got %s twice", key)
+	}
+}
+
+func Test_HashAPIKey(t *testing.T) {
+	hash := auth.HashAPIKey("gbk_key")
+	if len(hash) != 64 || strings.Trim(hash, "0123456789abcdef") != "" {
+		t.Errorf("Error hashing an API key. Expected 64 hex characters, got %s", hash)
+	}
+	if auth.HashAPIKey("gbk_key") != hash {
+		t.Errorf("Error hashing an API key. Expected the same hash for the same key, got %s and %s", hash, auth.HashAPIKey("gbk_key"))
+	}
+	if auth.HashAPIKey("gbk_other") == hash {
+		t.Errorf("Error hashing an API key. Expected another hash for another key, got %s", hash)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrNoKey is an error raised when there's no key to validate the token with
+var ErrNoKey = errors.New("No key found to validate the token")
+
+// ErrNoExpiry is an error raised when the token has no expiry, so it would be valid forever
+var ErrNoExpiry = errors.New("The token has no expiry")
+
+// ErrInvalidIssuer is an error raised when the token is not issued by the configured issuer
+var ErrInvalidIssuer = errors.New("The token is not issued by the expected issuer")
+
+// ErrInvalidAudience is an error raised when the token is not issued for the configured audience
+var ErrInvalidAudience = errors.New("The token is not issued for the expected audience")
+
+// Claims holds the claims of a validated token
+type Claims map[string]interface{}
+
+// Subject returns the sub claim of the token
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String returns the claim with the given name if it's a string, empty string otherwise
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns the claim with the given name as a string slice, accepting both a JSON array and a space separated string
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := []string{}
+		for _, i := range v {
+			if s, ok := i.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Authenticator validates the bearer tokens signed either with HS256 using a shared secret or with RS256 using the keys of a JWKS file
+type Authenticator struct {
+	secret   []byte
+	keys     map[string]*rsa.PublicKey
+	issuer   string
+	audience string
+}
+
+// NewAuthenticator returns a new Authenticator with the given shared secret and the keys in the given JWKS file.
+// Either of them can be empty, in which case the tokens signed with the relevant algorithm are rejected.
+// The iss and aud claims of the tokens are checked against the issuer and the audience unless they're empty
+func NewAuthenticator(secret string, jwksFile string, issuer string, audience string) (*Authenticator, error) {
+	a := &Authenticator{secret: []byte(secret), keys: map[string]*rsa.PublicKey{}, issuer: issuer, audience: audience}
+	if jwksFile == "" {
+		return a, nil
+	}
+	content, err := ioutil.ReadFile(jwksFile)
+	if err != nil {
+		return nil, err
+	}
+	a.keys, err = parseJWKS(content)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing JWKS file '%s': %w", jwksFile, err)
+	}
+	return a, nil
+}
+
+// Authenticate validates the signature, expiry, not-before date, issuer and audience of the token and returns its claims.
+// Tokens without an expiry are rejected
+func (a *Authenticator) Authenticate(token string) (Claims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"HS256", "RS256"}))
+	_, err := parser.ParseWithClaims(token, claims, a.key)
+	if err != nil {
+		return nil, err
+	}
+	// the parser only checks the expiry if the token has one
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, ErrNoExpiry
+	}
+	if a.issuer != "" && !claims.VerifyIssuer(a.issuer, true) {
+		return nil, ErrInvalidIssuer
+	}
+	if a.audience != "" && !claims.VerifyAudience(a.audience, true) {
+		return nil, ErrInvalidAudience
+	}
+	return Claims(claims), nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case "HS256":
+		if len(a.secret) == 0 {
+			return nil, ErrNoKey
+		}
+		return a.secret, nil
+	case "RS256":
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := a.keys[kid]; ok {
+			return key, nil
+		}
+		// a token without a kid can only be matched when there's a single key
+		if kid == "" && len(a.keys) == 1 {
+			for _, key := range a.keys {
+				return key, nil
+			}
+		}
+	}
+	return nil, ErrNoKey
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// parseJWKS returns the RSA signing keys in the JWKS document mapped to their key ids
+func parseJWKS(content []byte) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	err := json.Unmarshal(content, &set)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid modulus of key '%s': %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid exponent of key '%s': %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+type keyClaims struct{}
+
+// NewContext returns a new context carrying the given claims
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, keyClaims{}, claims)
+}
+
+// FromContext returns the claims carried by the context, if any
+func FromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(keyClaims{}).(Claims)
+	return claims, ok
+}
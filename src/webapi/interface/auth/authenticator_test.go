@@ -0,0 +1,121 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+func Test_AuthenticateHS256(t *testing.T) {
+	authenticator, _ := auth.NewAuthenticator("shared-secret", "", "", "")
+	token := signHS256(t, "shared-secret", jwt.MapClaims{"sub": "user1", "roles": []string{"editor"}, "exp": time.Now().Add(time.Hour).Unix()})
+	claims, err := authenticator.Authenticate(token)
+	if err != nil || claims.Subject() != "user1" {
+		t.Errorf("Error authenticating a valid token. Expected subject user1, got %s (%v)", claims.Subject(), err)
+	}
+	if roles := claims.Strings("roles"); len(roles) != 1 || roles[0] != "editor" {
+		t.Errorf("Error reading an array claim. Expected [editor], got %v", roles)
+	}
+	_, err = authenticator.Authenticate(signHS256(t, "another-secret", jwt.MapClaims{"sub": "user1"}))
+	if err == nil {
+		t.Errorf("Error authenticating a token signed with another secret. Expected an error, got nil")
+	}
+	_, err = authenticator.Authenticate(signHS256(t, "shared-secret", jwt.MapClaims{"sub": "user1", "exp": time.Now().Add(-time.Hour).Unix()}))
+	if err == nil {
+		t.Errorf("Error authenticating an expired token. Expected an error, got nil")
+	}
+	_, err = authenticator.Authenticate(signHS256(t, "shared-secret", jwt.MapClaims{"sub": "user1"}))
+	if err != auth.ErrNoExpiry {
+		t.Errorf("Error authenticating a token without an expiry. Expected %v, got %v", auth.ErrNoExpiry, err)
+	}
+}
+
+func Test_AuthenticateIssuerAndAudience(t *testing.T) {
+	authenticator, _ := auth.NewAuthenticator("shared-secret", "", "https://issuer.example.com", "goboiler")
+	exp := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		err    error
+	}{
+		{"matching", jwt.MapClaims{"iss": "https://issuer.example.com", "aud": "goboiler", "exp": exp}, nil},
+		{"audience in a list", jwt.MapClaims{"iss": "https://issuer.example.com", "aud": []string{"other", "goboiler"}, "exp": exp}, nil},
+		{"another issuer", jwt.MapClaims{"iss": "https://other.example.com", "aud": "goboiler", "exp": exp}, auth.ErrInvalidIssuer},
+		{"no issuer", jwt.MapClaims{"aud": "goboiler", "exp": exp}, auth.ErrInvalidIssuer},
+		{"another audience", jwt.MapClaims{"iss": "https://issuer.example.com", "aud": "other", "exp": exp}, auth.ErrInvalidAudience},
+		{"no audience", jwt.MapClaims{"iss": "https://issuer.example.com", "exp": exp}, auth.ErrInvalidAudience},
+	}
+	for _, test := range tests {
+		_, err := authenticator.Authenticate(signHS256(t, "shared-secret", test.claims))
+		if err != test.err {
+			t.Errorf("Error authenticating the token with %s claims. Expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func Test_AuthenticateRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := writeJWKS(t, "key1", &key.PublicKey)
+	defer os.RemoveAll(path.Dir(jwksFile))
+	authenticator, err := auth.NewAuthenticator("", jwksFile, "", "")
+	if err != nil {
+		t.Fatalf("Error reading the JWKS file: %v", err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "service1", "scope": "products:read products:write", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "key1"
+	signed, _ := token.SignedString(key)
+	claims, err := authenticator.Authenticate(signed)
+	if err != nil || claims.Subject() != "service1" {
+		t.Errorf("Error authenticating a valid token. Expected subject service1, got %s (%v)", claims.Subject(), err)
+	}
+	if scopes := claims.Strings("scope"); len(scopes) != 2 {
+		t.Errorf("Error reading a space separated claim. Expected 2 values, got %v", scopes)
+	}
+	token.Header["kid"] = "key2"
+	signed, _ = token.SignedString(key)
+	_, err = authenticator.Authenticate(signed)
+	if err == nil {
+		t.Errorf("Error authenticating a token with an unknown key id. Expected an error, got nil")
+	}
+	_, err = authenticator.Authenticate(signHS256(t, "", jwt.MapClaims{"sub": "service1"}))
+	if err == nil {
+		t.Errorf("Error authenticating an HS256 token without a shared secret. Expected an error, got nil")
+	}
+}
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	dir, err := ioutil.TempDir("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	content := fmt.Sprintf(`{"keys":[{"kty":"RSA","use":"sig","alg":"RS256","kid":"%s","n":"%s","e":"%s"}]}`, kid, n, e)
+	file := path.Join(dir, "jwks.json")
+	err = ioutil.WriteFile(file, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -0,0 +1,69 @@
+package auth
+
+// Role is the enum that enumerates the roles a caller can have
+type Role string
+
+const (
+	// Viewer can read the products and their feature flags, and the customers, items and orders of the shop
+	Viewer Role = "viewer"
+	// Editor can read and change the products and their feature flags, and the customers, items and orders of the shop
+	Editor Role = "editor"
+	// Admin can do everything, including managing webhooks and API keys and debugging the server
+	Admin Role = "admin"
+)
+
+// Permission is the enum that enumerates the operations which need authorization
+type Permission string
+
+const (
+	// ReadProducts allows reading the products and their feature flags
+	ReadProducts Permission = "products:read"
+	// WriteProducts allows creating and changing the products and their feature flags
+	WriteProducts Permission = "products:write"
+	// ManageDeletedProducts allows listing and restoring the soft deleted products
+	ManageDeletedProducts Permission = "products:deleted"
+	// ManageEnvironments allows creating and removing the environments
+	ManageEnvironments Permission = "environments:manage"
+	// ManageWebhooks allows managing the webhook subscriptions
+	ManageWebhooks Permission = "webhooks:manage"
+	// ManageAPIKeys allows issuing, rotating and revoking API keys
+	ManageAPIKeys Permission = "apikeys:manage"
+	// ReadAudit allows reading the audit log
+	ReadAudit Permission = "audit:read"
+	// ReadShop allows reading the customers, items and orders of the shop
+	ReadShop Permission = "shop:read"
+	// WriteShop allows creating and changing the customers and items, and ordering items
+	WriteShop Permission = "shop:write"
+	// ReadDebug allows profiling the server and reading its build info, configuration and routes on the admin port
+	ReadDebug Permission = "debug:read"
+)
+
+// RolesClaim is the name of the claim holding the roles of the caller
+const RolesClaim = "roles"
+
+var rolePermissions = map[Role][]Permission{
+	Viewer: {ReadProducts, ReadShop},
+	Editor: {ReadProducts, WriteProducts, ReadShop, WriteShop},
+	Admin:  {ReadProducts, WriteProducts, ReadShop, WriteShop, ManageDeletedProducts, ManageEnvironments, ManageWebhooks, ManageAPIKeys, ReadAudit, ReadDebug},
+}
+
+// Roles returns the roles of the caller
+func (c Claims) Roles() []Role {
+	roles := []Role{}
+	for _, r := range c.Strings(RolesClaim) {
+		roles = append(roles, Role(r))
+	}
+	return roles
+}
+
+// Can checks if one of the roles of the caller has the permission
+func (c Claims) Can(permission Permission) bool {
+	for _, r := range c.Roles() {
+		for _, p := range rolePermissions[r] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+func Test_Can(t *testing.T) {
+	viewer := auth.Claims{"roles": []interface{}{"viewer"}}
+	if !viewer.Can(auth.ReadProducts) || viewer.Can(auth.WriteProducts) {
+		t.Errorf("Error authorizing a viewer. Expected to read but not write products")
+	}
+	editor := auth.Claims{"roles": "viewer editor"}
+	if !editor.Can(auth.WriteProducts) || editor.Can(auth.ManageAPIKeys) {
+		t.Errorf("Error authorizing an editor. Expected to write products but not manage API keys")
+	}
+	admin := auth.Claims{"roles": []interface{}{"admin"}}
+	if !admin.Can(auth.ManageWebhooks) || !admin.Can(auth.ManageAPIKeys) || !admin.Can(auth.ReadAudit) {
+		t.Errorf("Error authorizing an admin. Expected to manage webhooks and API keys and to read the audit log")
+	}
+	if (auth.Claims{"roles": []interface{}{"unknown"}}).Can(auth.ReadProducts) || (auth.Claims{}).Can(auth.ReadProducts) {
+		t.Errorf("Error authorizing a caller without a known role. Expected no permissions")
+	}
+}
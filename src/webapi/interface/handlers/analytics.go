@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/analytics"
+)
+
+// defaultStaleDays is the number of days a feature must not be evaluated for to be reported as stale if it's not given
+const defaultStaleDays = 30
+
+// GetStaleFeatures gets the features of a product which are likely not needed anymore
+// swagger:route GET /products/{id}/stale Products getStaleFeatures
+// Return the features of the Product not evaluated in the number of days given with the days query parameter (30 by default)
+// or which always returned the same value
+// responses:
+//	200: StaleFeaturesResponse
+//	400: errorResponse
+//	404: errorResponse
+// GetStaleFeatures handles GET requests
+func (ctx *DBContext) GetStaleFeatures(rw http.ResponseWriter, r *http.Request) {
+	days := defaultStaleDays
+	if d := r.URL.Query().Get("days"); d != "" {
+		var err error
+		days, err = strconv.Atoi(d)
+		if err != nil || days < 1 {
+			rw.WriteHeader(http.StatusBadRequest)
+			data.ToJSON(&GenericError{Message: "days must be a positive integer"}, rw)
+			return
+		}
+	}
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	product, err := data.GetProductByID(r.Context(), id, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Product")
+
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	counts, err := data.GetEvaluationCounts(r.Context(), product.ID, since, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting EvaluationCounts")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(analytics.StaleFeatures(*product, *counts, since), rw)
+}
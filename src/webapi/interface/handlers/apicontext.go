@@ -3,14 +3,18 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"os"
 	"time"
 
-	opentracing "github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/configuration"
+	"github.com/serdarkalayci/goboiler/webapi/data"
 	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/analytics"
+	"github.com/serdarkalayci/goboiler/webapi/interface/health"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tenancy"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tracing"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -25,6 +29,10 @@ type APIContext struct {
 type DBContext struct {
 	MongoClient  mongo.Client
 	DatabaseName string
+	Webhooks     *webhook.Dispatcher
+	Tenants      *tenancy.Resolver
+	Analytics    *analytics.Recorder
+	Health       *health.Registry
 	APIContext
 }
 
@@ -33,26 +41,15 @@ func NewAPIContext(v *dto.Validation) *APIContext {
 	return &APIContext{v}
 }
 
-// NewDBContext returns a new DBContext handler with the given logger
-func NewDBContext(v *dto.Validation) *DBContext {
+// NewDBContext returns a new DBContext handler connected to the database in the given configuration
+func NewDBContext(v *dto.Validation, config configuration.MongoConfig) *DBContext {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	// We try to get connectionstring value from the environment variables, if not found it falls back to local database
-	connectionString := os.Getenv("ConnectionString")
-	if connectionString == "" {
-		connectionString = "mongodb://localhost:27017"
-		log.Info().Msg("ConnectionString from Env not found, falling back to local DB")
-	} else {
-		log.Info().Msgf("ConnectionString from Env is used: '%s'", connectionString)
-	}
-	databaseName := os.Getenv("DatabaseName")
-	if databaseName == "" {
-		databaseName = "goboiler"
-		log.Info().Msg("DatabaseName from Env not found, falling back to default")
-	} else {
-		log.Info().Msgf("DatabaseName from Env is used: '%s'", databaseName)
-	}
-	client, err := mongo.NewClient(options.Client().ApplyURI(connectionString))
+	log.Info().Msgf("Connecting to database '%s'", config.DatabaseName)
+	client, err := mongo.NewClient(options.Client().
+		ApplyURI(config.ConnectionString).
+		SetMonitor(data.CommandMonitors(tracing.CommandMonitor(), middleware.MongoCommandMonitor())).
+		SetPoolMonitor(middleware.MongoPoolMonitor()))
 	err = client.Connect(ctx)
 	if err != nil {
 		log.Error().Err(err).Msgf("An error occured while connecting to tha database")
@@ -66,29 +63,7 @@ func NewDBContext(v *dto.Validation) *DBContext {
 		}
 		log.Info().Msg("Connected to MongoDB!")
 	}
-	return &DBContext{*client, databaseName, APIContext{v}}
-}
-
-// createSpan creates a new openTracing.Span with the given name and returns it
-func createSpan(spanName string, r *http.Request) (span opentracing.Span) {
-	tracer := opentracing.GlobalTracer()
-
-	wireContext, err := opentracing.GlobalTracer().Extract(
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(r.Header))
-	if err != nil {
-		// The method is called without a span context in the http header.
-		//
-		span = tracer.StartSpan(spanName)
-	} else {
-		// Create the span referring to the RPC client if available.
-		// If wireContext == nil, a root span will be created.
-		span = opentracing.StartSpan(spanName, ext.RPCServerOption(wireContext))
-	}
-	ext.SpanKindRPCClient.Set(span)
-	ext.HTTPUrl.Set(span, r.URL.RequestURI())
-	ext.HTTPMethod.Set(span, r.Method)
-	return span
+	return &DBContext{MongoClient: *client, DatabaseName: config.DatabaseName, APIContext: APIContext{v}}
 }
 
 // ErrInvalidRatingPath is an error message when the Rating path is not valid
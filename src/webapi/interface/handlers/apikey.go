@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+// APIKeyHeader is the header the API keys are sent with
+const APIKeyHeader = "X-API-Key"
+
+// IssueAPIKey creates a new API key
+// swagger:route POST /apikeys APIKeys issueAPIKey
+// Issue a new API key. The key is returned only once
+// responses:
+//	201: IssuedAPIKeyResponse
+//	422: errorValidation
+//	500: errorResponse
+// IssueAPIKey handles POST requests
+func (ctx *DBContext) IssueAPIKey(rw http.ResponseWriter, r *http.Request) {
+	apiKeyDTO := r.Context().Value(KeyAPIKey{}).(*dto.APIKey)
+	key, err := auth.GenerateAPIKey()
+	var apiKey *data.APIKey
+	if err == nil {
+		apiKey, err = data.AddAPIKey(r.Context(), data.APIKey{
+			Name:     apiKeyDTO.Name,
+			Hint:     keyHint(key),
+			Hash:     auth.HashAPIKey(key),
+			Scope:    data.APIKeyScope(apiKeyDTO.Scope),
+			Products: apiKeyDTO.Products,
+		}, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error issuing APIKey")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditCreate, auditAPIKeys, apiKey.ID.Hex(), nil, apiKey)
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON(&dto.IssuedAPIKey{ID: apiKey.ID, Key: key}, rw)
+}
+
+// GetAllAPIKeys gets all API keys
+// swagger:route GET /apikeys APIKeys getAllAPIKeys
+// Return a list of APIKey from the database, without the keys themselves
+// responses:
+//	200: APIKeysResponse
+//	500: errorResponse
+// GetAllAPIKeys handles GET requests
+func (ctx *DBContext) GetAllAPIKeys(rw http.ResponseWriter, r *http.Request) {
+	apiKeys, err := data.GetAPIKeys(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting APIKeys")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(apiKeys, rw)
+}
+
+// RotateAPIKey replaces an API key with a new one, keeping its scope
+// swagger:route POST /apikeys/{id}/rotate APIKeys rotateAPIKey
+// Issue a new key for the APIKey, after which the old key can not be used anymore
+// responses:
+//	200: IssuedAPIKeyResponse
+//	404: errorResponse
+// RotateAPIKey handles POST requests
+func (ctx *DBContext) RotateAPIKey(rw http.ResponseWriter, r *http.Request) {
+	id, err := getObjectID(r, "id")
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: data.ErrAPIKeyNotFound.Error()}, rw)
+		return
+	}
+	before, err := data.GetAPIKeyByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var key string
+	if err == nil {
+		key, err = auth.GenerateAPIKey()
+	}
+	var apiKey *data.APIKey
+	if err == nil {
+		apiKey, err = data.RotateAPIKey(r.Context(), id, keyHint(key), auth.HashAPIKey(key), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err == data.ErrAPIKeyNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error rotating APIKey")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditRotate, auditAPIKeys, id.Hex(), before, apiKey)
+	data.ToJSON(&dto.IssuedAPIKey{ID: id, Key: key}, rw)
+}
+
+// RevokeAPIKey revokes an API key
+// swagger:route DELETE /apikeys/{id} APIKeys revokeAPIKey
+// Revoke the APIKey, after which it can not be used anymore
+// responses:
+//	204: noContentResponse
+//	404: errorResponse
+// RevokeAPIKey handles DELETE requests
+func (ctx *DBContext) RevokeAPIKey(rw http.ResponseWriter, r *http.Request) {
+	var before *data.APIKey
+	id, err := getObjectID(r, "id")
+	if err == nil {
+		before, err = data.GetAPIKeyByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	} else {
+		err = data.ErrAPIKeyNotFound
+	}
+	if err == nil {
+		err = data.RevokeAPIKey(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err == data.ErrAPIKeyNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error revoking APIKey")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditDelete, auditAPIKeys, id.Hex(), before, nil)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// MiddlewareAPIKey authenticates the requests carrying an API key and adds the claims of the key to the context.
+// Read-only keys get the viewer role and read-write keys get the editor role.
+// Keys restricted to products are only allowed to reach those products.
+// Requests without an API key and requests to the exempt paths are passed to the next handler as they are
+func (ctx *DBContext) MiddlewareAPIKey(exemptPaths []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" || isExempt(r.URL.Path, exemptPaths) {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			apiKey, err := data.GetAPIKeyByHash(r.Context(), auth.HashAPIKey(key), ctx.MongoClient, ctx.DatabaseName)
+			if err != nil {
+				log.Ctx(r.Context()).Debug().Err(err).Msg("Error validating API key")
+				unauthorized(rw, "API key is not valid")
+				return
+			}
+			if !apiKeyAllowsProduct(apiKey, r) {
+				forbidden(rw, "API key is restricted to other products")
+				return
+			}
+			products := []interface{}{}
+			for _, p := range apiKey.Products {
+				products = append(products, p.Hex())
+			}
+			role := auth.Viewer
+			if apiKey.Scope == data.ReadWrite {
+				role = auth.Editor
+			}
+			claims := auth.Claims{
+				"sub":           "apikey:" + apiKey.ID.Hex(),
+				"name":          apiKey.Name,
+				"scope":         string(apiKey.Scope),
+				"products":      products,
+				auth.RolesClaim: []interface{}{string(role)},
+			}
+			if ctx.Tenants != nil && apiKey.Tenant != "" {
+				// the key can only be used for the tenant it's been issued for. The keys issued without a tenant
+				// get no tenant claim, so they can't reach any tenant
+				claims[ctx.Tenants.Claim] = apiKey.Tenant
+			}
+
+			// add the claims to the context
+			r = r.WithContext(auth.NewContext(r.Context(), claims))
+
+			// Call the next handler, which can be another middleware in the chain, or the final handler.
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// apiKeyAllowsProduct checks if the API key is allowed to reach the route of the request. A key restricted to products
+// can only reach the routes of those products, the other routes such as the product list, the shop and the environments
+// are out of its reach
+func apiKeyAllowsProduct(apiKey *data.APIKey, r *http.Request) bool {
+	if len(apiKey.Products) == 0 {
+		return true
+	}
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return true
+	}
+	template, _ := route.GetPathTemplate()
+	if template != "/products/{id}" && !strings.HasPrefix(template, "/products/{id}/") {
+		return false
+	}
+	id, err := getObjectID(r, "id")
+	if err != nil {
+		return false
+	}
+	for _, p := range apiKey.Products {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+// keyHint returns the first characters of the key after its prefix
+func keyHint(key string) string {
+	return key[:len(auth.APIKeyPrefix)+6]
+}
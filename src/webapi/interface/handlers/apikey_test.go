@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tenancy"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// apiKeyRouter returns a router authenticating the API keys with the mock client, which records the claims of the callers
+func apiKeyRouter(mt *mtest.T, claims *auth.Claims) *mux.Router {
+	resolver, _ := tenancy.NewResolver(tenancy.HeaderSource, "X-Tenant-ID", "tenant", data.FieldIsolation)
+	ctx := &handlers.DBContext{MongoClient: *mt.Client, DatabaseName: "goboiler", Tenants: resolver}
+	record := func(rw http.ResponseWriter, r *http.Request) {
+		*claims, _ = auth.FromContext(r.Context())
+	}
+	router := mux.NewRouter()
+	router.Use(ctx.MiddlewareAPIKey([]string{"/health/live", "/metrics"}))
+	router.HandleFunc("/health/live", record)
+	router.HandleFunc("/metrics", record)
+	router.HandleFunc("/products", record)
+	router.HandleFunc("/products/{id}", record)
+	router.HandleFunc("/customers", record)
+	router.HandleFunc("/customers/{id}/orders", record).Methods(http.MethodPost)
+	router.HandleFunc("/environments", record)
+	return router
+}
+
+// apiKeyResponse returns the mock response of finding the given API key
+func apiKeyResponse(apiKey bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "goboiler.apikeys", mtest.FirstBatch, apiKey)
+}
+
+func Test_MiddlewareAPIKey(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("without key", func(mt *mtest.T) {
+		var claims auth.Claims
+		rw := httptest.NewRecorder()
+		apiKeyRouter(mt, &claims).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/products", nil))
+		if rw.Code != http.StatusOK || claims != nil {
+			mt.Errorf("Error passing a request without an API key. Expected status 200 without claims, got %d with %v", rw.Code, claims)
+		}
+	})
+	mt.Run("exempt paths", func(mt *mtest.T) {
+		var claims auth.Claims
+		for _, path := range []string{"/health/live", "/metrics"} {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Header.Set(handlers.APIKeyHeader, "gbk_wrong")
+			rw := httptest.NewRecorder()
+			apiKeyRouter(mt, &claims).ServeHTTP(rw, r)
+			if rw.Code != http.StatusOK {
+				mt.Errorf("Error calling %s with a wrong API key. Expected status 200, got %d", path, rw.Code)
+			}
+		}
+		if e := mt.GetStartedEvent(); e != nil {
+			mt.Errorf("Error calling the exempt paths with an API key. Expected the key not to be looked up, got %s", e.CommandName)
+		}
+	})
+	mt.Run("wrong key", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.apikeys", mtest.FirstBatch))
+		var claims auth.Claims
+		r := httptest.NewRequest(http.MethodGet, "/products", nil)
+		r.Header.Set(handlers.APIKeyHeader, "gbk_wrong")
+		rw := httptest.NewRecorder()
+		apiKeyRouter(mt, &claims).ServeHTTP(rw, r)
+		if rw.Code != http.StatusUnauthorized {
+			mt.Errorf("Error calling with a wrong API key. Expected status 401, got %d", rw.Code)
+		}
+		hash := mt.GetStartedEvent().Command.Lookup("filter", "hash").StringValue()
+		if hash != auth.HashAPIKey("gbk_wrong") {
+			mt.Errorf("Error looking up the API key. Expected its hash %s, got %s", auth.HashAPIKey("gbk_wrong"), hash)
+		}
+	})
+	mt.Run("claims", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(
+			apiKeyResponse(bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "CI"}, {Key: "scope", Value: "read"}, {Key: "tenant", Value: "acme"}}),
+			apiKeyResponse(bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "CI"}, {Key: "scope", Value: "readwrite"}}),
+		)
+		expected := []struct {
+			role   auth.Role
+			tenant string
+		}{{auth.Viewer, "acme"}, {auth.Editor, ""}}
+		for _, e := range expected {
+			var claims auth.Claims
+			r := httptest.NewRequest(http.MethodGet, "/products", nil)
+			r.Header.Set(handlers.APIKeyHeader, "gbk_key")
+			rw := httptest.NewRecorder()
+			apiKeyRouter(mt, &claims).ServeHTTP(rw, r)
+			roles := claims.Roles()
+			if rw.Code != http.StatusOK || claims.Subject() != "apikey:"+id.Hex() || len(roles) != 1 || roles[0] != e.role || claims.String("tenant") != e.tenant {
+				mt.Errorf("Error authenticating the API key. Expected the %s role and tenant '%s', got %d with %v", e.role, e.tenant, rw.Code, claims)
+			}
+		}
+	})
+	mt.Run("restricted to products", func(mt *mtest.T) {
+		allowed, other := primitive.NewObjectID(), primitive.NewObjectID()
+		tests := []struct {
+			method string
+			path   string
+			status int
+		}{
+			{http.MethodGet, "/products/" + allowed.Hex(), http.StatusOK},
+			{http.MethodGet, "/products/" + other.Hex(), http.StatusForbidden},
+			{http.MethodGet, "/products", http.StatusForbidden},
+			{http.MethodGet, "/customers", http.StatusForbidden},
+			{http.MethodPost, "/customers/" + primitive.NewObjectID().Hex() + "/orders", http.StatusForbidden},
+			{http.MethodGet, "/environments", http.StatusForbidden},
+		}
+		for _, test := range tests {
+			mt.AddMockResponses(apiKeyResponse(bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "scope", Value: "read"}, {Key: "products", Value: bson.A{allowed}}}))
+			var claims auth.Claims
+			r := httptest.NewRequest(test.method, test.path, nil)
+			r.Header.Set(handlers.APIKeyHeader, "gbk_key")
+			rw := httptest.NewRecorder()
+			apiKeyRouter(mt, &claims).ServeHTTP(rw, r)
+			if rw.Code != test.status {
+				mt.Errorf("Error calling %s with an API key restricted to %s. Expected status %d, got %d", test.path, allowed.Hex(), test.status, rw.Code)
+			}
+		}
+	})
+}
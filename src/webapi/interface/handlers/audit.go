@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Names of the audited resources
+const (
+	auditProducts      = "products"
+	auditSubscriptions = "webhooks"
+	auditAPIKeys       = "apikeys"
+	auditCustomers     = "customers"
+	auditItems         = "items"
+	auditOrders        = "orders"
+)
+
+// Names of the audited actions
+const (
+	auditCreate    = "create"
+	auditUpdate    = "update"
+	auditDelete    = "delete"
+	auditRotate    = "rotate"
+	auditRestore   = "restore"
+	auditRedeliver = "redeliver"
+	auditPlace     = "place"
+)
+
+// defaultAuditLimit is the number of audit entries returned when no limit is given
+const defaultAuditLimit = 100
+
+// GetAuditEntries gets the audit log
+// swagger:route GET /audit Audit getAuditEntries
+// Return the audit log, newest first, filtered by resource, resourceId, actor and the from-to time range given in RFC 3339 format.
+// At most 1000 entries are returned, even if the limit is higher
+// responses:
+//	200: AuditEntriesResponse
+//	400: errorResponse
+//	500: errorResponse
+// GetAuditEntries handles GET requests
+func (ctx *DBContext) GetAuditEntries(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := data.AuditFilter{
+		Resource:   query.Get("resource"),
+		ResourceID: query.Get("resourceId"),
+		Actor:      query.Get("actor"),
+		Limit:      defaultAuditLimit,
+	}
+	var err error
+	if v := query.Get("from"); v != "" && err == nil {
+		filter.From, err = time.Parse(time.RFC3339, v)
+	}
+	if v := query.Get("to"); v != "" && err == nil {
+		filter.To, err = time.Parse(time.RFC3339, v)
+	}
+	if v := query.Get("limit"); v != "" && err == nil {
+		filter.Limit, err = strconv.ParseInt(v, 10, 64)
+		if err == nil && filter.Limit < 1 {
+			err = fmt.Errorf("limit should be at least 1, got %d", filter.Limit)
+		}
+		if filter.Limit > data.MaxAuditLimit {
+			filter.Limit = data.MaxAuditLimit
+		}
+	}
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	entries, err := data.GetAuditEntries(r.Context(), filter, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting AuditEntries")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(entries, rw)
+}
+
+// audit records the mutating call in the audit log. Failures are logged and do not fail the call
+func (ctx *DBContext) audit(r *http.Request, action, resource, resourceID string, before, after interface{}) {
+	entry := data.NewAuditEntry(actor(r), action, resource, resourceID, before, after)
+	entry.RequestID = middleware.RequestID(r.Context())
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		entry.TraceID = sc.TraceID().String()
+	}
+	err := data.AddAuditEntry(r.Context(), entry, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msgf("Error recording the audit entry of %s %s %s", action, resource, resourceID)
+	}
+}
+
+// actor returns the subject of the caller, empty if the caller is not authenticated
+func actor(r *http.Request) string {
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		return claims.Subject()
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_GetAuditEntriesLimit(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	tests := []struct {
+		name   string
+		limit  string
+		status int
+	}{
+		{"zero limit", "0", http.StatusBadRequest},
+		{"negative limit", "-5", http.StatusBadRequest},
+		{"limit which is not a number", "ten", http.StatusBadRequest},
+		{"limit", "10", http.StatusOK},
+		{"limit over the maximum", "5000", http.StatusOK},
+	}
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "goboiler.audit", mtest.FirstBatch))
+			ctx := handlers.DBContext{MongoClient: *mt.Client, DatabaseName: "goboiler", APIContext: *handlers.NewAPIContext(dto.NewValidation())}
+			rw := httptest.NewRecorder()
+			ctx.GetAuditEntries(rw, httptest.NewRequest(http.MethodGet, "/audit?limit="+test.limit, nil))
+			if rw.Code != test.status {
+				mt.Errorf("Error getting the audit entries with a %s. Expected status %d, got %d", test.name, test.status, rw.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+// MiddlewareAuthenticate returns a middleware which validates the bearer token of the request and adds its claims to the context.
+// Requests to the exempt paths and requests already authenticated by an API key are passed through without a token
+func MiddlewareAuthenticate(authenticator *auth.Authenticator, exemptPaths []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if _, ok := auth.FromContext(r.Context()); ok || isExempt(r.URL.Path, exemptPaths) {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, "Bearer ") {
+				unauthorized(rw, "Bearer token is missing")
+				return
+			}
+			claims, err := authenticator.Authenticate(strings.TrimPrefix(header, "Bearer "))
+			if err != nil {
+				log.Ctx(r.Context()).Debug().Err(err).Msg("Error validating bearer token")
+				unauthorized(rw, "Bearer token is not valid")
+				return
+			}
+
+			// add the claims to the context
+			r = r.WithContext(auth.NewContext(r.Context(), claims))
+
+			// Call the next handler, which can be another middleware in the chain, or the final handler.
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// isExempt checks if the path is one of the exempt paths. Exempt paths ending with a slash match every path under them
+func isExempt(path string, exemptPaths []string) bool {
+	for _, p := range exemptPaths {
+		if path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func unauthorized(rw http.ResponseWriter, message string) {
+	rw.Header().Set("WWW-Authenticate", "Bearer")
+	rw.WriteHeader(http.StatusUnauthorized)
+	data.ToJSON(&GenericError{Message: message}, rw)
+}
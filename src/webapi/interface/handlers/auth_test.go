@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+)
+
+func Test_MiddlewareAuthenticate(t *testing.T) {
+	authenticator, _ := auth.NewAuthenticator("shared-secret", "", "", "")
+	handler := handlers.MiddlewareAuthenticate(authenticator, []string{"/health/live"})(allow)
+	tests := []struct {
+		name   string
+		path   string
+		claims jwt.MapClaims
+		status int
+	}{
+		{"valid token", "/products", jwt.MapClaims{"sub": "user1", "exp": time.Now().Add(time.Hour).Unix()}, http.StatusOK},
+		{"token without an expiry", "/products", jwt.MapClaims{"sub": "user1"}, http.StatusUnauthorized},
+		{"expired token", "/products", jwt.MapClaims{"sub": "user1", "exp": time.Now().Add(-time.Hour).Unix()}, http.StatusUnauthorized},
+		{"missing token", "/products", nil, http.StatusUnauthorized},
+		{"missing token on an exempt path", "/health/live", nil, http.StatusOK},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.claims != nil {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, test.claims).SignedString([]byte("shared-secret"))
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, r)
+		if rw.Code != test.status {
+			t.Errorf("Error authenticating the %s. Expected status %d, got %d", test.name, test.status, rw.Code)
+		}
+	}
+}
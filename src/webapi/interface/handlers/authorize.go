@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+// Authorize returns a handler which calls next only if one of the roles of the caller has the permission
+func Authorize(permission auth.Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			unauthorized(rw, "Caller is not authenticated")
+			return
+		}
+		if !claims.Can(permission) {
+			forbidden(rw, "Caller is not allowed to "+string(permission))
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// AuthorizeProductOwner returns a handler which calls next if one of the roles of the caller has the permission
+// or the caller is one of the owners of the product in the URL
+func (ctx *DBContext) AuthorizeProductOwner(permission auth.Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			unauthorized(rw, "Caller is not authenticated")
+			return
+		}
+		if claims.Can(permission) || ctx.ownsProduct(claims, r) {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		forbidden(rw, "Caller is not allowed to "+string(permission))
+	})
+}
+
+// ownsProduct checks if the subject of the claims is one of the owners of the product in the URL
+func (ctx *DBContext) ownsProduct(claims auth.Claims, r *http.Request) bool {
+	id, err := getObjectID(r, "id")
+	if err != nil || claims.Subject() == "" {
+		return false
+	}
+	product, err := data.GetProductByID(r.Context(), id, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		return false
+	}
+	for _, owner := range product.Owners {
+		if owner == claims.Subject() {
+			return true
+		}
+	}
+	return false
+}
+
+func forbidden(rw http.ResponseWriter, message string) {
+	rw.WriteHeader(http.StatusForbidden)
+	data.ToJSON(&GenericError{Message: message}, rw)
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// allow is the handler behind the authorization, which writes 200
+var allow = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+
+// withClaims returns the request made by the caller with the given claims, or an anonymous caller if they're nil
+func withClaims(r *http.Request, claims auth.Claims) *http.Request {
+	if claims == nil {
+		return r
+	}
+	return r.WithContext(auth.NewContext(r.Context(), claims))
+}
+
+func Test_Authorize(t *testing.T) {
+	handler := handlers.Authorize(auth.WriteProducts, allow)
+	tests := []struct {
+		name   string
+		claims auth.Claims
+		status int
+	}{
+		{"anonymous caller", nil, http.StatusUnauthorized},
+		{"viewer", auth.Claims{"sub": "viewer", auth.RolesClaim: []interface{}{string(auth.Viewer)}}, http.StatusForbidden},
+		{"caller without roles", auth.Claims{"sub": "nobody"}, http.StatusForbidden},
+		{"editor", auth.Claims{"sub": "editor", auth.RolesClaim: []interface{}{string(auth.Editor)}}, http.StatusOK},
+		{"admin", auth.Claims{"sub": "admin", auth.RolesClaim: "viewer admin"}, http.StatusOK},
+	}
+	for _, test := range tests {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, withClaims(httptest.NewRequest(http.MethodPut, "/products", nil), test.claims))
+		if rw.Code != test.status {
+			t.Errorf("Error authorizing the %s. Expected status %d, got %d", test.name, test.status, rw.Code)
+		}
+	}
+}
+
+func Test_AuthorizeProductOwner(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	product := bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "Product One"}, {Key: "owners", Value: bson.A{"alice"}}}
+	viewer := func(sub string) auth.Claims {
+		return auth.Claims{"sub": sub, auth.RolesClaim: []interface{}{string(auth.Viewer)}}
+	}
+	tests := []struct {
+		name      string
+		path      string
+		claims    auth.Claims
+		responses []bson.D
+		status    int
+	}{
+		{"anonymous caller", "/products/" + id.Hex(), nil, nil, http.StatusUnauthorized},
+		{"editor", "/products/" + id.Hex(), auth.Claims{"sub": "bob", auth.RolesClaim: []interface{}{string(auth.Editor)}}, nil, http.StatusOK},
+		{"owner", "/products/" + id.Hex(), viewer("alice"), []bson.D{mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, product)}, http.StatusOK},
+		{"other viewer", "/products/" + id.Hex(), viewer("bob"), []bson.D{mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, product)}, http.StatusForbidden},
+		{"owner of a missing product", "/products/" + id.Hex(), viewer("alice"), []bson.D{mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch)}, http.StatusForbidden},
+		{"caller without subject", "/products/" + id.Hex(), auth.Claims{auth.RolesClaim: []interface{}{string(auth.Viewer)}}, nil, http.StatusForbidden},
+		{"owner with a malformed id", "/products/not-an-id", viewer("alice"), nil, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			mt.AddMockResponses(test.responses...)
+			ctx := &handlers.DBContext{MongoClient: *mt.Client, DatabaseName: "goboiler"}
+			router := mux.NewRouter()
+			router.Handle("/products/{id}", ctx.AuthorizeProductOwner(auth.WriteProducts, allow))
+			rw := httptest.NewRecorder()
+			router.ServeHTTP(rw, withClaims(httptest.NewRequest(http.MethodPut, test.path, nil), test.claims))
+			if rw.Code != test.status {
+				mt.Errorf("Error authorizing the %s. Expected status %d, got %d", test.name, test.status, rw.Code)
+			}
+			if rw.Code == http.StatusUnauthorized && rw.Header().Get("WWW-Authenticate") != "Bearer" {
+				mt.Errorf("Error rejecting the %s. Expected the WWW-Authenticate header, got none", test.name)
+			}
+		})
+	}
+}
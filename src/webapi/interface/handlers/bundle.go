@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
+)
+
+// auditImport is the name of the audited action of importing a product
+const auditImport = "import"
+
+// ExportProducts exports the products and environments as a bundle
+// swagger:route GET /products/export Products exportProducts
+// Return every Product which is not deleted together with the environments as a bundle, in YAML with format=yaml and in JSON otherwise
+// responses:
+//	200: BundleResponse
+//	500: errorResponse
+// ExportProducts handles GET requests
+func (ctx *DBContext) ExportProducts(rw http.ResponseWriter, r *http.Request) {
+	products, err := data.GetProducts(r.Context(), false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var environments *[]data.Environment
+	if err == nil {
+		environments, err = data.GetEnvironments(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error exporting Products")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	b := bundle.Bundle{Version: bundle.Version, ExportedAt: time.Now().UTC(), Environments: *environments, Products: []data.Product{}}
+	if *products != nil {
+		b.Products = *products
+	}
+	format := bundleFormat(r, r.Header.Get("Accept"))
+	if format == bundle.YAML {
+		rw.Header().Set("Content-Type", "application/yaml")
+	}
+	rw.Header().Set("Content-Disposition", "attachment; filename=products."+string(format))
+	err = bundle.Encode(b, rw, format)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error serializing bundle")
+	}
+}
+
+// ImportProducts imports the products and environments of a bundle
+// swagger:route POST /products/import Products importProducts
+// Create or update the products and create the environments of a bundle in YAML or JSON, depending on the format query parameter or the Content-Type.
+// Products are matched by id and keep their ids, or with remap=true they're matched by name and new products get new ids.
+// With dryRun=true only the report of what would change is returned. Nothing is changed if there's a conflict, such as a product
+// which is not valid or is configured in an environment which doesn't exist.
+// The changes are not made in a transaction: if one fails, the changes made before it are kept and a 500 response is returned,
+// and importing the same bundle again makes the remaining changes
+// responses:
+//	200: ImportReportResponse
+//	400: errorResponse
+//	409: ImportReportResponse
+//	500: errorResponse
+// ImportProducts handles POST requests
+func (ctx *DBContext) ImportProducts(rw http.ResponseWriter, r *http.Request) {
+	b, err := bundle.Decode(r.Body, bundleFormat(r, r.Header.Get("Content-Type")))
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error deserializing bundle")
+
+		rw.WriteHeader(http.StatusBadRequest)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	products, err := data.GetProducts(r.Context(), true, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var environments *[]data.Environment
+	if err == nil {
+		environments, err = data.GetEnvironments(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting the current Products")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	existing := []data.Product{}
+	if *products != nil {
+		existing = *products
+	}
+	plan := bundle.NewPlan(*b, existing, *environments, r.URL.Query().Get("remap") == "true")
+	ctx.checkBundle(plan, *environments)
+	source := ctx.features(r)
+	plan.Check(source)
+	if !source.ok(rw, r) {
+		return
+	}
+	if claims, _ := auth.FromContext(r.Context()); !claims.Can(auth.ManageEnvironments) {
+		plan.RejectEnvironments("Caller is not allowed to " + string(auth.ManageEnvironments))
+	}
+	plan.Report.DryRun = r.URL.Query().Get("dryRun") == "true"
+	if plan.Report.Conflicts > 0 && !plan.Report.DryRun {
+		rw.WriteHeader(http.StatusConflict)
+		data.ToJSON(plan.Report, rw)
+		return
+	}
+	if !plan.Report.DryRun {
+		err = ctx.applyPlan(r, plan)
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error importing Products")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(plan.Report, rw)
+}
+
+// checkBundle marks the planned products which are not valid or are configured in environments which neither exist
+// nor are created by the import as conflicts, like the single product endpoints reject them
+func (ctx *DBContext) checkBundle(plan *bundle.Plan, environments []data.Environment) {
+	keys := map[string]bool{}
+	for _, e := range append(append([]data.Environment{}, environments...), plan.Environments...) {
+		keys[e.Key] = true
+	}
+	lookup := func(key string) error {
+		if !keys[key] {
+			return data.ErrEnvironmentNotFound
+		}
+		return nil
+	}
+	for _, p := range append(append([]data.Product{}, plan.Creates...), plan.Updates...) {
+		productDTO := toProductDTO(p)
+		messages := append(ctx.v.Validate(productDTO).Errors(), environmentErrors(productDTO, lookup)...)
+		if len(messages) > 0 {
+			plan.Conflict(p.ID, strings.Join(messages, ", "))
+		}
+	}
+}
+
+// applyPlan makes the changes of the import, recording every change like the single product endpoints do.
+// The changes are made one by one without a transaction, so the ones made before a failure are kept
+func (ctx *DBContext) applyPlan(r *http.Request, plan *bundle.Plan) error {
+	for _, e := range plan.Environments {
+		environment, err := data.AddEnvironment(r.Context(), e, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+		if err != nil {
+			return err
+		}
+		ctx.audit(r, auditImport, auditEnvironments, environment.Key, nil, environment)
+	}
+	for _, p := range plan.Creates {
+		product, err := data.ImportProduct(r.Context(), p, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+		if err != nil {
+			return err
+		}
+		ctx.recordRevision(r, product)
+		ctx.audit(r, auditImport, auditProducts, product.ID.Hex(), nil, product)
+		ctx.publish(r, webhook.EventProductCreated, product)
+	}
+	for i, p := range plan.Updates {
+		product, err := data.UpdateProduct(r.Context(), p, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+		if err != nil {
+			return err
+		}
+		ctx.recordRevision(r, product)
+		ctx.audit(r, auditImport, auditProducts, product.ID.Hex(), plan.Befores[i], product)
+		ctx.publish(r, webhook.EventProductUpdated, product)
+	}
+	return nil
+}
+
+// bundleFormat returns the format of the bundle from the format query parameter, falling back to the given media type
+func bundleFormat(r *http.Request, mediaType string) bundle.Format {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if format == string(bundle.YAML) {
+			return bundle.YAML
+		}
+		return bundle.JSON
+	}
+	if strings.Contains(mediaType, "yaml") {
+		return bundle.YAML
+	}
+	return bundle.JSON
+}
@@ -0,0 +1,48 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_ImportInvalidProduct(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("import", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, "goboiler.environments", mtest.FirstBatch, bson.D{{Key: "key", Value: "prod"}}),
+		)
+		ctx := &handlers.DBContext{MongoClient: *mt.Client, DatabaseName: "goboiler", APIContext: *handlers.NewAPIContext(dto.NewValidation())}
+		content := `{"version": 1, "products": [
+			{"id": "5f7b1a2e9d1e8a1b2c3d4e5f", "name": "Shop", "features": [{"name": "Checkout", "code": "checkout", "type": 1, "defaultValue": 1.5}]},
+			{"id": "5f7b1a2e9d1e8a1b2c3d4e60", "name": "Billing", "features": [{"name": "Payments", "code": "payments", "environments": {"staging": {"enabled": true}}}]},
+			{"id": "5f7b1a2e9d1e8a1b2c3d4e61", "name": "Search", "features": [{"name": "Suggest", "code": "suggest", "environments": {"prod": {"enabled": true}}}]}
+		]}`
+		rw := httptest.NewRecorder()
+		ctx.ImportProducts(rw, httptest.NewRequest(http.MethodPost, "/products/import", bytes.NewBufferString(content)))
+		if rw.Code != http.StatusConflict {
+			t.Fatalf("Error importing invalid products. Expected status 409, got %d", rw.Code)
+		}
+		var report bundle.Report
+		json.NewDecoder(rw.Body).Decode(&report)
+		if report.Conflicts != 2 || report.Created != 1 {
+			t.Fatalf("Error importing invalid products. Expected 2 conflicts and 1 product created, got %v", report)
+		}
+		if p := report.Products[0]; p.Action != bundle.ActionConflict || !strings.Contains(p.Message, "'flagvalue'") {
+			t.Errorf("Error importing a product with an invalid value. Expected a conflict on the flagvalue tag, got %s: %s", p.Action, p.Message)
+		}
+		if p := report.Products[1]; p.Action != bundle.ActionConflict || !strings.Contains(p.Message, "Environments[staging]") {
+			t.Errorf("Error importing a product with an unknown environment. Expected a conflict on staging, got %s: %s", p.Action, p.Message)
+		}
+	})
+}
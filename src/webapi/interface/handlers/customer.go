@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAllCustomers gets all customers
+// swagger:route GET /customers Customers getAllCustomers
+// Return a list of Customer from the database
+// responses:
+//	200: CustomersResponse
+//	500: errorResponse
+// GetAllCustomers handles GET requests
+func (ctx *DBContext) GetAllCustomers(rw http.ResponseWriter, r *http.Request) {
+	customers, err := data.GetCustomers(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Customers")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(customers, rw)
+}
+
+// GetSingleCustomer gets a single customer
+// swagger:route GET /customers/{id} Customers getSingleCustomer
+// Return the Customer which matches the id
+// responses:
+//	200: CustomerResponse
+//	404: errorResponse
+// GetSingleCustomer handles GET requests
+func (ctx *DBContext) GetSingleCustomer(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getCustomerID(rw, r)
+	if !ok {
+		return
+	}
+	customer, err := data.GetCustomerByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		writeCustomerError(rw, r, err)
+		return
+	}
+	data.ToJSON(customer, rw)
+}
+
+// AddCustomer adds a new customer
+// swagger:route POST /customers Customers addCustomer
+// Create a new Customer in the database
+// responses:
+//	201: CustomerResponse
+//	422: errorValidation
+//	500: errorResponse
+// AddCustomer handles POST requests
+func (ctx *DBContext) AddCustomer(rw http.ResponseWriter, r *http.Request) {
+	customerDTO := r.Context().Value(KeyCustomer{}).(*dto.Customer)
+	customer, err := data.AddCustomer(r.Context(), toCustomerData(customerDTO), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		writeCustomerError(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditCreate, auditCustomers, customer.ID.Hex(), nil, customer)
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON(customer, rw)
+}
+
+// UpdateCustomer replaces a customer
+// swagger:route PUT /customers/{id} Customers updateCustomer
+// Update the Customer in the database
+// responses:
+//	200: CustomerResponse
+//	404: errorResponse
+//	422: errorValidation
+// UpdateCustomer handles PUT requests
+func (ctx *DBContext) UpdateCustomer(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getCustomerID(rw, r)
+	if !ok {
+		return
+	}
+	customerDTO := r.Context().Value(KeyCustomer{}).(*dto.Customer)
+	updated := toCustomerData(customerDTO)
+	updated.ID = id
+	before, err := data.GetCustomerByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var customer *data.Customer
+	if err == nil {
+		customer, err = data.UpdateCustomer(r.Context(), updated, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		writeCustomerError(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditUpdate, auditCustomers, customer.ID.Hex(), before, customer)
+	data.ToJSON(customer, rw)
+}
+
+// DeleteCustomer removes a customer
+// swagger:route DELETE /customers/{id} Customers deleteCustomer
+// Remove the Customer from the database
+// responses:
+//	204: noContentResponse
+//	404: errorResponse
+// DeleteCustomer handles DELETE requests
+func (ctx *DBContext) DeleteCustomer(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getCustomerID(rw, r)
+	if !ok {
+		return
+	}
+	before, err := data.GetCustomerByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err == nil {
+		err = data.DeleteCustomer(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		writeCustomerError(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditDelete, auditCustomers, id.Hex(), before, nil)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// writeCustomerError writes the response of an error raised while handling a customer
+func writeCustomerError(rw http.ResponseWriter, r *http.Request, err error) {
+	if err == data.ErrCustomerNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	log.Ctx(r.Context()).Error().Err(err).Msg("Error handling Customer")
+
+	rw.WriteHeader(http.StatusInternalServerError)
+	data.ToJSON(&GenericError{Message: err.Error()}, rw)
+}
+
+// getCustomerID returns the id of the customer from the URL, writing a 404 response if it's not a valid id
+func getCustomerID(rw http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	id, err := getObjectID(r, "id")
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: data.ErrCustomerNotFound.Error()}, rw)
+		return id, false
+	}
+	return id, true
+}
+
+// toCustomerData converts the Customer received from the API into the Customer stored in the database
+func toCustomerData(customerDTO *dto.Customer) data.Customer {
+	return data.Customer{
+		Name:    customerDTO.Name,
+		Balance: customerDTO.Balance,
+	}
+}
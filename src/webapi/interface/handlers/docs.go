@@ -16,8 +16,12 @@
 package handlers
 
 import (
+	"github.com/serdarkalayci/goboiler/webapi/bundle"
 	"github.com/serdarkalayci/goboiler/webapi/data"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"github.com/serdarkalayci/goboiler/webapi/interface/analytics"
+	"github.com/serdarkalayci/goboiler/webapi/interface/health"
 )
 
 //
@@ -45,6 +49,14 @@ type errorValidationWrapper struct {
 	Body ValidationError
 }
 
+// Health of the api and of its dependencies
+// swagger:response HealthResponse
+type healthResponseWrapper struct {
+	// Status of the api and of each component
+	// in: body
+	Body health.Report
+}
+
 // A list of products
 // swagger:response ProductsResponse
 type productsResponseWrapper struct {
@@ -61,15 +73,425 @@ type productResponseWrapper struct {
 	Body data.Product
 }
 
+// A list of webhook subscriptions
+// swagger:response SubscriptionsResponse
+type subscriptionsResponseWrapper struct {
+	// All current subscriptions
+	// in: body
+	Body []data.Subscription
+}
+
+// Data structure representing a single webhook subscription
+// swagger:response SubscriptionResponse
+type subscriptionResponseWrapper struct {
+	// The subscription
+	// in: body
+	Body data.Subscription
+}
+
+// The delivery log of a webhook subscription
+// swagger:response DeliveriesResponse
+type deliveriesResponseWrapper struct {
+	// The deliveries, newest first
+	// in: body
+	Body []data.Delivery
+}
+
+// A list of API keys
+// swagger:response APIKeysResponse
+type apiKeysResponseWrapper struct {
+	// All API keys, without the keys themselves
+	// in: body
+	Body []data.APIKey
+}
+
+// Data structure representing a newly issued API key
+// swagger:response IssuedAPIKeyResponse
+type issuedAPIKeyResponseWrapper struct {
+	// The id and the key
+	// in: body
+	Body dto.IssuedAPIKey
+}
+
+// The audit log
+// swagger:response AuditEntriesResponse
+type auditEntriesResponseWrapper struct {
+	// The audit entries, newest first
+	// in: body
+	Body []data.AuditEntry
+}
+
+// The revisions of a product
+// swagger:response ProductRevisionsResponse
+type productRevisionsResponseWrapper struct {
+	// The revisions, newest first
+	// in: body
+	Body []data.ProductRevision
+}
+
+// Data structure representing a single revision of a product
+// swagger:response ProductRevisionResponse
+type productRevisionResponseWrapper struct {
+	// The revision
+	// in: body
+	Body data.ProductRevision
+}
+
+// The fields which differ between two states of a document
+// swagger:response FieldChangesResponse
+type fieldChangesResponseWrapper struct {
+	// The changed fields
+	// in: body
+	Body []data.FieldChange
+}
+
+// A list of environments
+// swagger:response EnvironmentsResponse
+type environmentsResponseWrapper struct {
+	// All environments in their promotion order
+	// in: body
+	Body []data.Environment
+}
+
+// Data structure representing a single environment
+// swagger:response EnvironmentResponse
+type environmentResponseWrapper struct {
+	// The environment
+	// in: body
+	Body data.Environment
+}
+
+// The outcome of evaluating the features of a product
+// swagger:response EvaluationResponse
+type evaluationResponseWrapper struct {
+	// The outcome of every feature, keyed by the feature code
+	// in: body
+	Body map[string]evaluation.Result
+}
+
+// The dependency graph of the features of a product
+// swagger:response DependencyGraphResponse
+type dependencyGraphResponseWrapper struct {
+	// The features and their dependencies
+	// in: body
+	Body evaluation.Graph
+}
+
+// The features of a product which are likely not needed anymore
+// swagger:response StaleFeaturesResponse
+type staleFeaturesResponseWrapper struct {
+	// The stale features
+	// in: body
+	Body []analytics.StaleFeature
+}
+
+// The products and environments exported as a bundle
+// swagger:response BundleResponse
+type bundleResponseWrapper struct {
+	// The bundle
+	// in: body
+	Body bundle.Bundle
+}
+
+// The outcome of importing a bundle
+// swagger:response ImportReportResponse
+type importReportResponseWrapper struct {
+	// The actions taken for every product and environment of the bundle
+	// in: body
+	Body bundle.Report
+}
+
+// A list of customers
+// swagger:response CustomersResponse
+type customersResponseWrapper struct {
+	// All current customers
+	// in: body
+	Body []data.Customer
+}
+
+// Data structure representing a single customer
+// swagger:response CustomerResponse
+type customerResponseWrapper struct {
+	// The customer
+	// in: body
+	Body data.Customer
+}
+
+// A list of items
+// swagger:response ItemsResponse
+type itemsResponseWrapper struct {
+	// All current items
+	// in: body
+	Body []data.Item
+}
+
+// Data structure representing a single item
+// swagger:response ItemResponse
+type itemResponseWrapper struct {
+	// The item
+	// in: body
+	Body data.Item
+}
+
+// A list of orders
+// swagger:response OrdersResponse
+type ordersResponseWrapper struct {
+	// The orders of the customer
+	// in: body
+	Body []data.Order
+}
+
+// Data structure representing a single order
+// swagger:response OrderResponse
+type orderResponseWrapper struct {
+	// The order
+	// in: body
+	Body data.Order
+}
+
 // No content is returned by this API endpoint
 // swagger:response noContentResponse
 type noContentResponseWrapper struct {
 }
 
-// swagger:parameters updateDetail
+// swagger:parameters getSingleProduct updateProduct deleteProduct restoreProduct evaluateProduct promoteProduct getProductGraph getStaleFeatures getProductRevisions getProductRevision diffProductRevisions rollbackProduct
 type productIDParamsWrapper struct {
 	// The id of the product for which the operation relates
 	// in: path
 	// required: true
-	ID primitive.ObjectID `json:"id"`
+	ID string `json:"id"`
+}
+
+// swagger:parameters addProduct updateProduct
+type productParamsWrapper struct {
+	// The product with its features
+	// in: body
+	// required: true
+	Body dto.Product
+}
+
+// swagger:parameters getAllProducts getSingleProduct
+type includeDeletedParamsWrapper struct {
+	// Whether the deleted products are returned too, allowed to the callers who can manage the deleted products
+	// in: query
+	IncludeDeleted bool `json:"includeDeleted"`
+}
+
+// swagger:parameters getStaleFeatures
+type staleFeaturesParamsWrapper struct {
+	// The number of days a feature must not be evaluated for to be stale, 30 by default
+	// in: query
+	Days int `json:"days"`
+}
+
+// swagger:parameters evaluateProduct
+type evaluationParamsWrapper struct {
+	// The environment and the attributes to evaluate the features for
+	// in: body
+	// required: true
+	Body dto.Evaluation
+
+	// The environment to evaluate the features for, overriding the environment in the body
+	// in: query
+	Environment string `json:"environment"`
+}
+
+// swagger:parameters promoteProduct
+type promoteParamsWrapper struct {
+	// The key of the environment the configuration is copied to
+	// in: path
+	// required: true
+	Key string `json:"key"`
+
+	// The key of the environment the configuration is copied from
+	// in: query
+	// required: true
+	From string `json:"from"`
+}
+
+// swagger:parameters getProductRevision rollbackProduct
+type revisionParamsWrapper struct {
+	// The number of the revision
+	// in: path
+	// required: true
+	Revision int64 `json:"revision"`
+}
+
+// swagger:parameters diffProductRevisions
+type revisionDiffParamsWrapper struct {
+	// The number of the older revision
+	// in: query
+	// required: true
+	From int64 `json:"from"`
+
+	// The number of the newer revision
+	// in: query
+	// required: true
+	To int64 `json:"to"`
+}
+
+// swagger:parameters exportProducts importProducts
+type bundleFormatParamsWrapper struct {
+	// The format of the bundle, yaml or json. The Accept or the Content-Type header is used if it's not given
+	// in: query
+	Format string `json:"format"`
+}
+
+// swagger:parameters importProducts
+type importParamsWrapper struct {
+	// The bundle to import
+	// in: body
+	// required: true
+	Body bundle.Bundle
+
+	// Whether the products are matched by name and created with new ids
+	// in: query
+	Remap bool `json:"remap"`
+
+	// Whether only the report of what would change is returned
+	// in: query
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:parameters addEnvironment
+type environmentParamsWrapper struct {
+	// The environment
+	// in: body
+	// required: true
+	Body dto.Environment
+}
+
+// swagger:parameters deleteEnvironment
+type environmentKeyParamsWrapper struct {
+	// The key of the environment
+	// in: path
+	// required: true
+	Key string `json:"key"`
+}
+
+// swagger:parameters getAuditEntries
+type auditParamsWrapper struct {
+	// The kind of the changed resources, such as products
+	// in: query
+	Resource string `json:"resource"`
+
+	// The id of the changed resource
+	// in: query
+	ResourceID string `json:"resourceId"`
+
+	// The subject of the caller who made the changes
+	// in: query
+	Actor string `json:"actor"`
+
+	// The start of the time range in RFC 3339 format
+	// in: query
+	From string `json:"from"`
+
+	// The end of the time range in RFC 3339 format
+	// in: query
+	To string `json:"to"`
+
+	// The maximum number of entries returned, between 1 and 1000. Defaults to 100
+	// in: query
+	Limit int64 `json:"limit"`
+}
+
+// swagger:parameters issueAPIKey
+type apiKeyParamsWrapper struct {
+	// The name and the roles of the API key
+	// in: body
+	// required: true
+	Body dto.APIKey
+}
+
+// swagger:parameters revokeAPIKey rotateAPIKey
+type apiKeyIDParamsWrapper struct {
+	// The id of the API key
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// swagger:parameters addSubscription
+type subscriptionParamsWrapper struct {
+	// The url and the events of the subscription
+	// in: body
+	// required: true
+	Body dto.Subscription
+}
+
+// swagger:parameters getSingleSubscription deleteSubscription getDeliveries redeliver
+type subscriptionIDParamsWrapper struct {
+	// The id of the subscription
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// swagger:parameters redeliver
+type deliveryIDParamsWrapper struct {
+	// The id of the delivery
+	// in: path
+	// required: true
+	DeliveryID string `json:"deliveryId"`
+}
+
+// swagger:parameters addCustomer updateCustomer
+type customerParamsWrapper struct {
+	// The customer
+	// in: body
+	// required: true
+	Body dto.Customer
+}
+
+// swagger:parameters getSingleCustomer updateCustomer deleteCustomer getOrders addOrder getSingleOrder addOrderItem removeOrderItem placeOrder
+type customerIDParamsWrapper struct {
+	// The id of the customer
+	// in: path
+	// required: true
+	ID string `json:"id"`
+}
+
+// swagger:parameters getSingleOrder addOrderItem removeOrderItem placeOrder
+type orderIDParamsWrapper struct {
+	// The id of the order
+	// in: path
+	// required: true
+	OrderID string `json:"orderId"`
+}
+
+// swagger:parameters addOrderItem
+type orderItemParamsWrapper struct {
+	// The item and the number of it added to the order
+	// in: body
+	// required: true
+	Body dto.OrderItem
+}
+
+// swagger:parameters removeOrderItem
+type removeOrderItemParamsWrapper struct {
+	// The id of the item
+	// in: path
+	// required: true
+	ItemID string `json:"itemId"`
+
+	// The number of the item removed from the order, 1 by default
+	// in: query
+	Count int `json:"count"`
+}
+
+// swagger:parameters addItem updateItem
+type itemParamsWrapper struct {
+	// The item
+	// in: body
+	// required: true
+	Body dto.Item
+}
+
+// swagger:parameters getSingleItem updateItem
+type itemIDParamsWrapper struct {
+	// The id of the item
+	// in: path
+	// required: true
+	ID string `json:"id"`
 }
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
+)
+
+// Names of the audited resource and action of environments
+const (
+	auditEnvironments = "environments"
+	auditPromote      = "promote"
+)
+
+// AddEnvironment adds a new environment
+// swagger:route POST /environments Environments addEnvironment
+// Create a new Environment the features can be configured for
+// responses:
+//	201: EnvironmentResponse
+//	409: errorResponse
+//	422: errorValidation
+// AddEnvironment handles POST requests
+func (ctx *DBContext) AddEnvironment(rw http.ResponseWriter, r *http.Request) {
+	environmentDTO := r.Context().Value(KeyEnvironment{}).(*dto.Environment)
+	environment, err := data.AddEnvironment(r.Context(), data.Environment{
+		Key:   environmentDTO.Key,
+		Name:  environmentDTO.Name,
+		Order: environmentDTO.Order,
+	}, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err == data.ErrEnvironmentExists {
+		rw.WriteHeader(http.StatusConflict)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error adding Environment")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditCreate, auditEnvironments, environment.Key, nil, environment)
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON(environment, rw)
+}
+
+// GetAllEnvironments gets all environments
+// swagger:route GET /environments Environments getAllEnvironments
+// Return a list of Environment in their promotion order
+// responses:
+//	200: EnvironmentsResponse
+//	500: errorResponse
+// GetAllEnvironments handles GET requests
+func (ctx *DBContext) GetAllEnvironments(rw http.ResponseWriter, r *http.Request) {
+	environments, err := data.GetEnvironments(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Environments")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(environments, rw)
+}
+
+// DeleteEnvironment removes an environment
+// swagger:route DELETE /environments/{key} Environments deleteEnvironment
+// Remove the Environment, keeping the configuration of the features in it
+// responses:
+//	204: noContentResponse
+//	404: errorResponse
+// DeleteEnvironment handles DELETE requests
+func (ctx *DBContext) DeleteEnvironment(rw http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	before, err := data.GetEnvironmentByKey(r.Context(), key, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err == nil {
+		err = data.DeleteEnvironment(r.Context(), key, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err == data.ErrEnvironmentNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error deleting Environment")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditDelete, auditEnvironments, key, before, nil)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// EvaluateProduct evaluates the features of a product
+// swagger:route POST /products/{id}/evaluate Products evaluateProduct
+// Return the outcome of every feature of the Product for the given environment and attributes, keyed by the feature code.
+// The environment query parameter overrides the environment in the body
+// responses:
+//	200: EvaluationResponse
+//	404: errorResponse
+//	422: errorValidation
+//	500: errorResponse
+// EvaluateProduct handles POST requests
+func (ctx *DBContext) EvaluateProduct(rw http.ResponseWriter, r *http.Request) {
+	evaluationDTO := r.Context().Value(KeyEvaluation{}).(*dto.Evaluation)
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	product, err := data.GetProductByID(r.Context(), id, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Product")
+
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	source := ctx.features(r)
+	results := evaluation.Evaluate(*product, evaluation.Context{
+		Environment: evaluationDTO.Environment,
+		Attributes:  evaluationDTO.Attributes,
+		Features:    source,
+	})
+	if !source.ok(rw, r) {
+		return
+	}
+	if ctx.Analytics != nil {
+		ctx.Analytics.Record(tenant(r), product.ID, results)
+	}
+	data.ToJSON(results, rw)
+}
+
+// PromoteProduct copies the configuration of the features of a product from one environment to another
+// swagger:route POST /products/{id}/environments/{key}/promote Products promoteProduct
+// Copy the configuration of every feature of the Product in the environment given with the from query parameter to the environment in the URL
+// responses:
+//	200: ProductResponse
+//	404: errorResponse
+//	409: errorResponse
+// PromoteProduct handles POST requests
+func (ctx *DBContext) PromoteProduct(rw http.ResponseWriter, r *http.Request) {
+	source, target := r.URL.Query().Get("from"), mux.Vars(r)["key"]
+	for _, key := range []string{source, target} {
+		if _, err := data.GetEnvironmentByKey(r.Context(), key, tenant(r), ctx.MongoClient, ctx.DatabaseName); err != nil {
+			rw.WriteHeader(http.StatusNotFound)
+			data.ToJSON(&GenericError{Message: fmt.Sprintf("%s: '%s'", err.Error(), key)}, rw)
+			return
+		}
+	}
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	before, err := data.GetProductByID(r.Context(), id, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var product *data.Product
+	if err == nil {
+		promoted := *before
+		promoted.Features = copyFeatures(before.Features)
+		evaluation.Promote(&promoted, source, target)
+		product, err = data.UpdateProduct(r.Context(), promoted, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err == data.ErrProductNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err == data.ErrProductConflict {
+		rw.WriteHeader(http.StatusConflict)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error promoting Product")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.recordRevision(r, product)
+	ctx.audit(r, auditPromote, auditProducts, product.ID.Hex(), before, product)
+	ctx.publish(r, webhook.EventProductUpdated, product)
+	data.ToJSON(product, rw)
+}
+
+// checkEnvironments checks if every environment the features of the product are configured for exists,
+// writing a 422 response if there's an unknown one
+func (ctx *DBContext) checkEnvironments(rw http.ResponseWriter, r *http.Request, product *dto.Product) bool {
+	messages := environmentErrors(product, func(key string) error {
+		_, err := data.GetEnvironmentByKey(r.Context(), key, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+		return err
+	})
+	if len(messages) > 0 {
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+		data.ToJSON(&ValidationError{Messages: messages}, rw)
+		return false
+	}
+	return true
+}
+
+// environmentErrors returns the messages for the environments the features of the product are configured in
+// which the lookup returns an error for, looking up each environment only once
+func environmentErrors(product *dto.Product, lookup func(key string) error) []string {
+	messages := []string{}
+	checked := map[string]bool{}
+	for _, f := range product.Features {
+		for key := range f.Environments {
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+			if err := lookup(key); err != nil {
+				messages = append(messages, fmt.Sprintf("Key: 'Product.Features.Environments[%s]' Error: %s", key, err.Error()))
+			}
+		}
+	}
+	return messages
+}
+
+// copyFeatures returns a copy of the features whose environments can be changed without changing the originals
+func copyFeatures(features []data.Feature) []data.Feature {
+	copied := make([]data.Feature, len(features))
+	for i, f := range features {
+		copied[i] = f
+		copied[i].Environments = map[string]data.FeatureEnvironment{}
+		for key, env := range f.Environments {
+			copied[i].Environments[key] = env
+		}
+	}
+	return copied
+}
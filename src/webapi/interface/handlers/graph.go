@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/evaluation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetProductGraph gets the dependency graph of the features of a product
+// swagger:route GET /products/{id}/graph Products getProductGraph
+// Return the features of the Product and every feature they depend on through their prerequisites, possibly of other products
+// responses:
+//	200: DependencyGraphResponse
+//	404: errorResponse
+//	500: errorResponse
+// GetProductGraph handles GET requests
+func (ctx *DBContext) GetProductGraph(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	product, err := data.GetProductByID(r.Context(), id, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Product")
+
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	source := ctx.features(r)
+	graph := evaluation.DependencyGraph(*product, source)
+	if !source.ok(rw, r) {
+		return
+	}
+	data.ToJSON(graph, rw)
+}
+
+// checkPrerequisites checks if the prerequisites of the features of the product exist and don't form a cycle,
+// writing a 422 response if they don't, or a 500 response if the products they refer to can't be read
+func (ctx *DBContext) checkPrerequisites(rw http.ResponseWriter, r *http.Request, product data.Product) bool {
+	messages := []string{}
+	source := ctx.features(r)
+	for _, id := range evaluation.MissingPrerequisites(product, source) {
+		messages = append(messages, fmt.Sprintf("Key: 'Product.Features.Prerequisites' Error: Feature '%s' does not exist", id))
+	}
+	if cycle := evaluation.FindCycle(product, source); cycle != nil {
+		messages = append(messages, fmt.Sprintf("Key: 'Product.Features.Prerequisites' Error: Prerequisites form a cycle: %s", strings.Join(cycle, " -> ")))
+	}
+	if !source.ok(rw, r) {
+		return false
+	}
+	if len(messages) > 0 {
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+		data.ToJSON(&ValidationError{Messages: messages}, rw)
+		return false
+	}
+	return true
+}
+
+// features returns the source the features of the prerequisites are read from for the tenant of the request
+func (ctx *DBContext) features(r *http.Request) *featureSource {
+	return &featureSource{ctx: ctx, request: r, tenant: tenant(r), products: map[primitive.ObjectID]*data.Product{}}
+}
+
+// featureSource reads the features from the database, getting each product only once.
+// The features of a product which can't be read are treated as missing, and the error is kept to be checked with ok
+type featureSource struct {
+	ctx      *DBContext
+	request  *http.Request
+	tenant   data.Tenant
+	products map[primitive.ObjectID]*data.Product
+	err      error
+}
+
+func (s *featureSource) GetFeature(productID primitive.ObjectID, code string) (*data.Feature, bool) {
+	product, ok := s.products[productID]
+	if !ok {
+		var err error
+		product, err = data.GetProductByID(s.request.Context(), productID, false, s.tenant, s.ctx.MongoClient, s.ctx.DatabaseName)
+		if err != nil && err != data.ErrProductNotFound && s.err == nil {
+			s.err = err
+		}
+		s.products[productID] = product
+	}
+	if product == nil {
+		return nil, false
+	}
+	for i := range product.Features {
+		if product.Features[i].Code == code {
+			return &product.Features[i], true
+		}
+	}
+	return nil, false
+}
+
+// ok checks if every product the features have been read from could be read, writing a 500 response if one couldn't
+func (s *featureSource) ok(rw http.ResponseWriter, r *http.Request) bool {
+	if s.err == nil {
+		return true
+	}
+	log.Ctx(r.Context()).Error().Err(s.err).Msg("Error getting the Products of the prerequisites")
+
+	rw.WriteHeader(http.StatusInternalServerError)
+	data.ToJSON(&GenericError{Message: s.err.Error()}, rw)
+	return false
+}
@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_PrerequisiteProductError(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("graph", func(mt *mtest.T) {
+		billing := primitive.NewObjectID()
+		product := data.Product{
+			ID:   primitive.NewObjectID(),
+			Name: "Shop",
+			Features: []data.Feature{
+				{Code: "express", Prerequisites: []data.Prerequisite{{ProductID: &billing, Code: "payments"}}},
+			},
+		}
+		raw, _ := bson.Marshal(product)
+		doc := bson.D{}
+		bson.Unmarshal(raw, &doc)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, doc),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted at shutdown"}),
+		)
+		ctx := &handlers.DBContext{MongoClient: *mt.Client, DatabaseName: "goboiler"}
+		router := mux.NewRouter()
+		router.HandleFunc("/products/{id}/graph", ctx.GetProductGraph)
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/products/"+product.ID.Hex()+"/graph", nil))
+		if rw.Code != http.StatusInternalServerError {
+			t.Errorf("Error getting the graph when a prerequisite product can't be read. Expected status 500, got %d", rw.Code)
+		}
+	})
+}
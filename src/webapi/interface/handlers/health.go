@@ -5,6 +5,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/health"
 )
 
 // swagger:route GET /health/live Health Live
@@ -19,17 +20,32 @@ func (ctx *APIContext) Live(rw http.ResponseWriter, r *http.Request) {
 }
 
 // swagger:route GET /health/ready Health Ready
-// Return 200 if the api is up and running and connected to the database
+// Return 200 if the api is up and running and all of its dependencies are up, or 503 with the components which are down
 // responses:
-//	200: OK
-//	404: errorResponse
+//	200: HealthResponse
+//	503: HealthResponse
 
 // Ready handles GET requests
 func (ctx *DBContext) Ready(rw http.ResponseWriter, r *http.Request) {
-	err := data.GetHealth(ctx.MongoClient, ctx.DatabaseName)
-	if err != nil {
-		log.Error().Err(err).Msg("Error connecting to database")
-		rw.WriteHeader(http.StatusInternalServerError)
+	report := ctx.Health.Run(r.Context())
+	if report.Status != health.StatusUp {
+		log.Ctx(r.Context()).Warn().Interface("components", report.Components).Msg("Service is not ready")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	data.ToJSON(report, rw)
+}
+
+// swagger:route GET /health/startup Health Startup
+// Return 200 once all of the dependencies of the api have been up, 503 until then
+// responses:
+//	200: OK
+//	503: errorResponse
+
+// Startup handles GET requests
+func (ctx *DBContext) Startup(rw http.ResponseWriter, r *http.Request) {
+	if !ctx.Health.Started() && ctx.Health.Run(r.Context()).Status != health.StatusUp {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		data.ToJSON(&GenericError{Message: "Service has not started yet"}, rw)
 		return
 	}
 	rw.WriteHeader(http.StatusOK)
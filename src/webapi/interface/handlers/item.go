@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAllItems gets all items
+// swagger:route GET /items Items getAllItems
+// Return a list of Item from the database
+// responses:
+//	200: ItemsResponse
+//	500: errorResponse
+// GetAllItems handles GET requests
+func (ctx *DBContext) GetAllItems(rw http.ResponseWriter, r *http.Request) {
+	items, err := data.GetItems(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Items")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(items, rw)
+}
+
+// GetSingleItem gets a single item
+// swagger:route GET /items/{id} Items getSingleItem
+// Return the Item which matches the id
+// responses:
+//	200: ItemResponse
+//	404: errorResponse
+// GetSingleItem handles GET requests
+func (ctx *DBContext) GetSingleItem(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getItemID(rw, r)
+	if !ok {
+		return
+	}
+	item, err := data.GetItemByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		writeItemError(rw, r, err)
+		return
+	}
+	data.ToJSON(item, rw)
+}
+
+// AddItem adds a new item
+// swagger:route POST /items Items addItem
+// Create a new Item in the database
+// responses:
+//	201: ItemResponse
+//	422: errorValidation
+//	500: errorResponse
+// AddItem handles POST requests
+func (ctx *DBContext) AddItem(rw http.ResponseWriter, r *http.Request) {
+	itemDTO := r.Context().Value(KeyItem{}).(*dto.Item)
+	item, err := data.AddItem(r.Context(), toItemData(itemDTO), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		writeItemError(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditCreate, auditItems, item.ID.Hex(), nil, item)
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON(item, rw)
+}
+
+// UpdateItem replaces an item
+// swagger:route PUT /items/{id} Items updateItem
+// Update the Item in the database
+// responses:
+//	200: ItemResponse
+//	404: errorResponse
+//	422: errorValidation
+// UpdateItem handles PUT requests
+func (ctx *DBContext) UpdateItem(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getItemID(rw, r)
+	if !ok {
+		return
+	}
+	itemDTO := r.Context().Value(KeyItem{}).(*dto.Item)
+	updated := toItemData(itemDTO)
+	updated.ID = id
+	before, err := data.GetItemByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var item *data.Item
+	if err == nil {
+		item, err = data.UpdateItem(r.Context(), updated, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		writeItemError(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditUpdate, auditItems, item.ID.Hex(), before, item)
+	data.ToJSON(item, rw)
+}
+
+// writeItemError writes the response of an error raised while handling an item
+func writeItemError(rw http.ResponseWriter, r *http.Request, err error) {
+	if err == data.ErrItemNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	log.Ctx(r.Context()).Error().Err(err).Msg("Error handling Item")
+
+	rw.WriteHeader(http.StatusInternalServerError)
+	data.ToJSON(&GenericError{Message: err.Error()}, rw)
+}
+
+// getItemID returns the id of the item from the URL, writing a 404 response if it's not a valid id
+func getItemID(rw http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	id, err := getObjectID(r, "id")
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: data.ErrItemNotFound.Error()}, rw)
+		return id, false
+	}
+	return id, true
+}
+
+// toItemData converts the Item received from the API into the Item stored in the database
+func toItemData(itemDTO *dto.Item) data.Item {
+	return data.Item{
+		Name:       itemDTO.Name,
+		Price:      itemDTO.Price,
+		StockCount: itemDTO.StockCount,
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	"github.com/rs/zerolog/log"
 	"github.com/serdarkalayci/goboiler/webapi/data"
@@ -12,24 +13,96 @@ import (
 // KeyProduct is a key used carrying the Product object within the context, just to avoid deserializing it multiple times
 type KeyProduct struct{}
 
+// KeySubscription is a key used carrying the Subscription object within the context
+type KeySubscription struct{}
+
+// KeyAPIKey is a key used carrying the APIKey object within the context
+type KeyAPIKey struct{}
+
+// KeyEnvironment is a key used carrying the Environment object within the context
+type KeyEnvironment struct{}
+
+// KeyEvaluation is a key used carrying the Evaluation object within the context
+type KeyEvaluation struct{}
+
+// KeyCustomer is a key used carrying the Customer object within the context
+type KeyCustomer struct{}
+
+// KeyItem is a key used carrying the Item object within the context
+type KeyItem struct{}
+
+// KeyOrderItem is a key used carrying the OrderItem object within the context
+type KeyOrderItem struct{}
+
 // MiddlewareValidateNewProduct Product new book product in the request and calls next if ok
 func (apiContext *APIContext) MiddlewareValidateNewProduct(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "product", KeyProduct{}, func() interface{} { return &dto.Product{} })
+}
+
+// MiddlewareValidateNewSubscription validates the new webhook subscription in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNewSubscription(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "subscription", KeySubscription{}, func() interface{} { return &dto.Subscription{} })
+}
+
+// MiddlewareValidateNewAPIKey validates the new API key in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNewAPIKey(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "API key", KeyAPIKey{}, func() interface{} { return &dto.APIKey{} })
+}
+
+// MiddlewareValidateNewEnvironment validates the new environment in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNewEnvironment(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "environment", KeyEnvironment{}, func() interface{} { return &dto.Environment{} })
+}
+
+// MiddlewareValidateEvaluation validates the evaluation request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateEvaluation(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "evaluation", KeyEvaluation{}, func() interface{} { return &dto.Evaluation{} })
+}
+
+// MiddlewareValidateNewCustomer validates the new customer in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNewCustomer(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "customer", KeyCustomer{}, func() interface{} { return &dto.Customer{} })
+}
+
+// MiddlewareValidateNewItem validates the new item in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNewItem(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "item", KeyItem{}, func() interface{} { return &dto.Item{} })
+}
+
+// MiddlewareValidateNewOrderItem validates the item to be added to an order in the request and calls next if ok
+func (apiContext *APIContext) MiddlewareValidateNewOrderItem(next http.Handler) http.Handler {
+	return apiContext.validateBody(next, "order item", KeyOrderItem{}, func() interface{} { return &dto.OrderItem{} })
+}
+
+// queryApplier is implemented by the request bodies which can be overridden with the query parameters
+type queryApplier interface {
+	ApplyQuery(query url.Values)
+}
+
+// validateBody deserializes the request body into the object created by newBody, applies the query parameters to it, validates it
+// and calls next with the object added to the context with the given key
+func (apiContext *APIContext) validateBody(next http.Handler, name string, key interface{}, newBody func() interface{}) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-		product := &dto.Product{}
+		body := newBody()
 
-		err := data.FromJSON(product, r.Body)
+		err := data.FromJSON(body, r.Body)
 		if err != nil {
-			log.Error().Err(err).Msg("Error deserializing book product")
+			log.Ctx(r.Context()).Error().Err(err).Msgf("Error deserializing %s", name)
 
 			rw.WriteHeader(http.StatusBadRequest)
 			data.ToJSON(&GenericError{Message: err.Error()}, rw)
 			return
 		}
 
-		// validate the product
-		errs := apiContext.v.Validate(product)
+		// let the query parameters override the body before it's validated
+		if q, ok := body.(queryApplier); ok {
+			q.ApplyQuery(r.URL.Query())
+		}
+
+		// validate the body
+		errs := apiContext.v.Validate(body)
 		if len(errs) != 0 {
-			log.Error().Err(errs[0]).Msg("Error validating book product")
+			log.Ctx(r.Context()).Error().Err(errs[0]).Msgf("Error validating %s", name)
 
 			// return the validation messages as an array
 			rw.WriteHeader(http.StatusUnprocessableEntity)
@@ -37,8 +110,8 @@ func (apiContext *APIContext) MiddlewareValidateNewProduct(next http.Handler) ht
 			return
 		}
 
-		// add the rating to the context
-		ctx := context.WithValue(r.Context(), KeyProduct{}, product)
+		// add the body to the context
+		ctx := context.WithValue(r.Context(), key, body)
 		r = r.WithContext(ctx)
 
 		// Call the next handler, which can be another middleware in the chain, or the final handler.
@@ -0,0 +1,39 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+)
+
+func Test_ValidateEvaluationEnvironmentQuery(t *testing.T) {
+	ctx := handlers.NewAPIContext(dto.NewValidation())
+	var evaluation *dto.Evaluation
+	handler := ctx.MiddlewareValidateEvaluation(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		evaluation = r.Context().Value(handlers.KeyEvaluation{}).(*dto.Evaluation)
+	}))
+	tests := []struct {
+		path, body, environment string
+		status                  int
+	}{
+		{"/evaluate", `{"attributes":{"country":"NL"}}`, "", http.StatusUnprocessableEntity},
+		{"/evaluate?environment=prod", `{"attributes":{"country":"NL"}}`, "prod", http.StatusOK},
+		{"/evaluate?environment=prod", `{"environment":"dev"}`, "prod", http.StatusOK},
+		{"/evaluate", `{"environment":"dev"}`, "dev", http.StatusOK},
+	}
+	for _, test := range tests {
+		evaluation = nil
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, test.path, bytes.NewBufferString(test.body)))
+		if rw.Code != test.status {
+			t.Errorf("Error validating the evaluation %s on %s. Expected status %d, got %d", test.body, test.path, test.status, rw.Code)
+		}
+		if test.status == http.StatusOK && (evaluation == nil || evaluation.Environment != test.environment) {
+			t.Errorf("Error validating the evaluation %s on %s. Expected environment %s, got %v", test.body, test.path, test.environment, evaluation)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/domain"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"github.com/serdarkalayci/goboiler/webapi/interface/shop"
+	"github.com/serdarkalayci/goboiler/webapi/usecases"
+)
+
+// GetOrders gets the orders of a customer
+// swagger:route GET /customers/{id}/orders Orders getOrders
+// Return the list of Order of the Customer, the latest first
+// responses:
+//	200: OrdersResponse
+//	404: errorResponse
+//	500: errorResponse
+// GetOrders handles GET requests
+func (ctx *DBContext) GetOrders(rw http.ResponseWriter, r *http.Request) {
+	customerID, ok := getCustomerID(rw, r)
+	if !ok {
+		return
+	}
+	_, err := data.GetCustomerByID(r.Context(), customerID, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		writeOrderError(rw, r, err)
+		return
+	}
+	orders, err := data.GetOrders(r.Context(), customerID, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		writeOrderError(rw, r, err)
+		return
+	}
+	data.ToJSON(orders, rw)
+}
+
+// GetSingleOrder gets a single order of a customer
+// swagger:route GET /customers/{id}/orders/{orderId} Orders getSingleOrder
+// Return the Order of the Customer which matches the id
+// responses:
+//	200: OrderResponse
+//	404: errorResponse
+// GetSingleOrder handles GET requests
+func (ctx *DBContext) GetSingleOrder(rw http.ResponseWriter, r *http.Request) {
+	order, ok := ctx.getOrder(rw, r)
+	if !ok {
+		return
+	}
+	data.ToJSON(order, rw)
+}
+
+// AddOrder starts a new order
+// swagger:route POST /customers/{id}/orders Orders addOrder
+// Create a new empty Order for the Customer, which items can be added to until it's placed
+// responses:
+//	201: OrderResponse
+//	404: errorResponse
+//	500: errorResponse
+// AddOrder handles POST requests
+func (ctx *DBContext) AddOrder(rw http.ResponseWriter, r *http.Request) {
+	customerID, ok := getCustomerID(rw, r)
+	if !ok {
+		return
+	}
+	_, err := data.GetCustomerByID(r.Context(), customerID, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	var order *data.Order
+	if err == nil {
+		order, err = data.AddOrder(r.Context(), data.Order{CustomerID: customerID}, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		writeOrderError(rw, r, err)
+		return
+	}
+	ctx.audit(r, auditCreate, auditOrders, order.ID.Hex(), nil, order)
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON(order, rw)
+}
+
+// AddOrderItem adds items to an order
+// swagger:route POST /customers/{id}/orders/{orderId}/items Orders addOrderItem
+// Add the given count of the Item to the Order, taking them from the stock and charging the balance of the Customer
+// responses:
+//	200: OrderResponse
+//	404: errorResponse
+//	409: errorResponse
+//	422: errorValidation
+// AddOrderItem handles POST requests
+func (ctx *DBContext) AddOrderItem(rw http.ResponseWriter, r *http.Request) {
+	orderItem := r.Context().Value(KeyOrderItem{}).(*dto.OrderItem)
+	ctx.changeOrder(rw, r, auditUpdate, func(operator *usecases.OrderOperator, orderID, customerID string) error {
+		return operator.AddProduct(r.Context(), orderID, customerID, orderItem.ItemID, orderItem.Count)
+	})
+}
+
+// RemoveOrderItem removes items from an order
+// swagger:route DELETE /customers/{id}/orders/{orderId}/items/{itemId} Orders removeOrderItem
+// Remove the count of the Item given in the count query parameter, 1 by default, from the Order, putting them back
+// to the stock and refunding the Customer
+// responses:
+//	200: OrderResponse
+//	400: errorResponse
+//	404: errorResponse
+//	409: errorResponse
+// RemoveOrderItem handles DELETE requests
+func (ctx *DBContext) RemoveOrderItem(rw http.ResponseWriter, r *http.Request) {
+	count := 1
+	if c := r.URL.Query().Get("count"); c != "" {
+		var err error
+		count, err = strconv.Atoi(c)
+		if err != nil || count < 1 {
+			rw.WriteHeader(http.StatusBadRequest)
+			data.ToJSON(&GenericError{Message: "count should be a positive number"}, rw)
+			return
+		}
+	}
+	ctx.changeOrder(rw, r, auditUpdate, func(operator *usecases.OrderOperator, orderID, customerID string) error {
+		return operator.RemoveProduct(r.Context(), orderID, customerID, mux.Vars(r)["itemId"], count)
+	})
+}
+
+// PlaceOrder places an order
+// swagger:route POST /customers/{id}/orders/{orderId}/place Orders placeOrder
+// Place the Order, after which it can't be changed, and publish the order.placed event
+// responses:
+//	200: OrderResponse
+//	404: errorResponse
+//	409: errorResponse
+// PlaceOrder handles POST requests
+func (ctx *DBContext) PlaceOrder(rw http.ResponseWriter, r *http.Request) {
+	ctx.changeOrder(rw, r, auditPlace, func(operator *usecases.OrderOperator, orderID, customerID string) error {
+		_, err := operator.PlaceOrder(r.Context(), orderID, customerID)
+		return err
+	})
+}
+
+// changeOrder makes the change to the order in the URL with the use cases of the orders, then audits the change
+// with the given action and writes the changed order
+func (ctx *DBContext) changeOrder(rw http.ResponseWriter, r *http.Request, action string, change func(operator *usecases.OrderOperator, orderID, customerID string) error) {
+	before, ok := ctx.getOrder(rw, r)
+	if !ok {
+		return
+	}
+	err := change(ctx.orderOperator(r), before.ID.Hex(), before.CustomerID.Hex())
+	if err != nil {
+		writeOrderError(rw, r, err)
+		return
+	}
+	order, ok := ctx.getOrder(rw, r)
+	if !ok {
+		return
+	}
+	ctx.audit(r, action, auditOrders, order.ID.Hex(), before, order)
+	data.ToJSON(order, rw)
+}
+
+// orderOperator returns the use cases of the orders working on the data of the tenant of the request,
+// publishing their events to the webhook subscribers of the tenant if webhooks are configured
+func (ctx *DBContext) orderOperator(r *http.Request) *usecases.OrderOperator {
+	t := tenant(r)
+	var publisher usecases.EventPublisher
+	if ctx.Webhooks != nil {
+		publisher = ctx.Webhooks.ForTenant(r.Context(), t)
+	}
+	return usecases.NewOrderOperator(
+		shop.NewOrderRepository(ctx.MongoClient, ctx.DatabaseName, t),
+		shop.NewCustomerRepository(ctx.MongoClient, ctx.DatabaseName, t),
+		shop.NewItemRepository(ctx.MongoClient, ctx.DatabaseName, t),
+		publisher,
+		middleware.OrderMetrics{},
+	)
+}
+
+// getOrder returns the order in the URL if it belongs to the customer in the URL, writing the error response if it doesn't
+func (ctx *DBContext) getOrder(rw http.ResponseWriter, r *http.Request) (*data.Order, bool) {
+	customerID, ok := getCustomerID(rw, r)
+	if !ok {
+		return nil, false
+	}
+	var order *data.Order
+	id, err := getObjectID(r, "orderId")
+	if err != nil {
+		err = data.ErrOrderNotFound
+	} else {
+		order, err = data.GetOrderByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err == nil && order.CustomerID != customerID {
+		err = usecases.ErrWrongCustomer
+	}
+	if err != nil {
+		writeOrderError(rw, r, err)
+		return nil, false
+	}
+	return order, true
+}
+
+// writeOrderError writes the response of an error raised while handling an order.
+// The errors of the business rules such as the stock or the balance being insufficient are conflicts
+func writeOrderError(rw http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrWrongCustomer):
+		// the orders of the other customers are not disclosed
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: data.ErrOrderNotFound.Error()}, rw)
+	case errors.Is(err, data.ErrOrderNotFound), errors.Is(err, data.ErrCustomerNotFound), errors.Is(err, data.ErrItemNotFound):
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+	case errors.Is(err, domain.ErrOutOfStock), errors.Is(err, domain.ErrInsufficientBalance),
+		errors.Is(err, domain.ErrProductNotInOrder), errors.Is(err, domain.ErrNotEnoughInOrder),
+		errors.Is(err, usecases.ErrEmptyOrder), errors.Is(err, usecases.ErrOrderPlaced):
+		rw.WriteHeader(http.StatusConflict)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+	default:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error handling Order")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+	}
+}
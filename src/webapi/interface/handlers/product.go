@@ -3,30 +3,37 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // GetSingleProduct gets a single product from database
 // swagger:route GET /products/{id} Products getSingleProduct
-// Return a list of Product from the database
+// Return a list of Product from the database, soft deleted ones only with includeDeleted=true for admins
 // responses:
 //	200: ProductResponse
+//	403: errorResponse
 //	404: errorResponse
 // ListSingle handles GET requests
 func (ctx *DBContext) GetSingleProduct(rw http.ResponseWriter, r *http.Request) {
-	span := createSpan("api.Product.GetSingleProduct", r)
-	defer span.Finish()
-
-	id := getProductID(r)
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
 
-	log.Debug().Msgf("get record id %d", id)
+	log.Ctx(r.Context()).Debug().Msgf("get record id %d", id)
 
-	product, err := data.GetProductByID(id, ctx.MongoClient, ctx.DatabaseName)
+	includeDeleted, ok := includeDeleted(rw, r)
+	if !ok {
+		return
+	}
+	product, err := data.GetProductByID(r.Context(), id, includeDeleted, tenant(r), ctx.MongoClient, ctx.DatabaseName)
 	if err != nil {
-		log.Error().Err(err).Msg("Error getting Detail")
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Detail")
 
 		rw.WriteHeader(http.StatusNotFound)
 		data.ToJSON(&GenericError{Message: err.Error()}, rw)
@@ -35,26 +42,28 @@ func (ctx *DBContext) GetSingleProduct(rw http.ResponseWriter, r *http.Request)
 	err = data.ToJSON(product, rw)
 	if err != nil {
 		// we should never be here but log the error just incase
-		log.Error().Err(err).Msg("Error serializing product")
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error serializing product")
 	}
 }
 
 // GetAllProducts gets all products from database
 // swagger:route GET /products Products getAllProducts
-// Return a list of Product from the database
+// Return a list of Product from the database, including the soft deleted ones only with includeDeleted=true for admins
 // responses:
 //	200: ProductsResponse
+//	403: errorResponse
 //	404: errorResponse
 // ListSingle handles GET requests
 func (ctx *DBContext) GetAllProducts(rw http.ResponseWriter, r *http.Request) {
-	span := createSpan("api.Product.GetSingleProduct", r)
-	defer span.Finish()
-
-	log.Debug().Msgf("get all products initiated")
+	log.Ctx(r.Context()).Debug().Msgf("get all products initiated")
 
-	products, err := data.GetProducts(ctx.MongoClient, ctx.DatabaseName)
+	includeDeleted, ok := includeDeleted(rw, r)
+	if !ok {
+		return
+	}
+	products, err := data.GetProducts(r.Context(), includeDeleted, tenant(r), ctx.MongoClient, ctx.DatabaseName)
 	if err != nil {
-		log.Error().Err(err).Msg("Error getting Product")
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Product")
 
 		rw.WriteHeader(http.StatusNotFound)
 		data.ToJSON(&GenericError{Message: err.Error()}, rw)
@@ -63,24 +72,239 @@ func (ctx *DBContext) GetAllProducts(rw http.ResponseWriter, r *http.Request) {
 	err = data.ToJSON(products, rw)
 	if err != nil {
 		// we should never be here but log the error just incase
-		log.Error().Err(err).Msg("Error serializing products")
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error serializing products")
 	}
 }
 
-// getProductID returns the ProductID from the URL
-// Panics if cannot convert the id into an bson.primitive.ObjectID
-// this should never happen as the router ensures that
-// this is a valid number
-func getProductID(r *http.Request) primitive.ObjectID {
-	// parse the Rating id from the url
-	vars := mux.Vars(r)
+// AddProduct adds a new product to the database
+// swagger:route POST /products Products addProduct
+// Create a new Product in the database
+// responses:
+//	201: ProductResponse
+//	422: errorValidation
+//	500: errorResponse
+// AddProduct handles POST requests
+func (ctx *DBContext) AddProduct(rw http.ResponseWriter, r *http.Request) {
+	productDTO := r.Context().Value(KeyProduct{}).(*dto.Product)
+	if !ctx.checkEnvironments(rw, r, productDTO) || !ctx.checkPrerequisites(rw, r, toProductData(productDTO)) {
+		return
+	}
+	product, err := data.AddProduct(r.Context(), toProductData(productDTO), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error adding Product")
 
-	// convert the id into an integer and return
-	id, err := primitive.ObjectIDFromHex(vars["id"])
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.recordRevision(r, product)
+	ctx.audit(r, auditCreate, auditProducts, product.ID.Hex(), nil, product)
+	ctx.publish(r, webhook.EventProductCreated, product)
+	rw.WriteHeader(http.StatusCreated)
+	err = data.ToJSON(product, rw)
 	if err != nil {
-		// should never happen
-		panic(err)
+		// we should never be here but log the error just incase
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error serializing product")
+	}
+}
+
+// UpdateProduct replaces a product and its features in the database
+// swagger:route PUT /products/{id} Products updateProduct
+// Update the Product and its feature flags in the database
+// responses:
+//	200: ProductResponse
+//	404: errorResponse
+//	409: errorResponse
+//	422: errorValidation
+// UpdateProduct handles PUT requests
+func (ctx *DBContext) UpdateProduct(rw http.ResponseWriter, r *http.Request) {
+	productDTO := r.Context().Value(KeyProduct{}).(*dto.Product)
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
 	}
+	productDTO.ID = id
+	if !ctx.checkEnvironments(rw, r, productDTO) || !ctx.checkPrerequisites(rw, r, toProductData(productDTO)) {
+		return
+	}
+	before, _ := data.GetProductByID(r.Context(), productDTO.ID, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	product, err := data.UpdateProduct(r.Context(), toProductData(productDTO), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err == data.ErrProductNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err == data.ErrProductConflict {
+		rw.WriteHeader(http.StatusConflict)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error updating Product")
 
-	return id
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.recordRevision(r, product)
+	ctx.audit(r, auditUpdate, auditProducts, product.ID.Hex(), before, product)
+	ctx.publish(r, webhook.EventProductUpdated, product)
+	err = data.ToJSON(product, rw)
+	if err != nil {
+		// we should never be here but log the error just incase
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error serializing product")
+	}
+}
+
+// DeleteProduct soft deletes a product
+// swagger:route DELETE /products/{id} Products deleteProduct
+// Mark the Product as deleted, after which it's not returned anymore unless asked for explicitly.
+// It's removed permanently after the retention period
+// responses:
+//	200: ProductResponse
+//	404: errorResponse
+// DeleteProduct handles DELETE requests
+func (ctx *DBContext) DeleteProduct(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	before, _ := data.GetProductByID(r.Context(), id, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	product, err := data.DeleteProduct(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	ctx.writeDeletion(rw, r, err, auditDelete, webhook.EventProductDeleted, before, product)
+}
+
+// RestoreProduct restores a soft deleted product
+// swagger:route POST /products/{id}/restore Products restoreProduct
+// Remove the deletion mark of the Product
+// responses:
+//	200: ProductResponse
+//	404: errorResponse
+// RestoreProduct handles POST requests
+func (ctx *DBContext) RestoreProduct(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	before, _ := data.GetProductByID(r.Context(), id, true, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	product, err := data.RestoreProduct(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	ctx.writeDeletion(rw, r, err, auditRestore, webhook.EventProductRestored, before, product)
+}
+
+// writeDeletion writes the response of deleting or restoring a product, recording the change if it succeeded
+func (ctx *DBContext) writeDeletion(rw http.ResponseWriter, r *http.Request, err error, action, event string, before, product *data.Product) {
+	if err == data.ErrProductNotFound {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msgf("Error on %s of Product", action)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.recordRevision(r, product)
+	ctx.audit(r, action, auditProducts, product.ID.Hex(), before, product)
+	ctx.publish(r, event, product)
+	data.ToJSON(product, rw)
+}
+
+// includeDeleted returns the includeDeleted query parameter of the request,
+// writing a 403 response if it's asked for by a caller who's not allowed to see the deleted products
+func includeDeleted(rw http.ResponseWriter, r *http.Request) (bool, bool) {
+	if r.URL.Query().Get("includeDeleted") != "true" {
+		return false, true
+	}
+	if claims, ok := auth.FromContext(r.Context()); !ok || !claims.Can(auth.ManageDeletedProducts) {
+		forbidden(rw, "Caller is not allowed to see the deleted products")
+		return false, false
+	}
+	return true, true
+}
+
+// toProductData converts the Product received from the API into the Product stored in the database
+func toProductData(productDTO *dto.Product) data.Product {
+	product := data.Product{
+		ID:       productDTO.ID,
+		Name:     productDTO.Name,
+		Features: []data.Feature{},
+		Owners:   productDTO.Owners,
+	}
+	for _, f := range productDTO.Features {
+		feature := data.Feature{
+			ID:            f.ID,
+			Name:          f.Name,
+			Code:          f.Code,
+			Type:          data.FlagType(f.Type),
+			DefaultValue:  f.DefaultValue,
+			Variants:      []data.Variant{},
+			Prerequisites: []data.Prerequisite{},
+			Environments:  map[string]data.FeatureEnvironment{},
+		}
+		for _, p := range f.Prerequisites {
+			feature.Prerequisites = append(feature.Prerequisites, data.Prerequisite{ProductID: p.ProductID, Code: p.Code, Variant: p.Variant})
+		}
+		for _, v := range f.Variants {
+			feature.Variants = append(feature.Variants, data.Variant{Key: v.Key, Value: v.Value, Weight: v.Weight})
+		}
+		for key, e := range f.Environments {
+			env := data.FeatureEnvironment{Enabled: e.Enabled, Value: e.Value, Rules: []data.Rule{}}
+			for _, r := range e.Rules {
+				env.Rules = append(env.Rules, data.Rule{Attribute: r.Attribute, Operator: r.Operator, Values: r.Values, Value: r.Value})
+			}
+			feature.Environments[key] = env
+		}
+		product.Features = append(product.Features, feature)
+	}
+	return product
+}
+
+// toProductDTO converts the Product stored in the database into the Product received from the API, to be validated like it
+func toProductDTO(product data.Product) *dto.Product {
+	productDTO := &dto.Product{
+		ID:       product.ID,
+		Name:     product.Name,
+		Features: []dto.Feature{},
+		Owners:   product.Owners,
+	}
+	for _, f := range product.Features {
+		feature := dto.Feature{
+			ID:            f.ID,
+			Name:          f.Name,
+			Code:          f.Code,
+			Type:          dto.FlagType(f.Type),
+			DefaultValue:  f.DefaultValue,
+			Variants:      []dto.Variant{},
+			Prerequisites: []dto.Prerequisite{},
+			Environments:  map[string]dto.FeatureEnvironment{},
+		}
+		for _, p := range f.Prerequisites {
+			feature.Prerequisites = append(feature.Prerequisites, dto.Prerequisite{ProductID: p.ProductID, Code: p.Code, Variant: p.Variant})
+		}
+		for _, v := range f.Variants {
+			feature.Variants = append(feature.Variants, dto.Variant{Key: v.Key, Value: v.Value, Weight: v.Weight})
+		}
+		for key, e := range f.Environments {
+			env := dto.FeatureEnvironment{Enabled: e.Enabled, Value: e.Value, Rules: []dto.Rule{}}
+			for _, r := range e.Rules {
+				env.Rules = append(env.Rules, dto.Rule{Attribute: r.Attribute, Operator: r.Operator, Values: r.Values, Value: r.Value})
+			}
+			feature.Environments[key] = env
+		}
+		productDTO.Features = append(productDTO.Features, feature)
+	}
+	return productDTO
+}
+
+// getProductID returns the id of the product from the URL, writing a 404 response if it's not a valid id
+func getProductID(rw http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	id, err := getObjectID(r, "id")
+	if err != nil {
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: data.ErrProductNotFound.Error()}, rw)
+		return id, false
+	}
+	return id, true
 }
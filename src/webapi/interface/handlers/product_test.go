@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+)
+
+func Test_MalformedProductID(t *testing.T) {
+	ctx := &handlers.DBContext{}
+	router := mux.NewRouter()
+	router.HandleFunc("/products/{id}", ctx.GetSingleProduct).Methods(http.MethodGet)
+	router.HandleFunc("/products/{id}", ctx.DeleteProduct).Methods(http.MethodDelete)
+	router.HandleFunc("/products/{id}/graph", ctx.GetProductGraph)
+	router.HandleFunc("/products/{id}/revisions", ctx.GetProductRevisions)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/products/not-an-id", nil),
+		httptest.NewRequest(http.MethodDelete, "/products/not-an-id", nil),
+		httptest.NewRequest(http.MethodGet, "/products/not-an-id/graph", nil),
+		httptest.NewRequest(http.MethodGet, "/products/not-an-id/revisions", nil),
+	} {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, req)
+		if rw.Code != http.StatusNotFound {
+			t.Errorf("Error handling the malformed product id of %s %s. Expected status 404, got %d", req.Method, req.URL.Path, rw.Code)
+		}
+	}
+}
+
+func Test_IncludeDeletedNotAllowed(t *testing.T) {
+	ctx := &handlers.DBContext{}
+	router := mux.NewRouter()
+	router.HandleFunc("/products", ctx.GetAllProducts)
+	router.HandleFunc("/products/{id}", ctx.GetSingleProduct)
+	for _, claims := range []auth.Claims{nil, {"sub": "editor", auth.RolesClaim: []interface{}{string(auth.Editor)}}} {
+		for _, path := range []string{"/products?includeDeleted=true", "/products/5f7b1a2e9d1e8a1b2c3d4e5f?includeDeleted=true"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if claims != nil {
+				req = req.WithContext(auth.NewContext(req.Context(), claims))
+			}
+			rw := httptest.NewRecorder()
+			router.ServeHTTP(rw, req)
+			if rw.Code != http.StatusForbidden {
+				t.Errorf("Error getting the deleted products as %v. Expected status 403, got %d", claims.Subject(), rw.Code)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
+)
+
+// auditRollback is the name of the audited action of rolling back a product
+const auditRollback = "rollback"
+
+// GetProductRevisions gets the revisions of a product
+// swagger:route GET /products/{id}/revisions Products getProductRevisions
+// Return the revisions of the Product, newest first
+// responses:
+//	200: ProductRevisionsResponse
+//	500: errorResponse
+// GetProductRevisions handles GET requests
+func (ctx *DBContext) GetProductRevisions(rw http.ResponseWriter, r *http.Request) {
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return
+	}
+	revisions, err := data.GetProductRevisions(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting ProductRevisions")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(revisions, rw)
+}
+
+// GetProductRevision gets a single revision of a product
+// swagger:route GET /products/{id}/revisions/{revision} Products getProductRevision
+// Return the given revision of the Product
+// responses:
+//	200: ProductRevisionResponse
+//	404: errorResponse
+// GetProductRevision handles GET requests
+func (ctx *DBContext) GetProductRevision(rw http.ResponseWriter, r *http.Request) {
+	revision, ok := ctx.findRevision(rw, r, mux.Vars(r)["revision"])
+	if !ok {
+		return
+	}
+	data.ToJSON(revision, rw)
+}
+
+// DiffProductRevisions compares two revisions of a product
+// swagger:route GET /products/{id}/revisions/diff Products diffProductRevisions
+// Return the fields of the Product which differ between the revisions given with the from and to query parameters
+// responses:
+//	200: FieldChangesResponse
+//	404: errorResponse
+// DiffProductRevisions handles GET requests
+func (ctx *DBContext) DiffProductRevisions(rw http.ResponseWriter, r *http.Request) {
+	from, ok := ctx.findRevision(rw, r, r.URL.Query().Get("from"))
+	if !ok {
+		return
+	}
+	to, ok := ctx.findRevision(rw, r, r.URL.Query().Get("to"))
+	if !ok {
+		return
+	}
+	data.ToJSON(data.DiffProductRevisions(from, to), rw)
+}
+
+// RollbackProduct restores an earlier revision of a product
+// swagger:route POST /products/{id}/revisions/{revision}/rollback Products rollbackProduct
+// Restore the Product to the given revision, recorded as a new revision
+// responses:
+//	200: ProductResponse
+//	404: errorResponse
+//	409: errorResponse
+// RollbackProduct handles POST requests
+func (ctx *DBContext) RollbackProduct(rw http.ResponseWriter, r *http.Request) {
+	revision, ok := ctx.findRevision(rw, r, mux.Vars(r)["revision"])
+	if !ok {
+		return
+	}
+	if !ctx.checkPrerequisites(rw, r, revision.Product) {
+		return
+	}
+	before, _ := data.GetProductByID(r.Context(), revision.ProductID, false, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	// rolling back to a revision recorded at a deletion should not delete the product again
+	revision.Product.DeletedAt = nil
+	product, err := data.UpdateProduct(r.Context(), revision.Product, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err == data.ErrProductNotFound || err == data.ErrProductConflict {
+		rw.WriteHeader(http.StatusConflict)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error rolling back Product")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.recordRevision(r, product)
+	ctx.audit(r, auditRollback, auditProducts, product.ID.Hex(), before, product)
+	ctx.publish(r, webhook.EventProductUpdated, product)
+	data.ToJSON(product, rw)
+}
+
+// findRevision returns the given revision of the product in the URL, writing a 404 response if there's none
+func (ctx *DBContext) findRevision(rw http.ResponseWriter, r *http.Request, revision string) (*data.ProductRevision, bool) {
+	id, ok := getProductID(rw, r)
+	if !ok {
+		return nil, false
+	}
+	number, err := strconv.Atoi(revision)
+	var productRevision *data.ProductRevision
+	if err == nil {
+		productRevision, err = data.GetProductRevision(r.Context(), id, number, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	} else {
+		err = data.ErrRevisionNotFound
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting ProductRevision")
+
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return nil, false
+	}
+	return productRevision, true
+}
+
+// recordRevision stores the product as a new revision. Failures are logged and do not fail the call
+func (ctx *DBContext) recordRevision(r *http.Request, product *data.Product) {
+	err := data.AddProductRevision(r.Context(), *product, actor(r), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msgf("Error recording revision %d of product %s", product.Revision, product.ID.Hex())
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tenancy"
+)
+
+// MiddlewareTenant returns a middleware which resolves the tenant of the request and adds it to the context.
+// Requests to the exempt paths are passed through without a tenant, as are all requests if multi-tenancy is disabled.
+// Callers who don't belong to the tenant of the request are rejected, with 401 if they're not authenticated at all
+func (ctx *DBContext) MiddlewareTenant(exemptPaths []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if ctx.Tenants == nil || isExempt(r.URL.Path, exemptPaths) {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			tenant, err := ctx.Tenants.Resolve(r)
+			if _, ok := auth.FromContext(r.Context()); !ok && err == tenancy.ErrTenantClaimMissing {
+				unauthorized(rw, err.Error())
+				return
+			}
+			if err == tenancy.ErrTenantMismatch || err == tenancy.ErrTenantClaimMissing {
+				forbidden(rw, err.Error())
+				return
+			}
+			if err != nil {
+				log.Ctx(r.Context()).Debug().Err(err).Msg("Error resolving tenant")
+				rw.WriteHeader(http.StatusBadRequest)
+				data.ToJSON(&GenericError{Message: err.Error()}, rw)
+				return
+			}
+
+			// add the tenant to the context
+			r = r.WithContext(tenancy.NewContext(r.Context(), tenant))
+
+			// Call the next handler, which can be another middleware in the chain, or the final handler.
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// tenant returns the tenant of the request
+func tenant(r *http.Request) data.Tenant {
+	return tenancy.FromContext(r.Context())
+}
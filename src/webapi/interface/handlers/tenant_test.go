@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tenancy"
+)
+
+func Test_MiddlewareTenant(t *testing.T) {
+	resolver, _ := tenancy.NewResolver(tenancy.HeaderSource, "X-Tenant-ID", "tenant", data.FieldIsolation)
+	ctx := &handlers.DBContext{Tenants: resolver}
+	var resolved data.Tenant
+	handler := ctx.MiddlewareTenant([]string{"/health/live"})(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		resolved = tenancy.FromContext(r.Context())
+	}))
+	tests := []struct {
+		name   string
+		path   string
+		claims auth.Claims
+		status int
+	}{
+		{"exempt path", "/health/live", nil, http.StatusOK},
+		{"anonymous caller", "/products", nil, http.StatusUnauthorized},
+		{"caller without a tenant", "/products", auth.Claims{"sub": "apikey:1"}, http.StatusForbidden},
+		{"caller of another tenant", "/products", auth.Claims{"sub": "user", "tenant": "globex"}, http.StatusForbidden},
+		{"caller of the tenant", "/products", auth.Claims{"sub": "user", "tenant": "acme"}, http.StatusOK},
+	}
+	for _, test := range tests {
+		resolved = data.Tenant{}
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		r.Header.Set("X-Tenant-ID", "acme")
+		if test.claims != nil {
+			r = r.WithContext(auth.NewContext(r.Context(), test.claims))
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, r)
+		if rw.Code != test.status {
+			t.Errorf("Error resolving the tenant of the %s. Expected status %d, got %d", test.name, test.status, rw.Code)
+		}
+	}
+	if resolved.ID != "acme" {
+		t.Errorf("Error resolving the tenant of the caller of the tenant. Expected acme, got %v", resolved)
+	}
+}
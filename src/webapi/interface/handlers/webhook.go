@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AddSubscription adds a new webhook subscription
+// swagger:route POST /webhooks Webhooks addSubscription
+// Subscribe a url to the given events
+// responses:
+//	201: SubscriptionResponse
+//	422: errorValidation
+//	500: errorResponse
+// AddSubscription handles POST requests
+func (ctx *DBContext) AddSubscription(rw http.ResponseWriter, r *http.Request) {
+	subscriptionDTO := r.Context().Value(KeySubscription{}).(*dto.Subscription)
+	subscription, err := data.AddSubscription(r.Context(), data.Subscription{
+		URL:    subscriptionDTO.URL,
+		Secret: subscriptionDTO.Secret,
+		Events: subscriptionDTO.Events,
+	}, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error adding Subscription")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditCreate, auditSubscriptions, subscription.ID.Hex(), nil, subscription)
+	rw.WriteHeader(http.StatusCreated)
+	data.ToJSON(subscription, rw)
+}
+
+// GetAllSubscriptions gets all webhook subscriptions
+// swagger:route GET /webhooks Webhooks getAllSubscriptions
+// Return a list of Subscription from the database
+// responses:
+//	200: SubscriptionsResponse
+//	500: errorResponse
+// GetAllSubscriptions handles GET requests
+func (ctx *DBContext) GetAllSubscriptions(rw http.ResponseWriter, r *http.Request) {
+	subscriptions, err := data.GetSubscriptions(r.Context(), tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Subscriptions")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(subscriptions, rw)
+}
+
+// GetSingleSubscription gets a single webhook subscription
+// swagger:route GET /webhooks/{id} Webhooks getSingleSubscription
+// Return the Subscription with the given id
+// responses:
+//	200: SubscriptionResponse
+//	404: errorResponse
+// GetSingleSubscription handles GET requests
+func (ctx *DBContext) GetSingleSubscription(rw http.ResponseWriter, r *http.Request) {
+	subscription, ok := ctx.findSubscription(rw, r)
+	if !ok {
+		return
+	}
+	data.ToJSON(subscription, rw)
+}
+
+// DeleteSubscription removes a webhook subscription together with its delivery log
+// swagger:route DELETE /webhooks/{id} Webhooks deleteSubscription
+// Remove the Subscription with the given id
+// responses:
+//	204: noContentResponse
+//	404: errorResponse
+//	500: errorResponse
+// DeleteSubscription handles DELETE requests
+func (ctx *DBContext) DeleteSubscription(rw http.ResponseWriter, r *http.Request) {
+	subscription, ok := ctx.findSubscription(rw, r)
+	if !ok {
+		return
+	}
+	err := data.DeleteSubscription(r.Context(), subscription.ID, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error deleting Subscription")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	ctx.audit(r, auditDelete, auditSubscriptions, subscription.ID.Hex(), subscription, nil)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries gets the delivery log of a webhook subscription
+// swagger:route GET /webhooks/{id}/deliveries Webhooks getDeliveries
+// Return the deliveries made to the Subscription, newest first
+// responses:
+//	200: DeliveriesResponse
+//	404: errorResponse
+// GetDeliveries handles GET requests
+func (ctx *DBContext) GetDeliveries(rw http.ResponseWriter, r *http.Request) {
+	subscription, ok := ctx.findSubscription(rw, r)
+	if !ok {
+		return
+	}
+	deliveries, err := data.GetDeliveries(r.Context(), subscription.ID, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Deliveries")
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	data.ToJSON(deliveries, rw)
+}
+
+// Redeliver posts the payload of an earlier delivery to the subscription again
+// swagger:route POST /webhooks/{id}/deliveries/{deliveryId}/redeliver Webhooks redeliver
+// Deliver the event of an earlier delivery again, logged as a new delivery
+// responses:
+//	202: noContentResponse
+//	404: errorResponse
+//	503: errorResponse
+// Redeliver handles POST requests
+func (ctx *DBContext) Redeliver(rw http.ResponseWriter, r *http.Request) {
+	subscription, ok := ctx.findSubscription(rw, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := getObjectID(r, "deliveryId")
+	var delivery *data.Delivery
+	if err == nil {
+		delivery, err = data.GetDeliveryByID(r.Context(), deliveryID, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err == nil && delivery.SubscriptionID != subscription.ID {
+		err = data.ErrDeliveryNotFound
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Delivery")
+
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return
+	}
+	if ctx.Webhooks == nil || !ctx.Webhooks.Redeliver(r.Context(), tenant(r), *subscription, *delivery) {
+		log.Ctx(r.Context()).Error().Msg("Error redelivering, webhooks are not running")
+
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		data.ToJSON(&GenericError{Message: "webhooks are not running"}, rw)
+		return
+	}
+	ctx.audit(r, auditRedeliver, auditSubscriptions, subscription.ID.Hex(), nil, delivery)
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// findSubscription returns the Subscription matching the id in the URL, writing a 404 response if there's none
+func (ctx *DBContext) findSubscription(rw http.ResponseWriter, r *http.Request) (*data.Subscription, bool) {
+	id, err := getObjectID(r, "id")
+	var subscription *data.Subscription
+	if err == nil {
+		subscription, err = data.GetSubscriptionByID(r.Context(), id, tenant(r), ctx.MongoClient, ctx.DatabaseName)
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Error getting Subscription")
+
+		rw.WriteHeader(http.StatusNotFound)
+		data.ToJSON(&GenericError{Message: err.Error()}, rw)
+		return nil, false
+	}
+	return subscription, true
+}
+
+// publish sends the event to the webhook subscribers of the tenant of the request if webhooks are configured
+func (ctx *DBContext) publish(r *http.Request, event string, payload interface{}) {
+	if ctx.Webhooks != nil {
+		ctx.Webhooks.Publish(r.Context(), tenant(r), event, payload)
+	}
+}
+
+// getObjectID returns the bson.primitive.ObjectID in the given URL variable
+func getObjectID(r *http.Request, key string) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(mux.Vars(r)[key])
+}
@@ -0,0 +1,161 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Statuses of the components and of the report
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check reports the health of a component, returning an error if it can't be used.
+// The context is cancelled when the timeout of the check passes
+type Check func(ctx context.Context) error
+
+// Result is the outcome of the last run of a check
+type Result struct {
+	// the status of the component, up or down
+	//
+	// required: true
+	Status string `json:"status"`
+	// the time the check took in milliseconds
+	//
+	// required: true
+	Latency int64 `json:"latencyMs"`
+	// the error of the check if the component is down
+	//
+	// required: false
+	Error string `json:"error,omitempty"`
+	// the time the check was run
+	//
+	// required: true
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the health of the service, which is up if all of its components are up
+type Report struct {
+	// the status of the service, up or down
+	//
+	// required: true
+	Status string `json:"status"`
+	// the results of the checks by the name of the component
+	//
+	// required: true
+	Components map[string]Result `json:"components"`
+}
+
+// component is a registered check with its last result
+type component struct {
+	name    string
+	check   Check
+	timeout time.Duration
+	sync.Mutex
+	last *Result
+}
+
+// Registry runs the health checks the components register
+type Registry struct {
+	cacheTTL   time.Duration
+	mu         sync.RWMutex
+	components []*component
+	started    bool
+}
+
+// NewRegistry returns a new Registry which reuses the result of a check for cacheTTL before running it again
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL}
+}
+
+// Register adds the check of the component with the given name, which is considered down if it takes longer than timeout
+func (reg *Registry) Register(name string, timeout time.Duration, check Check) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.components = append(reg.components, &component{name: name, check: check, timeout: timeout})
+}
+
+// Run runs the checks of all the components concurrently, reusing the results younger than the cache TTL,
+// and returns the report of the service
+func (reg *Registry) Run(ctx context.Context) Report {
+	reg.mu.RLock()
+	components := reg.components
+	reg.mu.RUnlock()
+
+	results := make([]Result, len(components))
+	var wg sync.WaitGroup
+	for i, c := range components {
+		wg.Add(1)
+		go func(i int, c *component) {
+			defer wg.Done()
+			results[i] = reg.result(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: map[string]Result{}}
+	for i, c := range components {
+		report.Components[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	if report.Status == StatusUp {
+		reg.mu.Lock()
+		reg.started = true
+		reg.mu.Unlock()
+	}
+	return report
+}
+
+// Started checks if all the components have been up at the same time once, which means the service has started
+func (reg *Registry) Started() bool {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	return reg.started
+}
+
+// result returns the cached result of the component if it's younger than the cache TTL, or runs its check
+func (reg *Registry) result(ctx context.Context, c *component) Result {
+	c.Lock()
+	defer c.Unlock()
+	if c.last != nil && time.Since(c.last.CheckedAt) < reg.cacheTTL {
+		return *c.last
+	}
+	// the result is shared with the other callers, so it shouldn't fail as this caller goes away
+	result := run(context.WithoutCancel(ctx), c)
+	c.last = &result
+	return result
+}
+
+// run runs the check of the component within its timeout, recovering from a panicking check
+func run(ctx context.Context, c *component) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	result.CheckedAt = time.Now().UTC()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("Check panicked: %v", r)
+			}
+		}()
+		done <- c.check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("Check timed out after %s", c.timeout)
+	}
+	result.Latency = time.Since(result.CheckedAt).Milliseconds()
+	result.Status = StatusUp
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
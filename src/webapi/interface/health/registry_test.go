@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/interface/health"
+)
+
+func Test_Run(t *testing.T) {
+	registry := health.NewRegistry(time.Minute)
+	var runs int32
+	registry.Register("mongo", time.Second, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	registry.Register("cache", time.Second, func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	registry.Register("queue", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+		t.Errorf("Error running the checks concurrently. Expected them to take about 50ms, got %s", elapsed)
+	}
+	if report.Status != health.StatusDown || report.Components["mongo"].Status != health.StatusUp || report.Components["mongo"].Latency < 50 {
+		t.Errorf("Error reporting the components. Expected mongo up with its latency and the service down, got %v", report)
+	}
+	if queue := report.Components["queue"]; queue.Status != health.StatusDown || queue.Error != "Check timed out after 10ms" {
+		t.Errorf("Error reporting the slow component. Expected it to time out, got %v", queue)
+	}
+	if registry.Started() {
+		t.Errorf("Error reporting the startup. Expected not started while a component is down, got started")
+	}
+	registry.Run(context.Background())
+	if runs != 1 {
+		t.Errorf("Error caching the results. Expected the check to run once, got %d runs", runs)
+	}
+}
+
+func Test_Started(t *testing.T) {
+	registry := health.NewRegistry(0)
+	failing := true
+	registry.Register("mongo", time.Second, func(ctx context.Context) error {
+		if failing {
+			return fmt.Errorf("server selection timeout")
+		}
+		return nil
+	})
+	if report := registry.Run(context.Background()); report.Components["mongo"].Error != "server selection timeout" || registry.Started() {
+		t.Errorf("Error running a failing check. Expected its error and not started, got %v", report)
+	}
+	failing = false
+	registry.Run(context.Background())
+	failing = true
+	if report := registry.Run(context.Background()); report.Status != health.StatusDown || !registry.Started() {
+		t.Errorf("Error reporting the startup. Expected started to stay true once all components are up, got %v", report)
+	}
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StartProductPurge removes the products of every tenant soft deleted longer than the retention period every interval, until the context is done
+func StartProductPurge(ctx context.Context, isolation data.Isolation, dbClient mongo.Client, dbName string, retention time.Duration, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				PurgeProducts(ctx, isolation, dbClient, dbName, retention)
+			}
+		}
+	}()
+}
+
+// PurgeProducts removes the products of every tenant soft deleted longer than the retention period once
+func PurgeProducts(ctx context.Context, isolation data.Isolation, dbClient mongo.Client, dbName string, retention time.Duration) {
+	tenants, err := data.GetTenants(ctx, isolation, dbClient, dbName)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting the tenants to purge the deleted products of")
+		return
+	}
+	for _, tenant := range tenants {
+		purged, err := data.PurgeDeletedProducts(ctx, time.Now().Add(-retention), tenant, dbClient, dbName)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error purging the deleted products of tenant '%s'", tenant.ID)
+		} else if purged > 0 {
+			log.Info().Msgf("Purged %d products of tenant '%s' deleted more than %s ago", purged, tenant.ID, retention)
+		}
+	}
+}
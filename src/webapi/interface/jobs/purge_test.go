@@ -0,0 +1,48 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/jobs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func Test_PurgeProducts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("every tenant", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			// the tenants
+			bson.D{{Key: "ok", Value: 1}, {Key: "values", Value: bson.A{"acme", "globex"}}},
+			// the products of acme
+			mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch, bson.D{{Key: "_id", Value: primitive.NewObjectID()}}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+			// the revisions and the evaluation counts of the purged product
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}},
+			// the products of globex
+			mtest.CreateCursorResponse(0, "goboiler.products", mtest.FirstBatch),
+		)
+		started := time.Now()
+		jobs.PurgeProducts(context.Background(), data.FieldIsolation, *mt.Client, "goboiler", 24*time.Hour)
+		tenants := []string{}
+		for e := mt.GetStartedEvent(); e != nil; e = mt.GetStartedEvent() {
+			if e.CommandName != "find" {
+				continue
+			}
+			filter := e.Command.Lookup("filter").Document()
+			tenants = append(tenants, filter.Lookup("tenant").StringValue())
+			deletedBefore := time.UnixMilli(filter.Lookup("deletedAt", "$lt").DateTime())
+			if retention := started.Sub(deletedBefore); retention < 24*time.Hour-time.Minute || retention > 24*time.Hour+time.Minute {
+				mt.Errorf("Error purging the products. Expected the products deleted more than 24h ago to be purged, got %v", deletedBefore)
+			}
+		}
+		if len(tenants) != 2 || tenants[0] != "acme" || tenants[1] != "globex" {
+			mt.Errorf("Error purging the products. Expected the products of acme and globex to be purged, got %v", tenants)
+		}
+	})
+}
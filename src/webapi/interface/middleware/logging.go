@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID is the header the id of the request is read from and returned in
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength is the length of the longest incoming request id which is honoured
+const maxRequestIDLength = 128
+
+// requestIDKey is the key the id of the request is kept with in the context
+type requestIDKey struct{}
+
+// RequestID returns the id of the request the context belongs to, empty if it's not set
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDMiddleware sets the id of the request from the X-Request-ID header, or generates one if it's missing or invalid,
+// and returns it in the same header. The context of the request carries the id and a logger
+// which adds the id and the trace id to every line, read with log.Ctx in the handlers and in the data functions
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		rw.Header().Set(HeaderRequestID, id)
+
+		logger := log.With().Str("request_id", id)
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			logger = logger.Str("trace_id", sc.TraceID().String())
+		}
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = logger.Logger().WithContext(ctx)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// AccessLogMiddleware writes a line to the logger of the request for each request handled,
+// with the method, the route, the status code, the size of the response, the latency and the remote address
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		rr := &responseRecorder{ResponseWriter: rw, status: http.StatusOK}
+		next.ServeHTTP(rr, r)
+		log.Ctx(r.Context()).Info().
+			Str("method", r.Method).
+			Str("route", route(r)).
+			Str("path", r.URL.Path).
+			Int("status", rr.status).
+			Int("bytes", rr.size).
+			Dur("latency", time.Since(startTime)).
+			Str("remote", r.RemoteAddr).
+			Msg("Request handled")
+	})
+}
+
+// validRequestID checks if the incoming request id can be used, which is printable ASCII no longer than maxRequestIDLength
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+)
+
+func Test_RequestIDMiddleware(t *testing.T) {
+	var requestID string
+	handler := middleware.RequestIDMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requestID = middleware.RequestID(r.Context())
+	}))
+	cases := []struct {
+		incoming string
+		kept     bool
+	}{
+		{"abc-123", true},
+		{"", false},
+		{"has space", false},
+		{strings.Repeat("a", 129), false},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/products", nil)
+		r.Header.Set(middleware.HeaderRequestID, c.incoming)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, r)
+		if requestID == "" || rw.Header().Get(middleware.HeaderRequestID) != requestID || (requestID == c.incoming) != c.kept {
+			t.Errorf("Error setting the request id from '%s'. Expected it to be kept: %v, got '%s'", c.incoming, c.kept, requestID)
+		}
+	}
+}
+
+func Test_AccessLogMiddleware(t *testing.T) {
+	var b bytes.Buffer
+	global := log.Logger
+	log.Logger = zerolog.New(&b)
+	defer func() { log.Logger = global }()
+
+	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware, middleware.AccessLogMiddleware)
+	router.HandleFunc("/products/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		log.Ctx(r.Context()).Info().Msg("Getting the product")
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte("{}"))
+	})
+	r := httptest.NewRequest("GET", "/products/42", nil)
+	r.Header.Set(middleware.HeaderRequestID, "abc-123")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Error logging the request. Expected the handler line and the access line, got %v", lines)
+	}
+	handlerLine, accessLine := map[string]interface{}{}, map[string]interface{}{}
+	json.Unmarshal([]byte(lines[0]), &handlerLine)
+	json.Unmarshal([]byte(lines[1]), &accessLine)
+	if handlerLine["request_id"] != "abc-123" {
+		t.Errorf("Error logging from the handler. Expected request_id abc-123, got %v", handlerLine)
+	}
+	if accessLine["request_id"] != "abc-123" || accessLine["route"] != "/products/{id}" || accessLine["status"] != float64(404) || accessLine["bytes"] != float64(2) {
+		t.Errorf("Error writing the access log. Expected request_id, route, status and bytes of the request, got %v", accessLine)
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoConnectionsGauge counts the open connections of the pool per server
+var (
+	MongoConnectionsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "mongo",
+			Subsystem: "pool",
+			Name:      "connections",
+			Help:      "Number of open connections of the pool",
+		},
+		[]string{"address"},
+	)
+)
+
+// MongoCheckedOutGauge counts the connections of the pool in use per server
+var (
+	MongoCheckedOutGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "mongo",
+			Subsystem: "pool",
+			Name:      "checked_out_connections",
+			Help:      "Number of connections of the pool in use",
+		},
+		[]string{"address"},
+	)
+)
+
+// MongoWaitHistogram observes the time waited for a connection of the pool per server
+var (
+	MongoWaitHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "mongo",
+			Subsystem: "pool",
+			Name:      "wait_seconds",
+			Help:      "Time waited for checking out a connection of the pool",
+			Buckets:   []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 5},
+		},
+		[]string{"address"},
+	)
+)
+
+// MongoCheckOutErrorCounterVec counts the failed check outs of the pool per server and reason
+var (
+	MongoCheckOutErrorCounterVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "mongo",
+			Subsystem: "pool",
+			Name:      "number_of_check_out_errors",
+			Help:      "Total number of connections which could not be checked out of the pool",
+		},
+		[]string{"address", "reason"},
+	)
+)
+
+// MongoCommandDurationHistogram observes the duration of the commands per collection and operation
+var (
+	MongoCommandDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "mongo",
+			Subsystem: "commands",
+			Name:      "duration_seconds",
+			Help:      "Duration of the commands sent to the database",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"collection", "operation"},
+	)
+)
+
+// MongoCommandErrorCounterVec counts the failed commands per collection and operation
+var (
+	MongoCommandErrorCounterVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "mongo",
+			Subsystem: "commands",
+			Name:      "number_of_errors",
+			Help:      "Total number of commands failed in the database",
+		},
+		[]string{"collection", "operation"},
+	)
+)
+
+// MongoCommandMonitor returns the command monitor of the Mongo client which observes the duration of the commands
+// and counts the failed ones
+func MongoCommandMonitor() *event.CommandMonitor {
+	collections := sync.Map{}
+	finished := func(e event.CommandFinishedEvent, failed bool) {
+		collection, _ := collections.LoadAndDelete(e.RequestID)
+		name, _ := collection.(string)
+		MongoCommandDurationHistogram.WithLabelValues(name, e.CommandName).Observe(e.Duration.Seconds())
+		if failed {
+			MongoCommandErrorCounterVec.WithLabelValues(name, e.CommandName).Inc()
+		}
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collections.Store(e.RequestID, data.CommandCollection(e))
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, false)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			finished(e.CommandFinishedEvent, true)
+		},
+	}
+}
+
+// MongoPoolMonitor returns the pool monitor of the Mongo client which counts the open and the checked out connections
+// and observes the time waited for a connection
+func MongoPoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				MongoConnectionsGauge.WithLabelValues(e.Address).Inc()
+			case event.ConnectionClosed:
+				MongoConnectionsGauge.WithLabelValues(e.Address).Dec()
+			case event.GetSucceeded:
+				MongoCheckedOutGauge.WithLabelValues(e.Address).Inc()
+				MongoWaitHistogram.WithLabelValues(e.Address).Observe(e.Duration.Seconds())
+			case event.GetFailed:
+				MongoWaitHistogram.WithLabelValues(e.Address).Observe(e.Duration.Seconds())
+				MongoCheckOutErrorCounterVec.WithLabelValues(e.Address, e.Reason).Inc()
+			case event.ConnectionReturned:
+				MongoCheckedOutGauge.WithLabelValues(e.Address).Dec()
+			}
+		},
+	}
+}
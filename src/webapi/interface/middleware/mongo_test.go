@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func Test_MongoCommandMonitor(t *testing.T) {
+	monitor := middleware.MongoCommandMonitor()
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "products"}})
+	monitor.Started(context.Background(), &event.CommandStartedEvent{Command: command, CommandName: "find", RequestID: 1})
+	monitor.Started(context.Background(), &event.CommandStartedEvent{Command: command, CommandName: "find", RequestID: 2})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, Duration: time.Millisecond}})
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2, Duration: time.Second}})
+	if series := testutil.CollectAndCount(middleware.MongoCommandDurationHistogram); series != 1 {
+		t.Errorf("Error observing the command durations. Expected 1 series, got %d", series)
+	}
+	if failed := testutil.ToFloat64(middleware.MongoCommandErrorCounterVec.WithLabelValues("products", "find")); failed != 1 {
+		t.Errorf("Error counting the failed commands. Expected 1, got %v", failed)
+	}
+}
+
+func Test_MongoPoolMonitor(t *testing.T) {
+	monitor := middleware.MongoPoolMonitor()
+	for _, e := range []event.PoolEvent{
+		{Type: event.ConnectionCreated, Address: "db:27017"},
+		{Type: event.ConnectionCreated, Address: "db:27017"},
+		{Type: event.GetSucceeded, Address: "db:27017", Duration: time.Millisecond},
+		{Type: event.GetSucceeded, Address: "db:27017", Duration: time.Millisecond},
+		{Type: event.ConnectionReturned, Address: "db:27017"},
+		{Type: event.GetFailed, Address: "db:27017", Reason: event.ReasonTimedOut, Duration: time.Second},
+	} {
+		e := e
+		monitor.Event(&e)
+	}
+	if open := testutil.ToFloat64(middleware.MongoConnectionsGauge.WithLabelValues("db:27017")); open != 2 {
+		t.Errorf("Error counting the open connections. Expected 2, got %v", open)
+	}
+	if checkedOut := testutil.ToFloat64(middleware.MongoCheckedOutGauge.WithLabelValues("db:27017")); checkedOut != 1 {
+		t.Errorf("Error counting the checked out connections. Expected 1, got %v", checkedOut)
+	}
+	if failed := testutil.ToFloat64(middleware.MongoCheckOutErrorCounterVec.WithLabelValues("db:27017", event.ReasonTimedOut)); failed != 1 {
+		t.Errorf("Error counting the failed check outs. Expected 1, got %v", failed)
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/serdarkalayci/goboiler/webapi/domain"
+)
+
+// OrdersPlacedCounter counts the orders placed
+var (
+	OrdersPlacedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "shop",
+			Subsystem: "orders",
+			Name:      "number_of_orders",
+			Help:      "Total number of orders placed",
+		},
+	)
+)
+
+// OrderValueHistogram observes the total value of the orders placed
+var (
+	OrderValueHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "shop",
+			Subsystem: "orders",
+			Name:      "value",
+			Help:      "Total value of the orders placed",
+			Buckets:   []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500},
+		},
+	)
+)
+
+// OrderItemsCounterVec counts the items added to and removed from the orders
+var (
+	OrderItemsCounterVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "shop",
+			Subsystem: "orders",
+			Name:      "number_of_items",
+			Help:      "Total number of items added to or removed from the orders",
+		},
+		[]string{"change"},
+	)
+)
+
+// OrderRejectionCounterVec counts the items which were not added to the orders per reason
+var (
+	OrderRejectionCounterVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "shop",
+			Subsystem: "orders",
+			Name:      "number_of_rejections",
+			Help:      "Total number of items which were not added to the orders",
+		},
+		[]string{"reason"},
+	)
+)
+
+// OrderMetrics is the usecases.Metrics implementation which records the business metrics in Prometheus
+type OrderMetrics struct{}
+
+// OrderPlaced counts the order and observes its total value
+func (OrderMetrics) OrderPlaced(order domain.Order) {
+	OrdersPlacedCounter.Inc()
+	OrderValueHistogram.Observe(order.Total)
+}
+
+// ItemsAdded counts the items added to an order
+func (OrderMetrics) ItemsAdded(item domain.OrderItem) {
+	OrderItemsCounterVec.WithLabelValues("added").Add(float64(item.ItemCount))
+}
+
+// ItemsRemoved counts the items removed from an order
+func (OrderMetrics) ItemsRemoved(item domain.OrderItem) {
+	OrderItemsCounterVec.WithLabelValues("removed").Add(float64(item.ItemCount))
+}
+
+// OutOfStock counts a rejection as the product is out of stock
+func (OrderMetrics) OutOfStock(item domain.OrderItem) {
+	OrderRejectionCounterVec.WithLabelValues("out_of_stock").Inc()
+}
+
+// InsufficientBalance counts a rejection as the balance of the customer is not enough
+func (OrderMetrics) InsufficientBalance(item domain.OrderItem) {
+	OrderRejectionCounterVec.WithLabelValues("insufficient_balance").Inc()
+}
@@ -1,15 +1,16 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-//RequestCounterVec counts total request per endpoint and method
+//RequestCounterVec counts total request per route, method and status code
 var (
 	RequestCounterVec = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -18,24 +19,83 @@ var (
 			Name:      "number_of_requests",
 			Help:      "Total number of requests handled by the API",
 		},
-		[]string{"endpoint", "method"},
+		[]string{"route", "method", "code"},
 	)
 )
 
-//RequestDurationGauge calculates avg time per endpoint and method
+//RequestDurationHistogram observes the duration of the requests per route, method and status code
 var (
-	RequestDurationGauge = prometheus.NewGaugeVec(
+	RequestDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "http",
+			Subsystem: "requests",
+			Name:      "duration_seconds",
+			Help:      "Duration of the requests handled by the API",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "code"},
+	)
+)
+
+//ResponseSizeHistogram observes the size of the response bodies per route, method and status code
+var (
+	ResponseSizeHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "http",
+			Subsystem: "responses",
+			Name:      "size_bytes",
+			Help:      "Size of the response bodies written by the API",
+			Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+		},
+		[]string{"route", "method", "code"},
+	)
+)
+
+//InFlightGauge counts the requests being handled
+var (
+	InFlightGauge = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: "http",
 			Subsystem: "requests",
-			Name:      "request_duration",
-			Help:      "Avg time of requests handled by the API",
+			Name:      "in_flight",
+			Help:      "Number of requests being handled by the API",
+		},
+	)
+)
+
+//EvaluationCounterVec counts the evaluations per feature and variant. The features of every product are counted together
+var (
+	EvaluationCounterVec = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "flags",
+			Subsystem: "evaluations",
+			Name:      "number_of_evaluations",
+			Help:      "Total number of feature evaluations served by the API",
 		},
-		[]string{"endpoint", "method"},
+		[]string{"feature", "variant", "enabled"},
 	)
 )
 
-//MetricsMiddleware writes increments request count and calculates request duration and writes to Prometheus metrics
+// responseRecorder keeps the status code and the size of the response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	n, err := rr.ResponseWriter.Write(b)
+	rr.size += n
+	return n, err
+}
+
+//MetricsMiddleware counts the requests being handled and observes the duration and the response size of each request,
+//labelled with the template of the matched route, such as /products/{id}, instead of the path
 func MetricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		url := strings.TrimRight(r.URL.Path, "/")
@@ -43,18 +103,31 @@ func MetricsMiddleware(next http.Handler) http.Handler {
 		ignoreRoutes = append(ignoreRoutes, "/metrics", "/swagger")
 		if !contains(ignoreRoutes, url) {
 			startTime := time.Now()
-			log.Println(r.RequestURI)
-			RequestCounterVec.WithLabelValues(url, r.Method).Inc()
+			InFlightGauge.Inc()
+			defer InFlightGauge.Dec()
+			rr := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
 			// Call the next handler, which can be another middleware in the chain, or the final handler.
-			next.ServeHTTP(w, r)
-			duration := time.Now().Sub(startTime).Seconds() * 1000 //Duration in milliseconds
-			RequestDurationGauge.WithLabelValues(url, r.Method).Set(duration)
+			next.ServeHTTP(rr, r)
+			labels := prometheus.Labels{"route": route(r), "method": r.Method, "code": strconv.Itoa(rr.status)}
+			RequestCounterVec.With(labels).Inc()
+			RequestDurationHistogram.With(labels).Observe(time.Since(startTime).Seconds())
+			ResponseSizeHistogram.With(labels).Observe(float64(rr.size))
 		} else {
 			next.ServeHTTP(w, r)
 		}
 	})
 }
 
+// route returns the template of the route matching the request, or unmatched if there's none
+func route(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
+
 func contains(a []string, x string) bool {
 	for _, n := range a {
 		if x == n {
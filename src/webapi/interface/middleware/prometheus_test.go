@@ -0,0 +1,60 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/serdarkalayci/goboiler/webapi/domain"
+	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"github.com/serdarkalayci/goboiler/webapi/usecases"
+)
+
+func Test_MetricsMiddleware(t *testing.T) {
+	var inFlight float64
+	router := mux.NewRouter()
+	router.Use(middleware.MetricsMiddleware)
+	router.HandleFunc("/products/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		inFlight = testutil.ToFloat64(middleware.InFlightGauge)
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"message":"Product not found"}`))
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/5f8d0d55b54764421b7156c1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/5f8d0d55b54764421b7156c2", nil))
+
+	labels := prometheus.Labels{"route": "/products/{id}", "method": "GET", "code": "404"}
+	if count := testutil.ToFloat64(middleware.RequestCounterVec.With(labels)); count != 2 {
+		t.Errorf("Error counting the requests by route. Expected 2, got %v", count)
+	}
+	if series := testutil.CollectAndCount(middleware.RequestDurationHistogram); series != 1 {
+		t.Errorf("Error observing the durations by route. Expected 1 series, got %d", series)
+	}
+	if inFlight != 1 || testutil.ToFloat64(middleware.InFlightGauge) != 0 {
+		t.Errorf("Error counting the requests in flight. Expected 1 while handling and 0 after, got %v and %v", inFlight, testutil.ToFloat64(middleware.InFlightGauge))
+	}
+}
+
+func Test_OrderMetrics(t *testing.T) {
+	var metrics usecases.Metrics = middleware.OrderMetrics{}
+	item := domain.OrderItem{ItemCount: 3, Item: domain.Product{ID: "Product1", Price: 2.5}}
+	metrics.ItemsAdded(item)
+	metrics.ItemsRemoved(domain.OrderItem{ItemCount: 1, Item: item.Item})
+	metrics.OutOfStock(item)
+	metrics.OrderPlaced(domain.Order{Items: []domain.OrderItem{item}, Total: 7.5})
+	if added := testutil.ToFloat64(middleware.OrderItemsCounterVec.WithLabelValues("added")); added != 3 {
+		t.Errorf("Error counting the items added. Expected 3, got %v", added)
+	}
+	if removed := testutil.ToFloat64(middleware.OrderItemsCounterVec.WithLabelValues("removed")); removed != 1 {
+		t.Errorf("Error counting the items removed. Expected 1, got %v", removed)
+	}
+	if rejected := testutil.ToFloat64(middleware.OrderRejectionCounterVec.WithLabelValues("out_of_stock")); rejected != 1 {
+		t.Errorf("Error counting the items out of stock. Expected 1, got %v", rejected)
+	}
+	if placed := testutil.ToFloat64(middleware.OrdersPlacedCounter); placed != 1 {
+		t.Errorf("Error counting the orders placed. Expected 1, got %v", placed)
+	}
+}
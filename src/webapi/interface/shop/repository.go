@@ -0,0 +1,157 @@
+package shop
+
+import (
+	"context"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// repository holds the database and the tenant the repositories of the shop keep their data in
+type repository struct {
+	MongoClient  mongo.Client
+	DatabaseName string
+	Tenant       data.Tenant
+}
+
+// CustomerRepository is the domain.CustomerRepository implementation which keeps the customers in MongoDB
+type CustomerRepository struct {
+	repository
+}
+
+// NewCustomerRepository returns a new CustomerRepository using the given client and database for the given tenant
+func NewCustomerRepository(client mongo.Client, databaseName string, tenant data.Tenant) *CustomerRepository {
+	return &CustomerRepository{repository{client, databaseName, tenant}}
+}
+
+// Store replaces the customer in the database
+func (r *CustomerRepository) Store(ctx context.Context, customer domain.Customer) error {
+	id, err := primitive.ObjectIDFromHex(customer.ID)
+	if err != nil {
+		return data.ErrCustomerNotFound
+	}
+	_, err = data.UpdateCustomer(ctx, data.Customer{ID: id, Name: customer.Name, Balance: customer.Balance}, r.Tenant, r.MongoClient, r.DatabaseName)
+	return err
+}
+
+// Fetch returns the customer with the given id, or data.ErrCustomerNotFound
+func (r *CustomerRepository) Fetch(ctx context.Context, customerID string) (domain.Customer, error) {
+	id, err := primitive.ObjectIDFromHex(customerID)
+	if err != nil {
+		return domain.Customer{}, data.ErrCustomerNotFound
+	}
+	customer, err := data.GetCustomerByID(ctx, id, r.Tenant, r.MongoClient, r.DatabaseName)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+	return domain.Customer{ID: customer.ID.Hex(), Name: customer.Name, Balance: customer.Balance}, nil
+}
+
+// ItemRepository is the domain.ProductRepository implementation which keeps the products of the shop in MongoDB as items
+type ItemRepository struct {
+	repository
+}
+
+// NewItemRepository returns a new ItemRepository using the given client and database for the given tenant
+func NewItemRepository(client mongo.Client, databaseName string, tenant data.Tenant) *ItemRepository {
+	return &ItemRepository{repository{client, databaseName, tenant}}
+}
+
+// Store replaces the item of the product in the database
+func (r *ItemRepository) Store(ctx context.Context, product domain.Product) error {
+	id, err := primitive.ObjectIDFromHex(product.ID)
+	if err != nil {
+		return data.ErrItemNotFound
+	}
+	_, err = data.UpdateItem(ctx, data.Item{ID: id, Name: product.Name, Price: product.Price, StockCount: product.StockCount}, r.Tenant, r.MongoClient, r.DatabaseName)
+	return err
+}
+
+// Fetch returns the product of the item with the given id, or data.ErrItemNotFound
+func (r *ItemRepository) Fetch(ctx context.Context, productID string) (domain.Product, error) {
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return domain.Product{}, data.ErrItemNotFound
+	}
+	item, err := data.GetItemByID(ctx, id, r.Tenant, r.MongoClient, r.DatabaseName)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return domain.Product{ID: item.ID.Hex(), Name: item.Name, Price: item.Price, StockCount: item.StockCount}, nil
+}
+
+// OrderRepository is the domain.OrderRepository implementation which keeps the orders in MongoDB.
+// The orders refer to their customers by id, so the customers of the fetched orders only have their ids set
+type OrderRepository struct {
+	repository
+}
+
+// NewOrderRepository returns a new OrderRepository using the given client and database for the given tenant
+func NewOrderRepository(client mongo.Client, databaseName string, tenant data.Tenant) *OrderRepository {
+	return &OrderRepository{repository{client, databaseName, tenant}}
+}
+
+// Store replaces the order in the database
+func (r *OrderRepository) Store(ctx context.Context, order domain.Order) error {
+	stored, err := toOrderData(order)
+	if err != nil {
+		return err
+	}
+	_, err = data.UpdateOrder(ctx, stored, r.Tenant, r.MongoClient, r.DatabaseName)
+	return err
+}
+
+// Fetch returns the order with the given id, or data.ErrOrderNotFound
+func (r *OrderRepository) Fetch(ctx context.Context, orderID string) (domain.Order, error) {
+	id, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return domain.Order{}, data.ErrOrderNotFound
+	}
+	order, err := data.GetOrderByID(ctx, id, r.Tenant, r.MongoClient, r.DatabaseName)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return toOrderDomain(*order), nil
+}
+
+// toOrderData converts the order of the domain into the order stored in the database
+func toOrderData(order domain.Order) (data.Order, error) {
+	id, err := primitive.ObjectIDFromHex(order.ID)
+	if err != nil {
+		return data.Order{}, data.ErrOrderNotFound
+	}
+	customerID, err := primitive.ObjectIDFromHex(order.Customer.ID)
+	if err != nil {
+		return data.Order{}, data.ErrCustomerNotFound
+	}
+	stored := data.Order{ID: id, CustomerID: customerID, Items: []data.OrderItem{}, Total: order.Total}
+	if !order.Date.IsZero() {
+		date := order.Date.UTC()
+		stored.PlacedAt = &date
+	}
+	for _, item := range order.Items {
+		itemID, err := primitive.ObjectIDFromHex(item.Item.ID)
+		if err != nil {
+			return data.Order{}, data.ErrItemNotFound
+		}
+		stored.Items = append(stored.Items, data.OrderItem{ItemID: itemID, Name: item.Item.Name, Price: item.Item.Price, Count: item.ItemCount})
+	}
+	return stored, nil
+}
+
+// toOrderDomain converts the order stored in the database into the order of the domain
+func toOrderDomain(stored data.Order) domain.Order {
+	order := domain.Order{ID: stored.ID.Hex(), Customer: domain.Customer{ID: stored.CustomerID.Hex()}, Total: stored.Total}
+	if stored.PlacedAt != nil {
+		order.Date = *stored.PlacedAt
+	}
+	for _, item := range stored.Items {
+		order.Items = append(order.Items, domain.OrderItem{
+			ItemCount: item.Count,
+			Item:      domain.Product{ID: item.ItemID.Hex(), Name: item.Name, Price: item.Price},
+		})
+	}
+	return order
+}
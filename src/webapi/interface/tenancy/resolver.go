@@ -0,0 +1,118 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+)
+
+// Source is the enum that enumerates where the tenant of a request is read from
+type Source string
+
+const (
+	// HeaderSource reads the tenant from a request header
+	HeaderSource Source = "header"
+	// ClaimSource reads the tenant from a claim of the caller
+	ClaimSource Source = "claim"
+	// SubdomainSource reads the tenant from the first label of the host name
+	SubdomainSource Source = "subdomain"
+)
+
+// ErrTenantMissing is an error raised when the tenant of a request can not be resolved
+var ErrTenantMissing = fmt.Errorf("Tenant could not be resolved")
+
+// ErrTenantInvalid is an error raised when the resolved tenant is not a valid tenant id
+var ErrTenantInvalid = fmt.Errorf("Tenant must consist of 1 to 40 lowercase letters, digits and dashes")
+
+// ErrTenantClaimMissing is an error raised when the tenant is read from the request but the caller does not belong to a tenant
+var ErrTenantClaimMissing = fmt.Errorf("Caller does not belong to a tenant")
+
+// ErrTenantMismatch is an error raised when the caller belongs to another tenant than the one of the request
+var ErrTenantMismatch = fmt.Errorf("Caller does not belong to the tenant")
+
+// tenant ids are used in collection names, so they're kept to a safe set of characters
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,39}$`)
+
+// Resolver finds the tenant of the requests
+type Resolver struct {
+	Source    Source
+	Header    string
+	Claim     string
+	Isolation data.Isolation
+}
+
+// NewResolver returns a new Resolver which reads the tenant from the given source, using the given header or claim name,
+// and isolates the data of the tenants in the given way
+func NewResolver(source Source, header string, claim string, isolation data.Isolation) (*Resolver, error) {
+	switch source {
+	case HeaderSource, ClaimSource, SubdomainSource:
+	default:
+		return nil, fmt.Errorf("Unknown tenant source '%s'", source)
+	}
+	switch isolation {
+	case data.FieldIsolation, data.CollectionIsolation:
+	default:
+		return nil, fmt.Errorf("Unknown tenant isolation '%s'", isolation)
+	}
+	return &Resolver{Source: source, Header: header, Claim: claim, Isolation: isolation}, nil
+}
+
+// Resolve returns the tenant of the request. When the tenant is read from the header or the subdomain,
+// which anyone can set, the caller must carry a tenant claim, or use an API key issued for a tenant, of the same tenant
+func (res *Resolver) Resolve(r *http.Request) (data.Tenant, error) {
+	claims, _ := auth.FromContext(r.Context())
+	var id string
+	switch res.Source {
+	case HeaderSource:
+		id = r.Header.Get(res.Header)
+	case ClaimSource:
+		id = claims.String(res.Claim)
+	case SubdomainSource:
+		id = subdomain(r.Host)
+	}
+	if id == "" {
+		return data.Tenant{}, ErrTenantMissing
+	}
+	if !tenantPattern.MatchString(id) {
+		return data.Tenant{}, ErrTenantInvalid
+	}
+	claimed := claims.String(res.Claim)
+	if claimed == "" {
+		return data.Tenant{}, ErrTenantClaimMissing
+	}
+	if claimed != id {
+		return data.Tenant{}, ErrTenantMismatch
+	}
+	return data.Tenant{ID: id, Isolation: res.Isolation}, nil
+}
+
+// subdomain returns the first label of the host name if it has at least three labels
+func subdomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 3 {
+		return ""
+	}
+	return strings.ToLower(labels[0])
+}
+
+type keyTenant struct{}
+
+// NewContext returns a new context carrying the given tenant
+func NewContext(ctx context.Context, tenant data.Tenant) context.Context {
+	return context.WithValue(ctx, keyTenant{}, tenant)
+}
+
+// FromContext returns the tenant carried by the context, or the zero Tenant if multi-tenancy is disabled
+func FromContext(ctx context.Context) data.Tenant {
+	tenant, _ := ctx.Value(keyTenant{}).(data.Tenant)
+	return tenant
+}
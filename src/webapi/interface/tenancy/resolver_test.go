@@ -0,0 +1,72 @@
+package tenancy_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tenancy"
+)
+
+func Test_ResolveHeader(t *testing.T) {
+	resolver, _ := tenancy.NewResolver(tenancy.HeaderSource, "X-Tenant-ID", "tenant", data.FieldIsolation)
+	r := httptest.NewRequest("GET", "/products", nil)
+	if _, err := resolver.Resolve(r); err != tenancy.ErrTenantMissing {
+		t.Errorf("Error resolving tenant without header. Expected %v, got %v", tenancy.ErrTenantMissing, err)
+	}
+	r.Header.Set("X-Tenant-ID", "Acme_Corp")
+	if _, err := resolver.Resolve(r); err != tenancy.ErrTenantInvalid {
+		t.Errorf("Error resolving invalid tenant. Expected %v, got %v", tenancy.ErrTenantInvalid, err)
+	}
+	r.Header.Set("X-Tenant-ID", "acme")
+	if _, err := resolver.Resolve(r); err != tenancy.ErrTenantClaimMissing {
+		t.Errorf("Error resolving tenant for a caller without a tenant. Expected %v, got %v", tenancy.ErrTenantClaimMissing, err)
+	}
+	caller := r.WithContext(auth.NewContext(r.Context(), auth.Claims{"sub": "apikey:1"}))
+	if _, err := resolver.Resolve(caller); err != tenancy.ErrTenantClaimMissing {
+		t.Errorf("Error resolving tenant for a caller without a tenant claim. Expected %v, got %v", tenancy.ErrTenantClaimMissing, err)
+	}
+	caller = r.WithContext(auth.NewContext(r.Context(), auth.Claims{"tenant": "acme"}))
+	tenant, err := resolver.Resolve(caller)
+	if err != nil || tenant.ID != "acme" || tenant.Isolation != data.FieldIsolation {
+		t.Errorf("Error resolving tenant from header. Expected acme with field isolation, got %v (%v)", tenant, err)
+	}
+	caller = r.WithContext(auth.NewContext(r.Context(), auth.Claims{"tenant": "globex"}))
+	if _, err := resolver.Resolve(caller); err != tenancy.ErrTenantMismatch {
+		t.Errorf("Error resolving tenant of another caller. Expected %v, got %v", tenancy.ErrTenantMismatch, err)
+	}
+}
+
+func Test_ResolveClaim(t *testing.T) {
+	resolver, _ := tenancy.NewResolver(tenancy.ClaimSource, "X-Tenant-ID", "org", data.CollectionIsolation)
+	r := httptest.NewRequest("GET", "/products", nil)
+	r = r.WithContext(auth.NewContext(r.Context(), auth.Claims{"org": "acme"}))
+	tenant, err := resolver.Resolve(r)
+	if err != nil || tenant.ID != "acme" {
+		t.Errorf("Error resolving tenant from claim. Expected acme, got %v (%v)", tenant, err)
+	}
+}
+
+func Test_ResolveSubdomain(t *testing.T) {
+	resolver, _ := tenancy.NewResolver(tenancy.SubdomainSource, "X-Tenant-ID", "tenant", data.FieldIsolation)
+	r := httptest.NewRequest("GET", "http://acme.flags.example.com:5500/products", nil)
+	r = r.WithContext(auth.NewContext(r.Context(), auth.Claims{"tenant": "acme"}))
+	tenant, err := resolver.Resolve(r)
+	if err != nil || tenant.ID != "acme" {
+		t.Errorf("Error resolving tenant from subdomain. Expected acme, got %v (%v)", tenant, err)
+	}
+	r = httptest.NewRequest("GET", "http://localhost:5500/products", nil)
+	if _, err := resolver.Resolve(r); err != tenancy.ErrTenantMissing {
+		t.Errorf("Error resolving tenant without subdomain. Expected %v, got %v", tenancy.ErrTenantMissing, err)
+	}
+}
+
+func Test_NewResolver(t *testing.T) {
+	if _, err := tenancy.NewResolver("cookie", "", "", data.FieldIsolation); err == nil {
+		t.Errorf("Error creating resolver with unknown source. Expected an error, got nil")
+	}
+	if _, err := tenancy.NewResolver(tenancy.HeaderSource, "X-Tenant-ID", "", "database"); err == nil {
+		t.Errorf("Error creating resolver with unknown isolation. Expected an error, got nil")
+	}
+}
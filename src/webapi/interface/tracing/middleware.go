@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// statusRecorder keeps the status code written to the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Middleware starts a server span for each request named after the method and the template of the matched route,
+// such as "GET /products/{id}", and passes it to the handlers in the context of the request.
+// The span is tagged with the status code of the response and marked as failed on server errors and panics
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route := ""
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+		name := "HTTP " + r.Method
+		if route != "" {
+			name = r.Method + " " + route
+		}
+		ctx, span := StartSpan(name, r)
+		defer span.End()
+		span.SetAttributes(attribute.String("http.route", route))
+
+		sr := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+		defer func() {
+			if err := recover(); err != nil {
+				span.RecordError(fmt.Errorf("%v", err))
+				span.SetStatus(codes.Error, "panic")
+				panic(err)
+			}
+			span.SetAttributes(attribute.Int("http.status_code", sr.status))
+			if sr.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sr.status))
+			}
+		}()
+		next.ServeHTTP(sr, r.WithContext(ctx))
+	})
+}
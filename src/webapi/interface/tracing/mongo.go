@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CommandMonitor returns the command monitor of the Mongo client which creates a client span for each command,
+// named after the command and the collection such as "find products", as a child of the span in the context of the call
+func CommandMonitor() *event.CommandMonitor {
+	spans := sync.Map{}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			collection := data.CommandCollection(e)
+			name := e.CommandName
+			if collection != "" {
+				name += " " + collection
+			}
+			_, span := otel.Tracer(instrumentation).Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBCollectionName(collection),
+					semconv.DBOperationName(e.CommandName),
+				),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			if span, ok := spans.LoadAndDelete(e.RequestID); ok {
+				span.(trace.Span).End()
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			if span, ok := spans.LoadAndDelete(e.RequestID); ok {
+				span.(trace.Span).SetStatus(codes.Error, e.Failure)
+				span.(trace.Span).End()
+			}
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporter defines where the spans are sent
+type Exporter string
+
+const (
+	// ExporterOTLP sends the spans to an OTLP collector over HTTP. The endpoint and the headers are read from the standard
+	// OTEL_EXPORTER_OTLP_* environment variables, such as OTEL_EXPORTER_OTLP_ENDPOINT
+	ExporterOTLP Exporter = "otlp"
+	// ExporterStdout writes the spans to the standard output, for running locally
+	ExporterStdout Exporter = "stdout"
+	// ExporterNone records no spans
+	ExporterNone Exporter = "none"
+)
+
+// instrumentation is the name of the tracer creating the spans of the API
+const instrumentation = "github.com/serdarkalayci/goboiler/webapi"
+
+// ErrUnknownExporter is an error raised when the exporter is not one of otlp, stdout and none
+var ErrUnknownExporter = fmt.Errorf("Unknown trace exporter, expected one of otlp, stdout and none")
+
+// Setup sets the global tracer provider exporting the spans of the service, sampling the given ratio of the traces
+// which are not sampled by the caller already, and the W3C trace context and baggage propagators.
+// The returned function flushes and stops the exporter
+func Setup(ctx context.Context, exporter Exporter, serviceName string, sampleRatio float64) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var options []sdktrace.TracerProviderOption
+	switch exporter {
+	case ExporterOTLP:
+		e, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(e))
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithSyncer(e))
+	case ExporterNone:
+	default:
+		return nil, ErrUnknownExporter
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	options = append(options,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a server span with the given name for the request, continuing the trace of the caller
+// if the request carries a traceparent header. The span is tagged with the http.url and http.method of the request
+// like the spans of the former Jaeger tracer, so the existing queries keep working
+func StartSpan(name string, r *http.Request) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(instrumentation).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.url", r.URL.RequestURI()),
+			attribute.String("http.method", r.Method),
+		),
+	)
+}
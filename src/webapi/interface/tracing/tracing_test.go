@@ -0,0 +1,151 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_Setup(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), "jaeger", "test", 1); err != tracing.ErrUnknownExporter {
+		t.Errorf("Error setting up the tracing with an unknown exporter. Expected %v, got %v", tracing.ErrUnknownExporter, err)
+	}
+	shutdown, err := tracing.Setup(context.Background(), tracing.ExporterNone, "test", 1)
+	if err != nil {
+		t.Fatalf("Error setting up the tracing. Expected no error, got %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Error shutting down the tracing. Expected no error, got %v", err)
+	}
+}
+
+// record sets up the tracing with a tracer provider recording the spans
+func record(t *testing.T) *tracetest.SpanRecorder {
+	if _, err := tracing.Setup(context.Background(), tracing.ExporterNone, "test", 1); err != nil {
+		t.Fatalf("Error setting up the tracing. Expected no error, got %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func Test_StartSpan(t *testing.T) {
+	recorder := record(t)
+
+	r := httptest.NewRequest("GET", "/products/1?deleted=true", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := tracing.StartSpan("api.Product.GetSingleProduct", r)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Error starting the span. Expected 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "api.Product.GetSingleProduct" || s.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Error starting the span. Expected server span api.Product.GetSingleProduct, got %s span %s", s.SpanKind(), s.Name())
+	}
+	if s.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || s.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Error continuing the trace of the caller. Expected trace 4bf92f3577b34da6a3ce929d0e0e4736, got %s", s.SpanContext().TraceID())
+	}
+	attributes := attribute.NewSet(s.Attributes()...)
+	if url, _ := attributes.Value("http.url"); url.AsString() != "/products/1?deleted=true" {
+		t.Errorf("Error tagging the span. Expected http.url /products/1?deleted=true, got %s", url.AsString())
+	}
+	if method, _ := attributes.Value("http.method"); method.AsString() != "GET" {
+		t.Errorf("Error tagging the span. Expected http.method GET, got %s", method.AsString())
+	}
+}
+
+func Test_CommandMonitor(t *testing.T) {
+	recorder := record(t)
+	monitor := tracing.CommandMonitor()
+	r := httptest.NewRequest("GET", "/products", nil)
+	ctx, parent := tracing.StartSpan("api.Product.GetAllProducts", r)
+
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "products"}, {Key: "filter", Value: bson.D{}}})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "goboiler", CommandName: "find", RequestID: 1})
+	command, _ = bson.Marshal(bson.D{{Key: "getMore", Value: int64(42)}, {Key: "collection", Value: "products"}})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "goboiler", CommandName: "getMore", RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "getMore", RequestID: 2}, Failure: "cursor not found"})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Error tracing the commands. Expected 3 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "find products" || spans[0].Parent().SpanID() != parent.SpanContext().SpanID() || spans[0].Status().Code != codes.Unset {
+		t.Errorf("Error tracing the find command. Expected a successful child span find products, got %s with status %v", spans[0].Name(), spans[0].Status())
+	}
+	if spans[1].Name() != "getMore products" || spans[1].Status().Code != codes.Error || spans[1].Status().Description != "cursor not found" {
+		t.Errorf("Error tracing the failed getMore command. Expected a failed span getMore products, got %s with status %v", spans[1].Name(), spans[1].Status())
+	}
+	attributes := attribute.NewSet(spans[0].Attributes()...)
+	if collection, _ := attributes.Value("db.collection.name"); collection.AsString() != "products" {
+		t.Errorf("Error tagging the command span. Expected db.collection.name products, got %s", collection.AsString())
+	}
+}
+
+func Test_Transport(t *testing.T) {
+	recorder := record(t)
+	traceparent := ""
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: tracing.NewTransport(nil)}
+	resp, err := client.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("Error sending the request. Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "HTTP POST" || spans[0].SpanKind() != trace.SpanKindClient || spans[0].Status().Code != codes.Error {
+		t.Fatalf("Error tracing the request. Expected a failed client span HTTP POST, got %v", spans)
+	}
+	expected := "00-" + spans[0].SpanContext().TraceID().String() + "-" + spans[0].SpanContext().SpanID().String() + "-01"
+	if traceparent != expected {
+		t.Errorf("Error propagating the trace context. Expected traceparent %s, got %s", expected, traceparent)
+	}
+}
+
+func Test_Middleware(t *testing.T) {
+	recorder := record(t)
+	var handlerSpan trace.SpanContext
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+	router.HandleFunc("/products/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/products/42", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "GET /products/{id}" {
+		t.Fatalf("Error tracing the request. Expected a span GET /products/{id}, got %v", spans)
+	}
+	if !handlerSpan.Equal(spans[0].SpanContext()) {
+		t.Errorf("Error passing the span to the handler. Expected %v, got %v", spans[0].SpanContext(), handlerSpan)
+	}
+	attributes := attribute.NewSet(spans[0].Attributes()...)
+	if status, _ := attributes.Value("http.status_code"); status.AsInt64() != http.StatusInternalServerError || spans[0].Status().Code != codes.Error {
+		t.Errorf("Error recording the status. Expected a failed span with http.status_code 500, got %d with status %v", status.AsInt64(), spans[0].Status())
+	}
+}
@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tracing"
+	"github.com/serdarkalayci/goboiler/webapi/usecases"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Names of the events which can be subscribed to
+const (
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventProductRestored = "product.restored"
+	EventOrderPlaced     = usecases.OrderPlacedEvent
+)
+
+// Names of the headers sent with each delivery
+const (
+	HeaderEvent     = "X-Goboiler-Event"
+	HeaderDelivery  = "X-Goboiler-Delivery"
+	HeaderSignature = "X-Goboiler-Signature"
+)
+
+// ErrPrivateTarget is returned when a delivery, or a redirect it follows, would connect to an address which is not public
+var ErrPrivateTarget = errors.New("the webhook target is not a public address")
+
+// Store represents an interface for the outer layers to implement the persistence of subscriptions and deliveries
+type Store interface {
+	GetSubscriptionsForEvent(ctx context.Context, tenant data.Tenant, event string) ([]data.Subscription, error)
+	AddDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error
+	UpdateDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error
+}
+
+// Dispatcher posts the published events to the subscribed receivers, retrying with an exponential backoff
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	// stop is closed when the dispatcher is stopped, ending the waits before the retries
+	stop     chan struct{}
+	mu       sync.Mutex
+	stopped  bool
+	inFlight sync.WaitGroup
+}
+
+// envelope is the body posted to the receivers
+type envelope struct {
+	ID      string      `json:"id"`
+	Event   string      `json:"event"`
+	Created time.Time   `json:"created"`
+	Data    interface{} `json:"data"`
+}
+
+// NewDispatcher returns a new Dispatcher which makes at most maxAttempts attempts per delivery,
+// waiting backoff before the second attempt and doubling it after every failed attempt
+func NewDispatcher(store Store, maxAttempts int, backoff time.Duration) *Dispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: 10 * time.Second, Transport: tracing.NewTransport(newTransport())},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		stop:        make(chan struct{}),
+	}
+}
+
+// newTransport returns a transport which only connects to public addresses. The address is checked when it's dialed
+// rather than when the subscription is created, so a redirect or a host resolving to another address can't get around it
+func newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !dto.PublicIP(net.ParseIP(host)) {
+				return ErrPrivateTarget
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the target
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// Stop stops retrying the deliveries and waits until the attempts in flight are made and logged or the context is done.
+// The events published after it's stopped are not delivered
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+	d.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// background runs f in a goroutine the dispatcher waits for when it's stopped. It returns false without running f
+// if the dispatcher is stopped already
+func (d *Dispatcher) background(f func()) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return false
+	}
+	d.inFlight.Add(1)
+	go func() {
+		defer d.inFlight.Done()
+		f()
+	}()
+	return true
+}
+
+// Sign returns the HMAC-SHA256 signature of the payload in the format sent in the signature header
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// tenantPublisher publishes the events of the use cases to the subscriptions of a single tenant
+type tenantPublisher struct {
+	ctx        context.Context
+	dispatcher *Dispatcher
+	tenant     data.Tenant
+}
+
+// Publish delivers the event to the subscriptions of the tenant
+func (p tenantPublisher) Publish(event string, payload interface{}) {
+	p.dispatcher.Publish(p.ctx, p.tenant, event, payload)
+}
+
+// ForTenant returns the publisher the use cases can notify the subscriptions of the given tenant with
+// within the trace of the given context
+func (d *Dispatcher) ForTenant(ctx context.Context, tenant data.Tenant) usecases.EventPublisher {
+	return tenantPublisher{ctx, d, tenant}
+}
+
+// Publish delivers the event to every subscription of the tenant interested in it in the background, so it returns
+// without waiting for the subscriptions to be queried. The payload is serialized before it returns.
+// The deliveries are traced within the trace of the context but aren't cancelled with it
+func (d *Dispatcher) Publish(ctx context.Context, tenant data.Tenant, event string, payload interface{}) {
+	ctx = context.WithoutCancel(ctx)
+	eventID := primitive.NewObjectID().Hex()
+	body, err := json.Marshal(envelope{ID: eventID, Event: event, Created: time.Now().UTC(), Data: payload})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Error serializing the payload of event %s", event)
+		return
+	}
+	started := d.background(func() {
+		subscriptions, err := d.store.GetSubscriptionsForEvent(ctx, tenant, event)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("Error getting the subscriptions for event %s", event)
+			return
+		}
+		for _, subscription := range subscriptions {
+			// the dispatcher waits for this goroutine, so it can't have finished waiting for the deliveries
+			d.inFlight.Add(1)
+			go func(subscription data.Subscription) {
+				defer d.inFlight.Done()
+				d.Deliver(ctx, tenant, subscription, eventID, event, body)
+			}(subscription)
+		}
+	})
+	if !started {
+		log.Ctx(ctx).Warn().Msgf("Event %s is not delivered, the dispatcher is stopped", event)
+	}
+}
+
+// Redeliver posts the payload of an earlier delivery to its subscription again in the background, logging it as a new delivery.
+// It returns false if the dispatcher is stopped
+func (d *Dispatcher) Redeliver(ctx context.Context, tenant data.Tenant, subscription data.Subscription, delivery data.Delivery) bool {
+	ctx = context.WithoutCancel(ctx)
+	return d.background(func() {
+		d.Deliver(ctx, tenant, subscription, delivery.EventID, delivery.Event, []byte(delivery.Payload))
+	})
+}
+
+// Deliver stores the delivery in the delivery log of the tenant as pending, and posts the payload to the subscription
+// until it succeeds, runs out of attempts or the dispatcher is stopped. Then it stores the outcome in the log and returns it
+func (d *Dispatcher) Deliver(ctx context.Context, tenant data.Tenant, subscription data.Subscription, eventID string, event string, payload []byte) data.Delivery {
+	delivery := data.Delivery{
+		ID:             primitive.NewObjectID(),
+		SubscriptionID: subscription.ID,
+		EventID:        eventID,
+		Event:          event,
+		Payload:        string(payload),
+		CreatedAt:      time.Now().UTC(),
+		Attempts:       []data.DeliveryAttempt{},
+		Pending:        true,
+	}
+	err := d.store.AddDelivery(ctx, tenant, delivery)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Error storing delivery %s", delivery.ID.Hex())
+	}
+	wait := d.backoff
+	stopped := false
+	for i := 0; i < d.maxAttempts && !delivery.Succeeded && !stopped; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(wait):
+				wait *= 2
+			case <-d.stop:
+				stopped = true
+				continue
+			}
+		}
+		attempt := d.attempt(ctx, subscription, delivery.ID.Hex(), event, payload)
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		delivery.Succeeded = attempt.Error == "" && attempt.StatusCode >= 200 && attempt.StatusCode < 300
+	}
+	switch {
+	case stopped:
+		log.Ctx(ctx).Warn().Msgf("Delivery %s of event %s to %s stopped after %d attempts", delivery.ID.Hex(), event, subscription.URL, len(delivery.Attempts))
+	case !delivery.Succeeded:
+		log.Ctx(ctx).Warn().Msgf("Delivery %s of event %s to %s failed after %d attempts", delivery.ID.Hex(), event, subscription.URL, len(delivery.Attempts))
+	}
+	delivery.Pending = false
+	err = d.store.UpdateDelivery(ctx, tenant, delivery)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Error storing delivery %s", delivery.ID.Hex())
+	}
+	return delivery
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, subscription data.Subscription, deliveryID string, event string, payload []byte) data.DeliveryAttempt {
+	attempt := data.DeliveryAttempt{Date: time.Now().UTC()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, payload))
+	resp, err := d.client.Do(req)
+	attempt.Duration = time.Since(attempt.Date).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	resp.Body.Close()
+	attempt.StatusCode = resp.StatusCode
+	return attempt
+}
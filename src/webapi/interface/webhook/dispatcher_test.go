@@ -0,0 +1,217 @@
+package webhook_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeStore struct {
+	sync.Mutex
+	subscriptions []data.Subscription
+	deliveries    []data.Delivery
+}
+
+func (s *fakeStore) GetSubscriptionsForEvent(ctx context.Context, tenant data.Tenant, event string) ([]data.Subscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *fakeStore) AddDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error {
+	s.Lock()
+	defer s.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *fakeStore) UpdateDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error {
+	s.Lock()
+	defer s.Unlock()
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == delivery.ID {
+			s.deliveries[i] = delivery
+			return nil
+		}
+	}
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func Test_DeliverSignsPayload(t *testing.T) {
+	secret := "0123456789abcdef"
+	var signature, event string
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhook.HeaderSignature)
+		event = r.Header.Get(webhook.HeaderEvent)
+		body, _ = ioutil.ReadAll(r.Body)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	store := &fakeStore{}
+	dispatcher := webhook.NewDispatcher(store, 3, time.Millisecond)
+	webhook.AllowPrivateTargets(dispatcher)
+	subscription := createSubscription(receiver.URL, secret)
+	delivery := dispatcher.Deliver(context.Background(), data.Tenant{}, subscription, "event1", webhook.EventProductUpdated, []byte(`{"id":"event1"}`))
+	if !delivery.Succeeded || len(delivery.Attempts) != 1 {
+		t.Errorf("Error delivering to a healthy receiver. Expected 1 successful attempt, got %d attempts", len(delivery.Attempts))
+	}
+	if signature != webhook.Sign(secret, body) {
+		t.Errorf("Error signing the payload. Expected %s, got %s", webhook.Sign(secret, body), signature)
+	}
+	if event != webhook.EventProductUpdated {
+		t.Errorf("Error sending the event header. Expected %s, got %s", webhook.EventProductUpdated, event)
+	}
+	if len(store.deliveries) != 1 {
+		t.Errorf("Error logging the delivery. Expected 1 delivery in the log, got %d", len(store.deliveries))
+	}
+}
+
+func Test_DeliverRetries(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	dispatcher := webhook.NewDispatcher(&fakeStore{}, 5, time.Millisecond)
+	webhook.AllowPrivateTargets(dispatcher)
+	delivery := dispatcher.Deliver(context.Background(), data.Tenant{}, createSubscription(receiver.URL, "0123456789abcdef"), "event1", webhook.EventOrderPlaced, []byte(`{}`))
+	if !delivery.Succeeded || len(delivery.Attempts) != 3 {
+		t.Errorf("Error retrying a failing receiver. Expected 3 attempts, got %d", len(delivery.Attempts))
+	}
+	if delivery.Attempts[0].StatusCode != http.StatusInternalServerError {
+		t.Errorf("Error logging the attempt. Expected status code %d, got %d", http.StatusInternalServerError, delivery.Attempts[0].StatusCode)
+	}
+}
+
+func Test_DeliverGivesUp(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+	store := &fakeStore{}
+	dispatcher := webhook.NewDispatcher(store, 2, time.Millisecond)
+	webhook.AllowPrivateTargets(dispatcher)
+	delivery := dispatcher.Deliver(context.Background(), data.Tenant{}, createSubscription(receiver.URL, "0123456789abcdef"), "event1", webhook.EventOrderPlaced, []byte(`{}`))
+	if delivery.Succeeded || len(delivery.Attempts) != 2 {
+		t.Errorf("Error giving up on a failing receiver. Expected 2 failed attempts, got %d", len(delivery.Attempts))
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].Succeeded {
+		t.Errorf("Error logging the failed delivery. Expected 1 failed delivery in the log, got %d", len(store.deliveries))
+	}
+}
+
+func Test_DeliverRefusesPrivateTargets(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	// the receiver listens on the loopback address, which the dispatcher doesn't connect to unless it's allowed
+	dispatcher := webhook.NewDispatcher(&fakeStore{}, 1, time.Millisecond)
+	delivery := dispatcher.Deliver(context.Background(), data.Tenant{}, createSubscription(receiver.URL, "0123456789abcdef"), "event1", webhook.EventOrderPlaced, []byte(`{}`))
+	if delivery.Succeeded || calls != 0 {
+		t.Errorf("Error refusing a private target. Expected no calls to the receiver, got %d", calls)
+	}
+	if len(delivery.Attempts) != 1 || !strings.Contains(delivery.Attempts[0].Error, webhook.ErrPrivateTarget.Error()) {
+		t.Errorf("Error logging the refused attempt. Expected the error %s, got %v", webhook.ErrPrivateTarget.Error(), delivery.Attempts)
+	}
+}
+
+// pendingStore signals when a delivery is logged as pending
+type pendingStore struct {
+	fakeStore
+	added chan data.Delivery
+}
+
+func (s *pendingStore) AddDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error {
+	s.added <- delivery
+	return s.fakeStore.AddDelivery(ctx, tenant, delivery)
+}
+
+func Test_StopEndsRetries(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+	store := &pendingStore{added: make(chan data.Delivery, 1)}
+	// the dispatcher would wait an hour before the second attempt if Stop didn't end the wait
+	dispatcher := webhook.NewDispatcher(store, 3, time.Hour)
+	webhook.AllowPrivateTargets(dispatcher)
+	if !dispatcher.Redeliver(context.Background(), data.Tenant{}, createSubscription(receiver.URL, "0123456789abcdef"), data.Delivery{EventID: "event1", Event: webhook.EventOrderPlaced, Payload: `{}`}) {
+		t.Fatalf("Error redelivering. Expected the dispatcher to be running")
+	}
+	pending := <-store.added
+	if !pending.Pending || len(pending.Attempts) != 0 {
+		t.Errorf("Error logging the delivery before the first attempt. Expected a pending delivery without attempts, got pending %t with %d attempts", pending.Pending, len(pending.Attempts))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := dispatcher.Stop(ctx)
+	if err != nil {
+		t.Fatalf("Error stopping the dispatcher. Expected the deliveries to end, got %s", err.Error())
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].Pending || store.deliveries[0].Succeeded {
+		t.Errorf("Error logging the stopped delivery. Expected 1 failed delivery in the log, got %d", len(store.deliveries))
+	}
+	if dispatcher.Redeliver(context.Background(), data.Tenant{}, createSubscription(receiver.URL, "0123456789abcdef"), data.Delivery{}) {
+		t.Errorf("Error redelivering after Stop. Expected the dispatcher to refuse it")
+	}
+}
+
+// blockingStore returns its subscriptions once it's released
+type blockingStore struct {
+	fakeStore
+	release chan struct{}
+}
+
+func (s *blockingStore) GetSubscriptionsForEvent(ctx context.Context, tenant data.Tenant, event string) ([]data.Subscription, error) {
+	<-s.release
+	return s.subscriptions, nil
+}
+
+func Test_PublishInBackground(t *testing.T) {
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(webhook.HeaderEvent)
+	}))
+	defer receiver.Close()
+	store := &blockingStore{release: make(chan struct{})}
+	store.subscriptions = []data.Subscription{createSubscription(receiver.URL, "0123456789abcdef")}
+	dispatcher := webhook.NewDispatcher(store, 1, time.Millisecond)
+	webhook.AllowPrivateTargets(dispatcher)
+	// Publish would never return if it queried the subscriptions before returning
+	dispatcher.Publish(context.Background(), data.Tenant{}, webhook.EventOrderPlaced, map[string]string{"id": "Order1"})
+	close(store.release)
+	select {
+	case event := <-received:
+		if event != webhook.EventOrderPlaced {
+			t.Errorf("Error publishing the event. Expected %s, got %s", webhook.EventOrderPlaced, event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Error publishing the event. Expected a delivery, got none")
+	}
+}
+
+func createSubscription(url string, secret string) data.Subscription {
+	return data.Subscription{
+		ID:     primitive.NewObjectID(),
+		URL:    url,
+		Secret: secret,
+		Events: []string{webhook.EventProductUpdated, webhook.EventOrderPlaced},
+	}
+}
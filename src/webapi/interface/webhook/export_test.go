@@ -0,0 +1,11 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+)
+
+// AllowPrivateTargets lets the dispatcher deliver to the test receivers, which listen on the loopback address
+func AllowPrivateTargets(d *Dispatcher) {
+	d.client = &http.Client{Timeout: 10 * time.Second}
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/serdarkalayci/goboiler/webapi/data"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoStore is the Store implementation which keeps subscriptions and deliveries in MongoDB
+type MongoStore struct {
+	MongoClient  mongo.Client
+	DatabaseName string
+}
+
+// NewMongoStore returns a new MongoStore using the given client and database
+func NewMongoStore(client mongo.Client, databaseName string) *MongoStore {
+	return &MongoStore{client, databaseName}
+}
+
+// GetSubscriptionsForEvent returns the subscriptions of the tenant interested in the given event
+func (s *MongoStore) GetSubscriptionsForEvent(ctx context.Context, tenant data.Tenant, event string) ([]data.Subscription, error) {
+	subscriptions, err := data.GetSubscriptionsForEvent(ctx, event, tenant, s.MongoClient, s.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	return *subscriptions, nil
+}
+
+// AddDelivery stores the given delivery in the delivery log of the tenant
+func (s *MongoStore) AddDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error {
+	return data.AddDelivery(ctx, delivery, tenant, s.MongoClient, s.DatabaseName)
+}
+
+// UpdateDelivery replaces the given delivery in the delivery log of the tenant
+func (s *MongoStore) UpdateDelivery(ctx context.Context, tenant data.Tenant, delivery data.Delivery) error {
+	return data.UpdateDelivery(ctx, delivery, tenant, s.MongoClient, s.DatabaseName)
+}
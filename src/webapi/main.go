@@ -9,84 +9,142 @@ import (
 
 	openapimw "github.com/go-openapi/runtime/middleware"
 
+	"github.com/serdarkalayci/goboiler/webapi/data"
 	"github.com/serdarkalayci/goboiler/webapi/dto"
+	"github.com/serdarkalayci/goboiler/webapi/interface/admin"
+	"github.com/serdarkalayci/goboiler/webapi/interface/analytics"
+	"github.com/serdarkalayci/goboiler/webapi/interface/auth"
 	"github.com/serdarkalayci/goboiler/webapi/interface/handlers"
+	"github.com/serdarkalayci/goboiler/webapi/interface/health"
+	"github.com/serdarkalayci/goboiler/webapi/interface/jobs"
 	"github.com/serdarkalayci/goboiler/webapi/interface/middleware"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tenancy"
+	"github.com/serdarkalayci/goboiler/webapi/interface/tracing"
+	"github.com/serdarkalayci/goboiler/webapi/interface/webhook"
 
 	"github.com/rs/zerolog"
 
 	"github.com/gorilla/mux"
-	"github.com/nicholasjackson/env"
 	"github.com/rs/zerolog/log"
 	config "github.com/serdarkalayci/goboiler/webapi/configuration"
 
-	opentracing "github.com/opentracing/opentracing-go"
-	"github.com/uber/jaeger-client-go"
-
-	jprom "github.com/uber/jaeger-lib/metrics/prometheus"
-
-	jaegercfg "github.com/uber/jaeger-client-go/config"
-	jaegerlog "github.com/uber/jaeger-client-go/log"
-
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var bindAddress = env.String("BASE_URL", false, ":5500", "Bind address for the server")
-
 func main() {
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	config.SetConfigValues()
-
-	// Sample configuration for testing. Use constant sampling to sample every trace
-	// and enable LogSpan to log every span via configured Logger.
-	cfg, err := jaegercfg.FromEnv()
-	if err != nil || cfg.ServiceName == "" {
-		cfg = &jaegercfg.Configuration{
-			ServiceName: "GoBoiler.WebApi",
-			Sampler: &jaegercfg.SamplerConfig{
-				Type:  jaeger.SamplerTypeConst,
-				Param: 1,
-			},
-			Reporter: &jaegercfg.ReporterConfig{
-				LogSpans: true,
-			},
-		}
+	// log.Ctx falls back to the global logger outside of the requests, such as in the background jobs
+	zerolog.DefaultContextLogger = &log.Logger
+	cfg, err := config.Load()
+	if err != nil {
+		log.Error().Err(err).Msg("Error loading the configuration")
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Exporter(cfg.Tracing.Exporter), cfg.Tracing.ServiceName, cfg.Tracing.SampleRatio)
+	if err != nil {
+		log.Error().Err(err).Msg("Error setting up the tracing")
+		os.Exit(1)
 	}
+	defer shutdownTracing(context.Background())
 
-	// Example logger and metrics factory. Use github.com/uber/jaeger-client-go/log
-	// and github.com/uber/jaeger-lib/metrics respectively to bind to real logging and metrics
-	// frameworks.
-	jLogger := jaegerlog.StdLogger
-	jMetricsFactory := jprom.New()
-
-	// Initialize tracer with a logger and a metrics factory
-	tracer, closer, _ := cfg.NewTracer(
-		jaegercfg.Logger(jLogger),
-		jaegercfg.Metrics(jMetricsFactory),
-	)
-	// Set the singleton opentracing.Tracer with the Jaeger tracer.
-	opentracing.SetGlobalTracer(tracer)
-	defer closer.Close()
-	env.Parse()
+	authenticator, err := auth.NewAuthenticator(cfg.Auth.JWTSecret, cfg.Auth.JWKSFile, cfg.Auth.Issuer, cfg.Auth.Audience)
+	if err != nil {
+		log.Error().Err(err).Msg("Error creating the authenticator")
+		os.Exit(1)
+	}
+
+	var tenants *tenancy.Resolver
+	if cfg.Tenancy.Source != "" {
+		tenants, err = tenancy.NewResolver(tenancy.Source(cfg.Tenancy.Source), cfg.Tenancy.Header, cfg.Tenancy.Claim, data.Isolation(cfg.Tenancy.Isolation))
+		if err != nil {
+			log.Error().Err(err).Msg("Error creating the tenant resolver")
+			os.Exit(1)
+		}
+	}
 
 	v := dto.NewValidation()
 
 	// create the handlers
 	apiContext := handlers.NewAPIContext(v)
-	dbContext := handlers.NewDBContext(v)
+	dbContext := handlers.NewDBContext(v, cfg.Mongo)
+	dbContext.Webhooks = webhook.NewDispatcher(webhook.NewMongoStore(dbContext.MongoClient, dbContext.DatabaseName), cfg.Webhooks.MaxAttempts, cfg.Webhooks.Backoff)
+	dbContext.Tenants = tenants
+	dbContext.Analytics = analytics.NewRecorder(analytics.NewMongoStore(dbContext.MongoClient, dbContext.DatabaseName))
+	dbContext.Health = health.NewRegistry(cfg.Health.CacheTTL)
+	dbContext.Health.Register("mongo", cfg.Health.CheckTimeout, func(ctx context.Context) error {
+		return data.GetHealth(ctx, dbContext.MongoClient, dbContext.DatabaseName)
+	})
+	dbContext.Health.Register("config", cfg.Health.CheckTimeout, config.Check)
 
 	// create a new serve mux and register the handlers
 	sm := mux.NewRouter()
+	sm.Use(tracing.Middleware)
+	sm.Use(middleware.RequestIDMiddleware)
+	sm.Use(middleware.AccessLogMiddleware)
 	sm.Use(middleware.MetricsMiddleware)
+	sm.Use(dbContext.MiddlewareAPIKey(cfg.Auth.ExemptPaths))
+	sm.Use(handlers.MiddlewareAuthenticate(authenticator, cfg.Auth.ExemptPaths))
+	sm.Use(dbContext.MiddlewareTenant(cfg.Tenancy.ExemptPaths))
 
 	// handlers for API
 	getR := sm.Methods(http.MethodGet).Subrouter()
 	getR.HandleFunc("/", apiContext.Index)
 	getR.HandleFunc("/health/live", apiContext.Live)
 	getR.HandleFunc("/health/ready", dbContext.Ready)
-	getR.HandleFunc("/products/{id}", dbContext.GetSingleProduct)
-	getR.HandleFunc("/products", dbContext.GetAllProducts)
+	getR.HandleFunc("/health/startup", dbContext.Startup)
+	getR.Handle("/products/export", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.ExportProducts)))
+	getR.Handle("/products/{id}", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetSingleProduct)))
+	getR.Handle("/products/{id}/stale", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetStaleFeatures)))
+	getR.Handle("/products/{id}/graph", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetProductGraph)))
+	getR.Handle("/products/{id}/revisions", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetProductRevisions)))
+	getR.Handle("/products/{id}/revisions/diff", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.DiffProductRevisions)))
+	getR.Handle("/products/{id}/revisions/{revision}", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetProductRevision)))
+	getR.Handle("/products", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetAllProducts)))
+	getR.Handle("/environments", handlers.Authorize(auth.ReadProducts, http.HandlerFunc(dbContext.GetAllEnvironments)))
+	getR.Handle("/audit", handlers.Authorize(auth.ReadAudit, http.HandlerFunc(dbContext.GetAuditEntries)))
+	getR.Handle("/apikeys", handlers.Authorize(auth.ManageAPIKeys, http.HandlerFunc(dbContext.GetAllAPIKeys)))
+	getR.Handle("/webhooks", handlers.Authorize(auth.ManageWebhooks, http.HandlerFunc(dbContext.GetAllSubscriptions)))
+	getR.Handle("/webhooks/{id}", handlers.Authorize(auth.ManageWebhooks, http.HandlerFunc(dbContext.GetSingleSubscription)))
+	getR.Handle("/customers", handlers.Authorize(auth.ReadShop, http.HandlerFunc(dbContext.GetAllCustomers)))
+	getR.Handle("/customers/{id}", handlers.Authorize(auth.ReadShop, http.HandlerFunc(dbContext.GetSingleCustomer)))
+	getR.Handle("/webhooks/{id}/deliveries", handlers.Authorize(auth.ManageWebhooks, http.HandlerFunc(dbContext.GetDeliveries)))
+	getR.Handle("/customers/{id}/orders", handlers.Authorize(auth.ReadShop, http.HandlerFunc(dbContext.GetOrders)))
+	getR.Handle("/customers/{id}/orders/{orderId}", handlers.Authorize(auth.ReadShop, http.HandlerFunc(dbContext.GetSingleOrder)))
+	getR.Handle("/items", handlers.Authorize(auth.ReadShop, http.HandlerFunc(dbContext.GetAllItems)))
+	getR.Handle("/items/{id}", handlers.Authorize(auth.ReadShop, http.HandlerFunc(dbContext.GetSingleItem)))
+
+	postR := sm.Methods(http.MethodPost).Subrouter()
+	postR.Handle("/products", handlers.Authorize(auth.WriteProducts, dbContext.MiddlewareValidateNewProduct(http.HandlerFunc(dbContext.AddProduct))))
+	postR.Handle("/products/import", handlers.Authorize(auth.WriteProducts, http.HandlerFunc(dbContext.ImportProducts)))
+	postR.Handle("/products/{id}/revisions/{revision}/rollback", dbContext.AuthorizeProductOwner(auth.WriteProducts, http.HandlerFunc(dbContext.RollbackProduct)))
+	postR.Handle("/products/{id}/restore", handlers.Authorize(auth.ManageDeletedProducts, http.HandlerFunc(dbContext.RestoreProduct)))
+	postR.Handle("/products/{id}/evaluate", handlers.Authorize(auth.ReadProducts, dbContext.MiddlewareValidateEvaluation(http.HandlerFunc(dbContext.EvaluateProduct))))
+	postR.Handle("/products/{id}/environments/{key}/promote", dbContext.AuthorizeProductOwner(auth.WriteProducts, http.HandlerFunc(dbContext.PromoteProduct)))
+	postR.Handle("/environments", handlers.Authorize(auth.ManageEnvironments, dbContext.MiddlewareValidateNewEnvironment(http.HandlerFunc(dbContext.AddEnvironment))))
+	postR.Handle("/apikeys", handlers.Authorize(auth.ManageAPIKeys, dbContext.MiddlewareValidateNewAPIKey(http.HandlerFunc(dbContext.IssueAPIKey))))
+	postR.Handle("/apikeys/{id}/rotate", handlers.Authorize(auth.ManageAPIKeys, http.HandlerFunc(dbContext.RotateAPIKey)))
+	postR.Handle("/webhooks", handlers.Authorize(auth.ManageWebhooks, dbContext.MiddlewareValidateNewSubscription(http.HandlerFunc(dbContext.AddSubscription))))
+	postR.Handle("/customers", handlers.Authorize(auth.WriteShop, dbContext.MiddlewareValidateNewCustomer(http.HandlerFunc(dbContext.AddCustomer))))
+	postR.Handle("/webhooks/{id}/deliveries/{deliveryId}/redeliver", handlers.Authorize(auth.ManageWebhooks, http.HandlerFunc(dbContext.Redeliver)))
+	postR.Handle("/customers/{id}/orders", handlers.Authorize(auth.WriteShop, http.HandlerFunc(dbContext.AddOrder)))
+	postR.Handle("/customers/{id}/orders/{orderId}/items", handlers.Authorize(auth.WriteShop, dbContext.MiddlewareValidateNewOrderItem(http.HandlerFunc(dbContext.AddOrderItem))))
+	postR.Handle("/customers/{id}/orders/{orderId}/place", handlers.Authorize(auth.WriteShop, http.HandlerFunc(dbContext.PlaceOrder)))
+	postR.Handle("/items", handlers.Authorize(auth.WriteShop, dbContext.MiddlewareValidateNewItem(http.HandlerFunc(dbContext.AddItem))))
+
+	putR := sm.Methods(http.MethodPut).Subrouter()
+	putR.Handle("/products/{id}", dbContext.AuthorizeProductOwner(auth.WriteProducts, dbContext.MiddlewareValidateNewProduct(http.HandlerFunc(dbContext.UpdateProduct))))
+	putR.Handle("/customers/{id}", handlers.Authorize(auth.WriteShop, dbContext.MiddlewareValidateNewCustomer(http.HandlerFunc(dbContext.UpdateCustomer))))
+	putR.Handle("/items/{id}", handlers.Authorize(auth.WriteShop, dbContext.MiddlewareValidateNewItem(http.HandlerFunc(dbContext.UpdateItem))))
+
+	deleteR := sm.Methods(http.MethodDelete).Subrouter()
+	deleteR.Handle("/products/{id}", dbContext.AuthorizeProductOwner(auth.WriteProducts, http.HandlerFunc(dbContext.DeleteProduct)))
+	deleteR.Handle("/environments/{key}", handlers.Authorize(auth.ManageEnvironments, http.HandlerFunc(dbContext.DeleteEnvironment)))
+	deleteR.Handle("/apikeys/{id}", handlers.Authorize(auth.ManageAPIKeys, http.HandlerFunc(dbContext.RevokeAPIKey)))
+	deleteR.Handle("/webhooks/{id}", handlers.Authorize(auth.ManageWebhooks, http.HandlerFunc(dbContext.DeleteSubscription)))
+	deleteR.Handle("/customers/{id}", handlers.Authorize(auth.WriteShop, http.HandlerFunc(dbContext.DeleteCustomer)))
+	deleteR.Handle("/customers/{id}/orders/{orderId}/items/{itemId}", handlers.Authorize(auth.WriteShop, http.HandlerFunc(dbContext.RemoveOrderItem)))
 
 	// handler for documentation
 	opts := openapimw.RedocOpts{SpecURL: "/swagger.yaml"}
@@ -97,20 +155,72 @@ func main() {
 
 	// create a new server
 	s := http.Server{
-		Addr:         *bindAddress,      // configure the bind address
-		Handler:      sm,                // set the default handler
-		ReadTimeout:  5 * time.Second,   // max time to read request from the client
-		WriteTimeout: 10 * time.Second,  // max time to write response to the client
-		IdleTimeout:  120 * time.Second, // max time for connections using TCP Keep-Alive
+		Addr:         cfg.Server.Address, // configure the bind address
+		Handler:      sm,                 // set the default handler
+		ReadTimeout:  5 * time.Second,    // max time to read request from the client
+		WriteTimeout: 10 * time.Second,   // max time to write response to the client
+		IdleTimeout:  120 * time.Second,  // max time for connections using TCP Keep-Alive
 	}
 
 	sm.PathPrefix("/metrics").Handler(promhttp.Handler())
 	prometheus.MustRegister(middleware.RequestCounterVec)
-	prometheus.MustRegister(middleware.RequestDurationGauge)
+	prometheus.MustRegister(middleware.RequestDurationHistogram)
+	prometheus.MustRegister(middleware.ResponseSizeHistogram)
+	prometheus.MustRegister(middleware.InFlightGauge)
+	prometheus.MustRegister(middleware.EvaluationCounterVec)
+	prometheus.MustRegister(middleware.OrdersPlacedCounter)
+	prometheus.MustRegister(middleware.OrderValueHistogram)
+	prometheus.MustRegister(middleware.OrderItemsCounterVec)
+	prometheus.MustRegister(middleware.OrderRejectionCounterVec)
+	prometheus.MustRegister(middleware.MongoConnectionsGauge)
+	prometheus.MustRegister(middleware.MongoCheckedOutGauge)
+	prometheus.MustRegister(middleware.MongoWaitHistogram)
+	prometheus.MustRegister(middleware.MongoCheckOutErrorCounterVec)
+	prometheus.MustRegister(middleware.MongoCommandDurationHistogram)
+	prometheus.MustRegister(middleware.MongoCommandErrorCounterVec)
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	var isolation data.Isolation
+	if tenants != nil {
+		isolation = tenants.Isolation
+	}
+	jobs.StartProductPurge(purgeCtx, isolation, dbContext.MongoClient, dbContext.DatabaseName, cfg.Products.Retention, cfg.Products.PurgeInterval)
+
+	analyticsCtx, stopAnalytics := context.WithCancel(context.Background())
+	dbContext.Analytics.Start(analyticsCtx, cfg.Analytics.FlushInterval)
+
+	// create the admin server if enabled
+	var as *http.Server
+	if cfg.Server.AdminAddress != "" {
+		ar := admin.NewRouter(sm, config.Settings)
+		if !admin.IsLoopback(cfg.Server.AdminAddress) {
+			ar.Use(handlers.MiddlewareAuthenticate(authenticator, nil))
+			ar.Use(func(next http.Handler) http.Handler {
+				return handlers.Authorize(auth.ReadDebug, next)
+			})
+		}
+		as = &http.Server{
+			Addr:        cfg.Server.AdminAddress,
+			Handler:     ar,
+			ReadTimeout: 5 * time.Second,
+			// no write timeout, as the CPU profiles and the execution traces are written after the duration the caller asks for
+			IdleTimeout: 120 * time.Second,
+		}
+		go func() {
+			log.Info().Msgf("Starting admin server on %s", cfg.Server.AdminAddress)
+
+			err := as.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				log.Error().Err(err).Msg("Error starting admin server")
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// start the server
 	go func() {
-		log.Debug().Msgf("Starting server on %s", *bindAddress)
+		log.Debug().Msgf("Starting server on %s", cfg.Server.Address)
 
 		err := s.ListenAndServe()
 		if err != nil {
@@ -132,4 +242,17 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	s.Shutdown(ctx)
+	if as != nil {
+		as.Shutdown(ctx)
+	}
+
+	// log the webhook deliveries in flight, they're not retried anymore
+	err = dbContext.Webhooks.Stop(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Error waiting for the webhook deliveries")
+	}
+
+	// write the evaluation counts recorded since the last flush
+	stopAnalytics()
+	dbContext.Analytics.Flush(context.Background())
 }
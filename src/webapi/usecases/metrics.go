@@ -0,0 +1,26 @@
+package usecases
+
+import "github.com/serdarkalayci/goboiler/webapi/domain"
+
+// Metrics represents an interface for the outer layers to record the business metrics of the use cases
+type Metrics interface {
+	// OrderPlaced records an order placed with its total value
+	OrderPlaced(order domain.Order)
+	// ItemsAdded records the items added to an order
+	ItemsAdded(item domain.OrderItem)
+	// ItemsRemoved records the items removed from an order
+	ItemsRemoved(item domain.OrderItem)
+	// OutOfStock records the items which were not added to an order as the product is out of stock
+	OutOfStock(item domain.OrderItem)
+	// InsufficientBalance records the items which were not added to an order as the balance of the customer is not enough
+	InsufficientBalance(item domain.OrderItem)
+}
+
+// noMetrics is the Metrics implementation which records nothing, used when the use cases are created without metrics
+type noMetrics struct{}
+
+func (noMetrics) OrderPlaced(order domain.Order)            {}
+func (noMetrics) ItemsAdded(item domain.OrderItem)          {}
+func (noMetrics) ItemsRemoved(item domain.OrderItem)        {}
+func (noMetrics) OutOfStock(item domain.OrderItem)          {}
+func (noMetrics) InsufficientBalance(item domain.OrderItem) {}
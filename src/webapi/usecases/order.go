@@ -1,35 +1,172 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"github.com/serdarkalayci/goboiler/webapi/domain"
 )
 
+// EventPublisher represents an interface for the outer layers to notify interested parties about the events of the use cases
+type EventPublisher interface {
+	Publish(event string, payload interface{})
+}
+
+// OrderPlacedEvent is the name of the event published when an order is placed
+const OrderPlacedEvent = "order.placed"
+
+// ErrWrongCustomer is an error raised when the order does not belong to the customer
+var ErrWrongCustomer = errors.New("The order does not belong to this customer")
+
+// ErrEmptyOrder is an error raised when an order without any products is placed
+var ErrEmptyOrder = errors.New("The order does not contain any products, cannot place the order")
+
+// ErrOrderPlaced is an error raised when an order which is already placed is changed
+var ErrOrderPlaced = errors.New("The order is already placed, cannot change the order")
+
 // OrderOperator is the struct that hold both OrderRepository and CustomerRepository
 type OrderOperator struct {
 	orderRepository    domain.OrderRepository
 	customerRepository domain.CustomerRepository
 	productRepository  domain.ProductRepository
+	eventPublisher     EventPublisher
+	metrics            Metrics
+}
+
+// NewOrderOperator returns a new OrderOperator with the given repositories, event publisher and metrics.
+// Nothing is recorded if the metrics are nil
+func NewOrderOperator(or domain.OrderRepository, cr domain.CustomerRepository, pr domain.ProductRepository, ep EventPublisher, m Metrics) *OrderOperator {
+	if m == nil {
+		m = noMetrics{}
+	}
+	return &OrderOperator{
+		orderRepository:    or,
+		customerRepository: cr,
+		productRepository:  pr,
+		eventPublisher:     ep,
+		metrics:            m,
+	}
 }
 
-// AddProduct adds a product to the order
-// Returns error if Order's CustomerID does not match customerID
+// AddProduct adds a product to the order and stores the order, the product with its decreased stock count
+// and the customer with the decreased balance. They're stored one after the other, not in a transaction
+// Returns ErrWrongCustomer if Order's CustomerID does not match customerID
+// Returns ErrOrderPlaced if the Order is already placed
 // Returns error if the Customer does not have enough credit
 // Returns error if the productCount is above Product's StockCount
-func (oo *OrderOperator) AddProduct(orderID, customerID, productID string, productCount int) error {
-	order := oo.orderRepository.Fetch(orderID)
-	if order.Customer.ID != customerID {
-		return errors.New("The order does not belong to this customer, cannot add products")
+func (oo *OrderOperator) AddProduct(ctx context.Context, orderID, customerID, productID string, productCount int) error {
+	order, err := oo.openOrder(ctx, orderID, customerID)
+	if err != nil {
+		return err
+	}
+	product, err := oo.productRepository.Fetch(ctx, productID)
+	if err != nil {
+		return err
+	}
+	orderItem := domain.OrderItem{
+		Item:      product,
+		ItemCount: productCount,
+	}
+	err = order.AddProduct(&orderItem)
+	switch {
+	case errors.Is(err, domain.ErrOutOfStock):
+		oo.metrics.OutOfStock(orderItem)
+		return err
+	case errors.Is(err, domain.ErrInsufficientBalance):
+		oo.metrics.InsufficientBalance(orderItem)
+		return err
+	case err != nil:
+		return err
+	}
+	err = oo.store(ctx, order, orderItem.Item)
+	if err != nil {
+		return err
+	}
+	oo.metrics.ItemsAdded(orderItem)
+	return nil
+}
+
+// RemoveProduct removes a product from the order and stores the order, the product with its increased stock count
+// and the customer with the balance increased by the price on the order line. The product is only fetched for its stock count,
+// its current price is not refunded. They're stored one after the other, not in a transaction
+// Returns ErrWrongCustomer if Order's CustomerID does not match customerID
+// Returns ErrOrderPlaced if the Order is already placed
+// Returns error if the Order does not contain productCount of the Product
+func (oo *OrderOperator) RemoveProduct(ctx context.Context, orderID, customerID, productID string, productCount int) error {
+	order, err := oo.openOrder(ctx, orderID, customerID)
+	if err != nil {
+		return err
+	}
+	product, err := oo.productRepository.Fetch(ctx, productID)
+	if err != nil {
+		return err
 	}
-	product := oo.productRepository.Fetch(productID)
 	orderItem := domain.OrderItem{
 		Item:      product,
 		ItemCount: productCount,
 	}
-	err := order.AddProduct(orderItem)
+	err = order.RemoveProduct(&orderItem)
+	if err != nil {
+		return err
+	}
+	err = oo.store(ctx, order, orderItem.Item)
 	if err != nil {
 		return err
 	}
+	oo.metrics.ItemsRemoved(orderItem)
 	return nil
 }
+
+// PlaceOrder sets the placement date of the order, stores it and publishes the OrderPlacedEvent
+// Returns ErrWrongCustomer if Order's CustomerID does not match customerID
+// Returns ErrOrderPlaced if the Order is already placed
+// Returns ErrEmptyOrder if the Order does not contain any items
+func (oo *OrderOperator) PlaceOrder(ctx context.Context, orderID, customerID string) (domain.Order, error) {
+	order, err := oo.openOrder(ctx, orderID, customerID)
+	if err != nil {
+		return order, err
+	}
+	if len(order.Items) == 0 {
+		return order, ErrEmptyOrder
+	}
+	order.Date = time.Now()
+	err = oo.orderRepository.Store(ctx, order)
+	if err != nil {
+		return order, err
+	}
+	oo.metrics.OrderPlaced(order)
+	if oo.eventPublisher != nil {
+		oo.eventPublisher.Publish(OrderPlacedEvent, order)
+	}
+	return order, nil
+}
+
+// openOrder returns the order which is not placed yet together with the current state of its customer
+func (oo *OrderOperator) openOrder(ctx context.Context, orderID, customerID string) (domain.Order, error) {
+	order, err := oo.orderRepository.Fetch(ctx, orderID)
+	if err != nil {
+		return order, err
+	}
+	if order.Customer.ID != customerID {
+		return order, ErrWrongCustomer
+	}
+	if !order.Date.IsZero() {
+		return order, ErrOrderPlaced
+	}
+	order.Customer, err = oo.customerRepository.Fetch(ctx, customerID)
+	return order, err
+}
+
+// store stores the changed product, customer and order one after the other
+func (oo *OrderOperator) store(ctx context.Context, order domain.Order, product domain.Product) error {
+	err := oo.productRepository.Store(ctx, product)
+	if err != nil {
+		return err
+	}
+	err = oo.customerRepository.Store(ctx, order.Customer)
+	if err != nil {
+		return err
+	}
+	return oo.orderRepository.Store(ctx, order)
+}
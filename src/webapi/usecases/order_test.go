@@ -1,9 +1,210 @@
 package usecases_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"github.com/serdarkalayci/goboiler/webapi/domain"
+	"github.com/serdarkalayci/goboiler/webapi/usecases"
 )
 
+var errNotFound = errors.New("not found")
+
+type fakeOrderRepository struct {
+	orders map[string]domain.Order
+}
+
+func (r *fakeOrderRepository) Store(ctx context.Context, order domain.Order) error {
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *fakeOrderRepository) Fetch(ctx context.Context, orderID string) (domain.Order, error) {
+	order, ok := r.orders[orderID]
+	if !ok {
+		return order, errNotFound
+	}
+	return order, nil
+}
+
+type fakeCustomerRepository struct {
+	customers map[string]domain.Customer
+}
+
+func (r *fakeCustomerRepository) Store(ctx context.Context, customer domain.Customer) error {
+	r.customers[customer.ID] = customer
+	return nil
+}
+
+func (r *fakeCustomerRepository) Fetch(ctx context.Context, customerID string) (domain.Customer, error) {
+	customer, ok := r.customers[customerID]
+	if !ok {
+		return customer, errNotFound
+	}
+	return customer, nil
+}
+
+type fakeProductRepository struct {
+	products map[string]domain.Product
+}
+
+func (r *fakeProductRepository) Store(ctx context.Context, product domain.Product) error {
+	r.products[product.ID] = product
+	return nil
+}
+
+func (r *fakeProductRepository) Fetch(ctx context.Context, id string) (domain.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return product, errNotFound
+	}
+	return product, nil
+}
+
+type fakeMetrics struct {
+	placed, added, removed, outOfStock, insufficientBalance int
+	value                                                   float64
+}
+
+func (m *fakeMetrics) OrderPlaced(order domain.Order) {
+	m.placed++
+	m.value += order.Total
+}
+
+func (m *fakeMetrics) ItemsAdded(item domain.OrderItem) {
+	m.added += item.ItemCount
+}
+
+func (m *fakeMetrics) ItemsRemoved(item domain.OrderItem) {
+	m.removed += item.ItemCount
+}
+
+func (m *fakeMetrics) OutOfStock(item domain.OrderItem) {
+	m.outOfStock++
+}
+
+func (m *fakeMetrics) InsufficientBalance(item domain.OrderItem) {
+	m.insufficientBalance++
+}
+
+type fakePublisher struct {
+	events []string
+}
+
+func (p *fakePublisher) Publish(event string, payload interface{}) {
+	p.events = append(p.events, event)
+}
+
 func Test_AddProduct(t *testing.T) {
+	customer := domain.Customer{ID: "Customer1", Name: "Customer Name1", Balance: 30}
+	orders := &fakeOrderRepository{orders: map[string]domain.Order{"Order1": {ID: "Order1", Customer: customer}}}
+	products := &fakeProductRepository{products: map[string]domain.Product{
+		"Product1": {ID: "Product1", Price: 7.5, StockCount: 3},
+		"Product2": {ID: "Product2", Price: 50, StockCount: 10},
+	}}
+	customers := &fakeCustomerRepository{customers: map[string]domain.Customer{"Customer1": customer}}
+	metrics := &fakeMetrics{}
+	operator := usecases.NewOrderOperator(orders, customers, products, nil, metrics)
+	err := operator.AddProduct(context.Background(), "Order1", "Customer1", "Product1", 2)
+	if err != nil || metrics.added != 2 || orders.orders["Order1"].Total != 15 {
+		t.Errorf("Error adding the product. Expected 2 items added and the order stored, got %d items and total %f", metrics.added, orders.orders["Order1"].Total)
+	}
+	if products.products["Product1"].StockCount != 1 || customers.customers["Customer1"].Balance != 15 {
+		t.Errorf("Error adding the product. Expected the stock count 1 and the balance 15 stored, got %d and %f", products.products["Product1"].StockCount, customers.customers["Customer1"].Balance)
+	}
+	err = operator.AddProduct(context.Background(), "Order1", "Customer1", "Product1", 5)
+	if err != domain.ErrOutOfStock || metrics.outOfStock != 1 {
+		t.Errorf("Error adding a product out of stock. Expected %v and 1 rejection, got %v and %d rejections", domain.ErrOutOfStock, err, metrics.outOfStock)
+	}
+	err = operator.AddProduct(context.Background(), "Order1", "Customer1", "Product2", 1)
+	if err != domain.ErrInsufficientBalance || metrics.insufficientBalance != 1 || metrics.added != 2 {
+		t.Errorf("Error adding a product above the balance. Expected %v and 1 rejection, got %v and %d rejections", domain.ErrInsufficientBalance, err, metrics.insufficientBalance)
+	}
+	if products.products["Product2"].StockCount != 10 {
+		t.Errorf("Error adding a product above the balance. Expected the stock count 10 unchanged, got %d", products.products["Product2"].StockCount)
+	}
+	err = operator.AddProduct(context.Background(), "Order1", "Customer2", "Product1", 1)
+	if err != usecases.ErrWrongCustomer {
+		t.Errorf("Error adding a product to the order of another customer. Expected %v, got %v", usecases.ErrWrongCustomer, err)
+	}
+}
+
+func Test_RemoveProduct(t *testing.T) {
+	customer := domain.Customer{ID: "Customer1", Name: "Customer Name1", Balance: 30}
+	product := domain.Product{ID: "Product1", Price: 7.5, StockCount: 3}
+	orders := &fakeOrderRepository{orders: map[string]domain.Order{
+		"Order1": {ID: "Order1", Customer: customer, Items: []domain.OrderItem{{ItemCount: 2, Item: product}}, Total: 15},
+	}}
+	products := &fakeProductRepository{products: map[string]domain.Product{"Product1": product}}
+	customers := &fakeCustomerRepository{customers: map[string]domain.Customer{"Customer1": customer}}
+	metrics := &fakeMetrics{}
+	operator := usecases.NewOrderOperator(orders, customers, products, nil, metrics)
+	err := operator.RemoveProduct(context.Background(), "Order1", "Customer1", "Product1", 3)
+	if err == nil || metrics.removed != 0 {
+		t.Errorf("Error removing more items than the order contains. Expected an error and nothing removed, got %d removed", metrics.removed)
+	}
+	err = operator.RemoveProduct(context.Background(), "Order1", "Customer1", "Product1", 1)
+	if err != nil || metrics.removed != 1 || orders.orders["Order1"].Total != 7.5 {
+		t.Errorf("Error removing the product. Expected 1 item removed and the order stored, got %d items and total %f", metrics.removed, orders.orders["Order1"].Total)
+	}
+	if products.products["Product1"].StockCount != 4 || customers.customers["Customer1"].Balance != 37.5 {
+		t.Errorf("Error removing the product. Expected the stock count 4 and the balance 37.5 stored, got %d and %f", products.products["Product1"].StockCount, customers.customers["Customer1"].Balance)
+	}
+}
+
+func Test_RemoveProductAfterPriceChange(t *testing.T) {
+	customer := domain.Customer{ID: "Customer1", Name: "Customer Name1", Balance: 30}
+	orders := &fakeOrderRepository{orders: map[string]domain.Order{"Order1": {ID: "Order1", Customer: customer}}}
+	products := &fakeProductRepository{products: map[string]domain.Product{"Product1": {ID: "Product1", Price: 5, StockCount: 10}}}
+	customers := &fakeCustomerRepository{customers: map[string]domain.Customer{"Customer1": customer}}
+	operator := usecases.NewOrderOperator(orders, customers, products, nil, nil)
+	err := operator.AddProduct(context.Background(), "Order1", "Customer1", "Product1", 2)
+	if err != nil {
+		t.Fatalf("Error adding the product. Expected no error, got %v", err)
+	}
+	product := products.products["Product1"]
+	product.Price = 20
+	products.products["Product1"] = product
+	err = operator.RemoveProduct(context.Background(), "Order1", "Customer1", "Product1", 2)
+	if err != nil || orders.orders["Order1"].Total != 0 || customers.customers["Customer1"].Balance != 30 {
+		t.Errorf("Error removing the product after its price changed. Expected total 0 and the balance 30 refunded at the price paid, got %f and %f (%v)", orders.orders["Order1"].Total, customers.customers["Customer1"].Balance, err)
+	}
+	if products.products["Product1"].StockCount != 10 || products.products["Product1"].Price != 20 {
+		t.Errorf("Error removing the product after its price changed. Expected the stock count 10 and the price 20 stored, got %d and %f", products.products["Product1"].StockCount, products.products["Product1"].Price)
+	}
+}
 
+func Test_PlaceOrder(t *testing.T) {
+	customer := domain.Customer{ID: "Customer1", Name: "Customer Name1", Balance: 30}
+	orders := &fakeOrderRepository{orders: map[string]domain.Order{
+		"Empty":  {ID: "Empty", Customer: customer},
+		"Order1": {ID: "Order1", Customer: customer, Items: []domain.OrderItem{{ItemCount: 1, Item: domain.Product{ID: "Product1"}}}},
+	}}
+	publisher := &fakePublisher{}
+	metrics := &fakeMetrics{}
+	customers := &fakeCustomerRepository{customers: map[string]domain.Customer{"Customer1": customer}}
+	operator := usecases.NewOrderOperator(orders, customers, nil, publisher, metrics)
+	_, err := operator.PlaceOrder(context.Background(), "Order1", "Customer2")
+	if err == nil || len(publisher.events) != 0 {
+		t.Errorf("Error placing the order of another customer. Expected an error and no events, got %d events", len(publisher.events))
+	}
+	_, err = operator.PlaceOrder(context.Background(), "Empty", "Customer1")
+	if err != usecases.ErrEmptyOrder || len(publisher.events) != 0 {
+		t.Errorf("Error placing an empty order. Expected an error and no events, got %d events", len(publisher.events))
+	}
+	_, err = operator.PlaceOrder(context.Background(), "Order1", "Customer1")
+	if err != nil || len(publisher.events) != 1 || publisher.events[0] != usecases.OrderPlacedEvent {
+		t.Errorf("Error placing the order. Expected the %s event, got %v", usecases.OrderPlacedEvent, publisher.events)
+	}
+	if metrics.placed != 1 {
+		t.Errorf("Error placing the order. Expected 1 order placed in the metrics, got %d", metrics.placed)
+	}
+	if orders.orders["Order1"].Date.IsZero() {
+		t.Errorf("Error placing the order. Expected the placement date to be set")
+	}
+	_, err = operator.PlaceOrder(context.Background(), "Order1", "Customer1")
+	if err != usecases.ErrOrderPlaced || len(publisher.events) != 1 {
+		t.Errorf("Error placing the order again. Expected %v and no more events, got %v and %d events", usecases.ErrOrderPlaced, err, len(publisher.events))
+	}
 }